@@ -0,0 +1,254 @@
+package bosh
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// Client talks to the REST API of a BOSH Director
+// with the credentials the BOSH CLI would use.
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+type info struct {
+	UserAuthentication struct {
+		Type    string `json:"type"`
+		Options struct {
+			URL string `json:"url"`
+		} `json:"options"`
+	} `json:"user_authentication"`
+}
+
+// New creates a client from the BOSH_* environment variables,
+// as found in the env.yml of the BOSH state directory.
+func New(envs map[string]string) (*Client, error) {
+	directorURL, err := environmentURL(envs["BOSH_ENVIRONMENT"])
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := newTransport(envs["BOSH_CA_CERT"], envs["BOSH_ALL_PROXY"])
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		url:  directorURL,
+		http: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+	}
+
+	var directorInfo info
+	if err := client.get("/info", &directorInfo); err != nil {
+		return nil, err
+	}
+
+	switch directorInfo.UserAuthentication.Type {
+	case "uaa":
+		cfg := &clientcredentials.Config{
+			ClientID:     envs["BOSH_CLIENT"],
+			ClientSecret: envs["BOSH_CLIENT_SECRET"],
+			TokenURL:     strings.TrimSuffix(directorInfo.UserAuthentication.Options.URL, "/") + "/oauth/token",
+		}
+
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client.http)
+		client.http = cfg.Client(ctx)
+		client.http.Timeout = 10 * time.Second
+	default:
+		client.http = &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &basicAuthTransport{
+				username: envs["BOSH_CLIENT"],
+				password: envs["BOSH_CLIENT_SECRET"],
+				base:     transport,
+			},
+		}
+	}
+
+	return client, nil
+}
+
+func (c *Client) Deployments() ([]string, error) {
+	var deployments []struct {
+		Name string `json:"name"`
+	}
+
+	if err := c.get("/deployments", &deployments); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, d := range deployments {
+		names = append(names, d.Name)
+	}
+
+	return names, nil
+}
+
+// Tasks returns the most recent tasks of the deployment, newest first.
+func (c *Client) Tasks(deployment string) ([]Task, error) {
+	var tasks []Task
+	err := c.get("/tasks?verbose=1&limit=10&deployment="+url.QueryEscape(deployment), &tasks)
+	return tasks, err
+}
+
+func (c *Client) Task(id int) (Task, error) {
+	var task Task
+	err := c.get(fmt.Sprintf("/tasks/%d", id), &task)
+	return task, err
+}
+
+// TaskEvents returns the events the task has emitted so far.
+// Lines that cannot be parsed are skipped.
+func (c *Client) TaskEvents(id int) ([]Event, error) {
+	output, err := c.TaskOutput(id, "event")
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, line := range strings.Split(string(output), "\n") {
+		var event Event
+		if json.Unmarshal([]byte(line), &event) == nil {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// TaskOutput returns the raw output of the given type (event, debug, result) of the task.
+func (c *Client) TaskOutput(id int, outputType string) ([]byte, error) {
+	resp, err := c.do(fmt.Sprintf("/tasks/%d/output?type=%s", id, url.QueryEscape(outputType)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// CancelTask asks the Director to cancel a queued or processing task.
+func (c *Client) CancelTask(id int) error {
+	resp, err := c.request(http.MethodDelete, fmt.Sprintf("/tasks/%d", id))
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+func (c *Client) get(path string, result interface{}) error {
+	resp, err := c.do(path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (c *Client) do(path string) (*http.Response, error) {
+	return c.request(http.MethodGet, path)
+}
+
+func (c *Client) request(method string, path string) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("director responded to %s with %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return resp, nil
+}
+
+func environmentURL(environment string) (string, error) {
+	if environment == "" {
+		return "", errors.New("BOSH_ENVIRONMENT is not set")
+	}
+
+	if !strings.Contains(environment, "://") {
+		environment = "https://" + environment
+	}
+
+	u, err := url.Parse(environment)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Port() == "" {
+		u.Host += ":25555"
+	}
+
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+func newTransport(caCert string, allProxy string) (*http.Transport, error) {
+	transport := &http.Transport{TLSClientConfig: &tls.Config{}}
+
+	if caCert != "" {
+		if !strings.Contains(caCert, "-----BEGIN") {
+			data, err := ioutil.ReadFile(caCert)
+			if err != nil {
+				return nil, err
+			}
+			caCert = string(data)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, errors.New("BOSH_CA_CERT does not contain a valid certificate")
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if allProxy != "" {
+		proxyURL, err := url.Parse(allProxy)
+		if err != nil {
+			return nil, err
+		}
+
+		// Tunnels over SSH are left to the BOSH CLI
+		if proxyURL.Scheme != "socks5" && proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+			return nil, fmt.Errorf("unsupported BOSH_ALL_PROXY scheme: %s", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, nil
+}
+
+type basicAuthTransport struct {
+	username string
+	password string
+	base     http.RoundTripper
+}
+
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+	return t.base.RoundTrip(req)
+}
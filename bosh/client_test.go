@@ -0,0 +1,158 @@
+package bosh_test
+
+import (
+	"code.cloudfoundry.org/cfdev/bosh"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		server   *httptest.Server
+		envs     map[string]string
+		authType string
+		requests []string
+	)
+
+	BeforeEach(func() {
+		authType = "uaa"
+		requests = nil
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.Path)
+
+			switch r.URL.Path {
+			case "/info":
+				fmt.Fprintf(w, `{"user_authentication": {"type": %q, "options": {"url": "%s"}}}`, authType, server.URL)
+				return
+			case "/oauth/token":
+				username, password, _ := r.BasicAuth()
+				if username != "admin" || password != "some-secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"access_token": "some-token", "token_type": "bearer", "expires_in": 3600}`)
+				return
+			}
+
+			username, password, basic := r.BasicAuth()
+			authorized := r.Header.Get("Authorization") == "Bearer some-token" ||
+				(basic && username == "admin" && password == "some-secret")
+			if !authorized {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			switch r.URL.Path {
+			case "/tasks":
+				Expect(r.URL.Query().Get("deployment")).To(Equal("cf"))
+				fmt.Fprint(w, `[{"id": 7, "state": "processing", "description": "create deployment", "deployment": "cf"}]`)
+			case "/tasks/7":
+				if r.Method == http.MethodDelete {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				fmt.Fprint(w, `{"id": 7, "state": "error", "result": "some-result"}`)
+			case "/tasks/7/output":
+				Expect(r.URL.Query().Get("type")).To(Equal("event"))
+				fmt.Fprintln(w, `{"time": 1, "stage": "Compiling packages", "total": 2, "task": "golang/123", "index": 1, "state": "started"}`)
+				fmt.Fprintln(w, `not-json`)
+				fmt.Fprintln(w, `{"time": 2, "stage": "Compiling packages", "total": 2, "task": "golang/123", "index": 1, "state": "finished"}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, "not found")
+			}
+		}))
+
+		envs = map[string]string{
+			"BOSH_ENVIRONMENT":   server.URL,
+			"BOSH_CLIENT":        "admin",
+			"BOSH_CLIENT_SECRET": "some-secret",
+			"BOSH_CA_CERT":       string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("authenticates with UAA and lists the tasks of a deployment", func() {
+		client, err := bosh.New(envs)
+		Expect(err).NotTo(HaveOccurred())
+
+		tasks, err := client.Tasks("cf")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tasks).To(Equal([]bosh.Task{{ID: 7, State: "processing", Description: "create deployment", Deployment: "cf"}}))
+		Expect(tasks[0].Running()).To(BeTrue())
+		Expect(requests).To(ContainElement("/oauth/token"))
+	})
+
+	It("authenticates with basic auth when the Director does not use UAA", func() {
+		authType = "basic"
+
+		client, err := bosh.New(envs)
+		Expect(err).NotTo(HaveOccurred())
+
+		task, err := client.Task(7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Failed()).To(BeTrue())
+		Expect(requests).NotTo(ContainElement("/oauth/token"))
+	})
+
+	It("parses the events of a task", func() {
+		client, err := bosh.New(envs)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := client.TaskEvents(7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[1].State).To(Equal("finished"))
+	})
+
+	It("cancels tasks", func() {
+		client, err := bosh.New(envs)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(client.CancelTask(7)).To(Succeed())
+		Expect(client.CancelTask(8)).To(MatchError(ContainSubstring("404: not found")))
+	})
+
+	It("returns the response of the Director on errors", func() {
+		client, err := bosh.New(envs)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.Task(8)
+		Expect(err).To(MatchError(ContainSubstring("404: not found")))
+	})
+
+	It("fails when the Director certificate is not trusted", func() {
+		delete(envs, "BOSH_CA_CERT")
+
+		_, err := bosh.New(envs)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("defaults to https and the Director port", func() {
+		envs["BOSH_ENVIRONMENT"] = strings.TrimPrefix(server.URL, "https://")
+		_, err := bosh.New(envs)
+		Expect(err).NotTo(HaveOccurred())
+
+		envs["BOSH_ENVIRONMENT"] = "127.0.0.1"
+		_, err = bosh.New(envs)
+		Expect(err).To(MatchError(ContainSubstring("127.0.0.1:25555")))
+	})
+
+	It("leaves SSH tunnels to the BOSH CLI", func() {
+		envs["BOSH_ALL_PROXY"] = "ssh+socks5://jumpbox@10.0.0.1:22?private-key=key"
+
+		_, err := bosh.New(envs)
+		Expect(err).To(MatchError(ContainSubstring("unsupported BOSH_ALL_PROXY scheme")))
+	})
+})
@@ -0,0 +1,95 @@
+package bosh
+
+import "fmt"
+
+const (
+	TaskQueued     = "queued"
+	TaskProcessing = "processing"
+	TaskCancelling = "cancelling"
+	TaskDone       = "done"
+	TaskError      = "error"
+	TaskTimeout    = "timeout"
+	TaskCancelled  = "cancelled"
+)
+
+type Task struct {
+	ID          int    `json:"id"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	Result      string `json:"result"`
+	Deployment  string `json:"deployment"`
+	StartedAt   int64  `json:"started_at"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+func (t Task) Running() bool {
+	return t.State == TaskQueued || t.State == TaskProcessing || t.State == TaskCancelling
+}
+
+func (t Task) Failed() bool {
+	return t.State == TaskError || t.State == TaskTimeout || t.State == TaskCancelled
+}
+
+type Event struct {
+	Time     int64       `json:"time"`
+	Stage    string      `json:"stage"`
+	Tags     []string    `json:"tags"`
+	Total    int         `json:"total"`
+	Task     string      `json:"task"`
+	Index    int         `json:"index"`
+	State    string      `json:"state"`
+	Progress int         `json:"progress"`
+	Error    *EventError `json:"error"`
+	Data     struct {
+		Error string `json:"error"`
+	} `json:"data"`
+}
+
+type EventError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Progress summarizes the events of a task.
+type Progress struct {
+	Stage  string
+	Done   int
+	Total  int
+	Errors []string
+}
+
+// ProgressOf reports the stage of the latest event,
+// and how many of its steps have finished.
+func ProgressOf(events []Event) Progress {
+	var (
+		progress Progress
+		finished = map[string]bool{}
+	)
+
+	for _, event := range events {
+		switch {
+		case event.Error != nil:
+			progress.Errors = append(progress.Errors, event.Error.Message)
+			continue
+		case event.State == "failed" && event.Data.Error != "":
+			progress.Errors = append(progress.Errors, event.Stage+": "+event.Task+": "+event.Data.Error)
+		}
+
+		if event.Stage == "" {
+			continue
+		}
+
+		if event.Stage != progress.Stage {
+			progress = Progress{Stage: event.Stage, Errors: progress.Errors}
+			finished = map[string]bool{}
+		}
+
+		progress.Total = event.Total
+		if event.State == "finished" || event.State == "failed" {
+			finished[fmt.Sprintf("%d:%s", event.Index, event.Task)] = true
+		}
+		progress.Done = len(finished)
+	}
+
+	return progress
+}
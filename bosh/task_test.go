@@ -0,0 +1,35 @@
+package bosh_test
+
+import (
+	"code.cloudfoundry.org/cfdev/bosh"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProgressOf", func() {
+	It("reports the steps of the latest stage", func() {
+		progress := bosh.ProgressOf([]bosh.Event{
+			{Stage: "Compiling packages", Total: 1, Task: "golang/1", Index: 1, State: "started"},
+			{Stage: "Compiling packages", Total: 1, Task: "golang/1", Index: 1, State: "finished"},
+			{Stage: "Updating instance", Total: 3, Task: "router/1 (0)", Index: 1, State: "started"},
+			{Stage: "Updating instance", Total: 3, Task: "router/1 (0)", Index: 1, State: "finished"},
+			{Stage: "Updating instance", Total: 3, Task: "api/2 (0)", Index: 2, State: "started"},
+		})
+
+		Expect(progress).To(Equal(bosh.Progress{Stage: "Updating instance", Done: 1, Total: 3}))
+	})
+
+	It("collects the errors of the task", func() {
+		failed := bosh.Event{Stage: "Updating instance", Total: 1, Task: "api/2 (0)", Index: 1, State: "failed"}
+		failed.Data.Error = "some-failure"
+
+		progress := bosh.ProgressOf([]bosh.Event{
+			failed,
+			{Error: &bosh.EventError{Code: 450001, Message: "some-error"}},
+		})
+
+		Expect(progress.Errors).To(Equal([]string{"Updating instance: api/2 (0): some-failure", "some-error"}))
+		Expect(progress.Done).To(Equal(1))
+	})
+})
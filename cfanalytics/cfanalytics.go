@@ -20,6 +20,18 @@ const (
 	ERROR            = "error"
 	UNINSTALL        = "uninstall"
 	DEPLOY_SERVICE   = "deployed service"
+	CREDHUB_ENV      = "credhub"
+	TARGET           = "target"
+	UPGRADE          = "upgrade"
+	EXPORT           = "export"
+	IMPORT           = "import"
+	SMOKE_TEST       = "smoke test"
+	SERVICES         = "services"
+	UNDEPLOY_SERVICE = "undeployed service"
+	CONSOLE          = "console"
+	DISK_USAGE       = "disk usage"
+	DISK_COMPACT     = "disk compact"
+	DISK_GROW        = "disk grow"
 )
 
 //go:generate mockgen -package mocks -destination mocks/analytics_client.go gopkg.in/segmentio/analytics-go.v3 Client
@@ -3,14 +3,13 @@ package bosh
 import (
 	"code.cloudfoundry.org/cfdev/cfanalytics"
 	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/shell"
 	"code.cloudfoundry.org/cfdev/workspace"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"runtime"
-
 	"github.com/spf13/cobra"
 )
 
@@ -37,24 +36,24 @@ func (b *Bosh) Cmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "bosh",
 		Run: func(cmd *cobra.Command, args []string) {
-			if runtime.GOOS != "windows" {
-				b.UI.Say(`Usage: eval $(cf dev bosh env)`)
-			} else {
-				b.UI.Say(`Usage: cf dev bosh env | Invoke-Expression`)
-			}
+			b.UI.Say(shell.Usage(shell.Detect(), "cf dev bosh env"))
 		},
 	}
+
+	args := shell.EnvArgs{}
 	envCmd := &cobra.Command{
 		Use: "env",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return b.Env()
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return b.Env(args)
 		},
 	}
+
+	args.AddFlags(envCmd)
 	cmd.AddCommand(envCmd)
 	return cmd
 }
 
-func (b *Bosh) Env() error {
+func (b *Bosh) Env(args shell.EnvArgs) error {
 	go func() {
 		<-b.Exit
 		os.Exit(128)
@@ -62,40 +61,42 @@ func (b *Bosh) Env() error {
 
 	b.Analytics.Event(cfanalytics.BOSH_ENV)
 
-	var output []string
-	envsMapping := b.Workspace.EnvsMapping()
+	if args.Shell == "" {
+		args.Shell = shell.Detect()
+	}
 
-	for _, envvar := range os.Environ() {
-		if strings.HasPrefix(envvar, "BOSH_") {
-			envvar = strings.Split(envvar, "=")[0]
-			if runtime.GOOS != "windows" {
-				output = append(output, fmt.Sprintf("unset %s;", envvar))
-			} else {
-				output = append(output, fmt.Sprintf("Remove-Item Env:%s;", envvar))
-			}
-		}
+	envs := map[string]string{}
+	for key, value := range b.Workspace.EnvsMapping() {
+		envs[key] = value
 	}
 
-	for key, value := range envsMapping {
-		if key == "BOSH_CA_CERT" || key == "BOSH_GW_PRIVATE_KEY" {
-			continue
+	envs["BOSH_CA_CERT"] = filepath.Join(b.Config.StateBosh, "ca.crt")
+	envs["BOSH_GW_PRIVATE_KEY"] = filepath.Join(b.Config.StateBosh, "jumpbox.key")
+
+	if args.Envrc != "" {
+		if err := shell.WriteEnvrc(args.Envrc, envs); err != nil {
+			return fmt.Errorf("failed to write %s: %s", args.Envrc, err)
 		}
 
-		if runtime.GOOS != "windows" {
-			output = append(output, fmt.Sprintf(`export %s=%q;`, key, value))
-		} else {
-			output = append(output, fmt.Sprintf(`$env:%s=%q;`, key, value))
+		b.UI.Say("Wrote the BOSH environment to %s", args.Envrc)
+		return nil
+	}
+
+	// Only stale variables from a previous environment are unset,
+	// the ones provided by CF Dev get overwritten anyway
+	var unset []string
+	for _, envvar := range os.Environ() {
+		key := strings.Split(envvar, "=")[0]
+		if _, ok := envs[key]; strings.HasPrefix(key, "BOSH_") && !ok {
+			unset = append(unset, key)
 		}
 	}
 
-	if runtime.GOOS == "windows" {
-		output = append(output, fmt.Sprintf(`$env:BOSH_CA_CERT=%q;`, filepath.Join(b.Config.StateBosh, "ca.crt")))
-		output = append(output, fmt.Sprintf(`$env:BOSH_GW_PRIVATE_KEY=%q;`, filepath.Join(b.Config.StateBosh, "jumpbox.key")))
-	} else {
-		output = append(output, fmt.Sprintf(`export BOSH_CA_CERT=%q;`, filepath.Join(b.Config.StateBosh, "ca.crt")))
-		output = append(output, fmt.Sprintf(`export BOSH_GW_PRIVATE_KEY=%q;`, filepath.Join(b.Config.StateBosh, "jumpbox.key")))
+	output, err := shell.Format(args.Shell, envs, unset)
+	if err != nil {
+		return err
 	}
 
-	b.UI.Say(strings.Join(output, "\n"))
+	b.UI.Say(output)
 	return nil
 }
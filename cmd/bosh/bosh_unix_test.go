@@ -5,6 +5,7 @@ package bosh_test
 import (
 	"code.cloudfoundry.org/cfdev/cfanalytics"
 	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/shell"
 	"code.cloudfoundry.org/cfdev/workspace"
 	"fmt"
 	"io/ioutil"
@@ -44,6 +45,7 @@ var _ = Describe("Bosh", func() {
 		content := `---
 BOSH_ENVIRONMENT: 10.0.0.1
 BOSH_CLIENT: admin
+CREDHUB_SERVER: https://10.0.0.1:8844
 BOSH_CA_CERT: |
   some ca cert
   end pem block
@@ -84,11 +86,11 @@ BOSH_GW_PRIVATE_KEY: |
 				mockUI.EXPECT().Say(gomock.Any()).Do(func(arg string) {
 					Expect(arg).To(ContainSubstring(`unset BOSH_SOME_VAR;`))
 					Expect(arg).To(ContainSubstring(`unset BOSH_SOME_OTHER_VAR;`))
-					Expect(arg).To(ContainSubstring(`export BOSH_ENVIRONMENT="10.0.0.1";`))
-					Expect(arg).To(ContainSubstring(`export BOSH_CLIENT="admin";`))
+					Expect(arg).To(ContainSubstring(`export BOSH_ENVIRONMENT='10.0.0.1';`))
+					Expect(arg).To(ContainSubstring(`export BOSH_CLIENT='admin';`))
 				})
 
-				Expect(boshCmd.Env()).To(Succeed())
+				Expect(boshCmd.Env(shell.EnvArgs{Shell: "bash"})).To(Succeed())
 			})
 		})
 
@@ -98,11 +100,53 @@ BOSH_GW_PRIVATE_KEY: |
 				Expect(strings.Count(arg, "BOSH_CA_CERT")).To(Equal(1))
 				Expect(strings.Count(arg, "BOSH_GW_PRIVATE_KEY")).To(Equal(1))
 
-				Expect(arg).To(ContainSubstring(fmt.Sprintf(`export BOSH_CA_CERT="%s";`, filepath.Join(tmpDir, "ca.crt"))))
-				Expect(arg).To(ContainSubstring(fmt.Sprintf(`export BOSH_GW_PRIVATE_KEY="%s";`, filepath.Join(tmpDir, "jumpbox.key"))))
+				Expect(arg).To(ContainSubstring(fmt.Sprintf(`export BOSH_CA_CERT='%s';`, filepath.Join(tmpDir, "ca.crt"))))
+				Expect(arg).To(ContainSubstring(fmt.Sprintf(`export BOSH_GW_PRIVATE_KEY='%s';`, filepath.Join(tmpDir, "jumpbox.key"))))
 			})
 
-			Expect(boshCmd.Env()).To(Succeed())
+			Expect(boshCmd.Env(shell.EnvArgs{Shell: "bash"})).To(Succeed())
+		})
+
+		It("prints the environment in the requested shell syntax", func() {
+			mockAnalyticsClient.EXPECT().Event(cfanalytics.BOSH_ENV)
+			mockUI.EXPECT().Say(gomock.Any()).Do(func(arg string) {
+				Expect(arg).To(ContainSubstring(`set -gx BOSH_ENVIRONMENT '10.0.0.1';`))
+				Expect(arg).To(ContainSubstring(`set -gx BOSH_CLIENT 'admin';`))
+			})
+
+			Expect(boshCmd.Env(shell.EnvArgs{Shell: "fish"})).To(Succeed())
+		})
+
+		It("exports the other variables of the environment as well", func() {
+			mockAnalyticsClient.EXPECT().Event(cfanalytics.BOSH_ENV)
+			mockUI.EXPECT().Say(gomock.Any()).Do(func(arg string) {
+				Expect(arg).To(ContainSubstring(`export CREDHUB_SERVER='https://10.0.0.1:8844';`))
+			})
+
+			Expect(boshCmd.Env(shell.EnvArgs{Shell: "bash"})).To(Succeed())
+		})
+
+		It("returns an error for an unsupported shell", func() {
+			mockAnalyticsClient.EXPECT().Event(cfanalytics.BOSH_ENV)
+
+			Expect(boshCmd.Env(shell.EnvArgs{Shell: "tcsh"})).To(MatchError(ContainSubstring("unsupported shell 'tcsh'")))
+		})
+
+		Context("when an envrc path is provided", func() {
+			It("writes the environment to the file", func() {
+				envrcPath := filepath.Join(tmpDir, ".envrc")
+
+				mockAnalyticsClient.EXPECT().Event(cfanalytics.BOSH_ENV)
+				mockUI.EXPECT().Say("Wrote the BOSH environment to %s", envrcPath)
+
+				Expect(boshCmd.Env(shell.EnvArgs{Envrc: envrcPath})).To(Succeed())
+
+				contents, err := ioutil.ReadFile(envrcPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(ContainSubstring(`export BOSH_ENVIRONMENT='10.0.0.1'`))
+				Expect(string(contents)).To(ContainSubstring(fmt.Sprintf(`export BOSH_CA_CERT='%s'`, filepath.Join(tmpDir, "ca.crt"))))
+				Expect(string(contents)).NotTo(ContainSubstring("unset"))
+			})
 		})
 	})
 })
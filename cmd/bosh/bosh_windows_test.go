@@ -3,6 +3,7 @@ package bosh_test
 import (
 	"code.cloudfoundry.org/cfdev/cfanalytics"
 	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/shell"
 	"code.cloudfoundry.org/cfdev/workspace"
 	"fmt"
 	"io/ioutil"
@@ -82,11 +83,11 @@ BOSH_GW_PRIVATE_KEY: |
 				mockUI.EXPECT().Say(gomock.Any()).Do(func(arg string) {
 					Expect(arg).To(ContainSubstring(`Remove-Item Env:BOSH_SOME_OTHER_VAR;`))
 					Expect(arg).To(ContainSubstring(`Remove-Item Env:BOSH_SOME_VAR;`))
-					Expect(arg).To(ContainSubstring(`$env:BOSH_ENVIRONMENT="10.0.0.1";`))
-					Expect(arg).To(ContainSubstring(`$env:BOSH_CLIENT="admin";`))
+					Expect(arg).To(ContainSubstring(`$env:BOSH_ENVIRONMENT='10.0.0.1';`))
+					Expect(arg).To(ContainSubstring(`$env:BOSH_CLIENT='admin';`))
 				})
 
-				Expect(boshCmd.Env()).To(Succeed())
+				Expect(boshCmd.Env(shell.EnvArgs{Shell: "powershell"})).To(Succeed())
 			})
 
 			It("replaces the certificates with their file paths", func() {
@@ -95,11 +96,11 @@ BOSH_GW_PRIVATE_KEY: |
 					Expect(strings.Count(arg, "BOSH_CA_CERT")).To(Equal(1))
 					Expect(strings.Count(arg, "BOSH_GW_PRIVATE_KEY")).To(Equal(1))
 
-					Expect(arg).To(ContainSubstring(fmt.Sprintf(`$env:BOSH_CA_CERT=%q;`, filepath.Join(tmpDir, "ca.crt"))))
+					Expect(arg).To(ContainSubstring(fmt.Sprintf(`$env:BOSH_CA_CERT='%s';`, filepath.Join(tmpDir, "ca.crt"))))
 					Expect(arg).To(ContainSubstring(fmt.Sprintf(`$env:BOSH_GW_PRIVATE_KEY=%q;`, filepath.Join(tmpDir, "jumpbox.key"))))
 				})
 
-				Expect(boshCmd.Env()).To(Succeed())
+				Expect(boshCmd.Env(shell.EnvArgs{Shell: "powershell"})).To(Succeed())
 			})
 		})
 	})
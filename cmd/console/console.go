@@ -0,0 +1,173 @@
+package console
+
+import (
+	"bytes"
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/driver"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/console UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/console Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+type Console struct {
+	Exit      chan struct{}
+	UI        UI
+	Config    config.Config
+	Analytics Analytics
+	Stdin     io.Reader
+	Stdout    io.Writer
+}
+
+type Args struct {
+	DetachKey string
+}
+
+func (c *Console) Cmd() *cobra.Command {
+	args := Args{}
+	cmd := &cobra.Command{
+		Use:   "console",
+		Short: "Attach to the serial console of the VM",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.Execute(args)
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.StringVar(&args.DetachKey, "detach-key", "ctrl-]", "key that detaches from the console")
+	return cmd
+}
+
+func (c *Console) Execute(args Args) error {
+	c.Analytics.Event(cfanalytics.CONSOLE)
+
+	detachKey, err := parseDetachKey(args.DetachKey)
+	if err != nil {
+		return e.SafeWrap(err, "cf dev console")
+	}
+
+	conn, err := net.Dial("unix", driver.ConsoleSocket(c.Config))
+	if err != nil {
+		return e.SafeWrap(err, "Failed to connect to the VM console. It is only available while CF Dev runs with QEMU on Linux")
+	}
+	defer conn.Close()
+
+	c.UI.Say("Connected to the VM console. Press Enter for a prompt, and %s to detach.", args.DetachKey)
+
+	restore := c.makeRaw()
+	go func() {
+		<-c.Exit
+		restore()
+		os.Exit(128)
+	}()
+
+	err = attach(conn, c.Stdin, c.Stdout, detachKey)
+	restore()
+	if err != nil {
+		return e.SafeWrap(err, "cf dev console")
+	}
+
+	c.UI.Say("\nDetached from the VM console.")
+	return nil
+}
+
+// makeRaw passes every key to the console, including the ones the
+// terminal would turn into signals. It returns how to undo it.
+func (c *Console) makeRaw() func() {
+	stdin, ok := c.Stdin.(*os.File)
+	if !ok || !terminal.IsTerminal(int(stdin.Fd())) {
+		return func() {}
+	}
+
+	state, err := terminal.MakeRaw(int(stdin.Fd()))
+	if err != nil {
+		return func() {}
+	}
+
+	return func() {
+		terminal.Restore(int(stdin.Fd()), state)
+	}
+}
+
+// attach copies the input to the console and the console to the output,
+// until the detach key is pressed, the input ends or the VM stops.
+func attach(conn net.Conn, stdin io.Reader, stdout io.Writer, detachKey byte) error {
+	var (
+		closed   = make(chan error, 1)
+		detached = make(chan error, 1)
+	)
+
+	go func() {
+		_, err := io.Copy(stdout, conn)
+		closed <- err
+	}()
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := stdin.Read(buf)
+			if i := bytes.IndexByte(buf[:n], detachKey); i >= 0 {
+				_, err = conn.Write(buf[:i])
+				detached <- err
+				return
+			}
+
+			if n > 0 {
+				if _, err := conn.Write(buf[:n]); err != nil {
+					detached <- err
+					return
+				}
+			}
+
+			if err == io.EOF {
+				detached <- nil
+				return
+			} else if err != nil {
+				detached <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-detached:
+		return err
+	case <-closed:
+		return fmt.Errorf("the console was closed, the VM stopped")
+	}
+}
+
+// parseDetachKey turns keys such as ctrl-] or ctrl-a
+// into the control character the terminal sends for them.
+func parseDetachKey(key string) (byte, error) {
+	lower := strings.ToLower(key)
+	if !strings.HasPrefix(lower, "ctrl-") || len(lower) != len("ctrl-")+1 {
+		return 0, fmt.Errorf("invalid detach key '%s', expected a key such as ctrl-] or ctrl-a", key)
+	}
+
+	char := lower[len(lower)-1]
+	switch {
+	case char >= 'a' && char <= 'z':
+		return char - 'a' + 1, nil
+	case char >= '[' && char <= '_', char == '@':
+		return char & 0x1f, nil
+	default:
+		return 0, fmt.Errorf("invalid detach key '%s', expected a key such as ctrl-] or ctrl-a", key)
+	}
+}
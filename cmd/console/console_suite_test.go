@@ -0,0 +1,13 @@
+package console_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestConsole(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Console Suite")
+}
@@ -0,0 +1,138 @@
+package console_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/console"
+	"code.cloudfoundry.org/cfdev/cmd/console/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"github.com/golang/mock/gomock"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Console", func() {
+	var (
+		mockController *gomock.Controller
+		mockUI         *mocks.MockUI
+		mockAnalytics  *mocks.MockAnalytics
+		tmpDir         string
+		listener       net.Listener
+		received       chan string
+		stdin          *io.PipeWriter
+		stdout         *gbytes.Buffer
+		cmd            *console.Console
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-console-")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(tmpDir, "linuxkit"), 0755)).To(Succeed())
+
+		listener, err = net.Listen("unix", filepath.Join(tmpDir, "linuxkit", "console.sock"))
+		Expect(err).NotTo(HaveOccurred())
+		received = make(chan string, 1)
+
+		var stdinReader *io.PipeReader
+		stdinReader, stdin = io.Pipe()
+		stdout = gbytes.NewBuffer()
+
+		cmd = &console.Console{
+			UI:        mockUI,
+			Analytics: mockAnalytics,
+			Config: config.Config{
+				StateLinuxkit: filepath.Join(tmpDir, "linuxkit"),
+			},
+			Stdin:  stdinReader,
+			Stdout: stdout,
+		}
+
+		mockAnalytics.EXPECT().Event(cfanalytics.CONSOLE)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		listener.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	serve := func(closeAfterPrompt bool) {
+		go func() {
+			defer GinkgoRecover()
+
+			conn, err := listener.Accept()
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			conn.Write([]byte("cfdev login: "))
+			if closeAfterPrompt {
+				return
+			}
+
+			data, _ := ioutil.ReadAll(conn)
+			received <- string(data)
+		}()
+	}
+
+	It("attaches to the console until the detach key is pressed", func() {
+		serve(false)
+		mockUI.EXPECT().Say("Connected to the VM console. Press Enter for a prompt, and %s to detach.", "ctrl-]")
+		mockUI.EXPECT().Say("\nDetached from the VM console.")
+
+		done := make(chan error, 1)
+		go func() { done <- cmd.Execute(console.Args{DetachKey: "ctrl-]"}) }()
+
+		Eventually(stdout).Should(gbytes.Say("cfdev login: "))
+		stdin.Write([]byte("root\r\x1dnot sent"))
+
+		Eventually(done).Should(Receive(BeNil()))
+		Eventually(received).Should(Receive(Equal("root\r")))
+	})
+
+	It("supports other detach keys", func() {
+		serve(false)
+		mockUI.EXPECT().Say(gomock.Any(), "Ctrl-A")
+		mockUI.EXPECT().Say("\nDetached from the VM console.")
+
+		done := make(chan error, 1)
+		go func() { done <- cmd.Execute(console.Args{DetachKey: "Ctrl-A"}) }()
+
+		Eventually(stdout).Should(gbytes.Say("cfdev login: "))
+		stdin.Write([]byte("ls\x01"))
+
+		Eventually(done).Should(Receive(BeNil()))
+		Eventually(received).Should(Receive(Equal("ls")))
+	})
+
+	It("returns an error when the VM stops", func() {
+		serve(true)
+		mockUI.EXPECT().Say(gomock.Any(), "ctrl-]")
+
+		err := cmd.Execute(console.Args{DetachKey: "ctrl-]"})
+		Expect(err).To(MatchError(ContainSubstring("the console was closed, the VM stopped")))
+		Expect(stdout).To(gbytes.Say("cfdev login: "))
+	})
+
+	It("returns an error when the VM console is not available", func() {
+		listener.Close()
+
+		err := cmd.Execute(console.Args{DetachKey: "ctrl-]"})
+		Expect(err).To(MatchError(ContainSubstring("Failed to connect to the VM console")))
+	})
+
+	It("rejects invalid detach keys", func() {
+		err := cmd.Execute(console.Args{DetachKey: "alt-x"})
+		Expect(err).To(MatchError(ContainSubstring("invalid detach key 'alt-x'")))
+	})
+})
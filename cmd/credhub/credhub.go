@@ -0,0 +1,107 @@
+package credhub
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/shell"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/credhub UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics_client.go code.cloudfoundry.org/cfdev/cmd/credhub AnalyticsClient
+type AnalyticsClient interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+type Credhub struct {
+	Exit      chan struct{}
+	UI        UI
+	Config    config.Config
+	Analytics AnalyticsClient
+	Workspace *workspace.Workspace
+}
+
+func (c *Credhub) Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "credhub",
+		Run: func(cmd *cobra.Command, args []string) {
+			c.UI.Say(shell.Usage(shell.Detect(), "cf dev credhub env"))
+		},
+	}
+
+	args := shell.EnvArgs{}
+	envCmd := &cobra.Command{
+		Use: "env",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.Env(args)
+		},
+	}
+
+	args.AddFlags(envCmd)
+	cmd.AddCommand(envCmd)
+	return cmd
+}
+
+func (c *Credhub) Env(args shell.EnvArgs) error {
+	go func() {
+		<-c.Exit
+		os.Exit(128)
+	}()
+
+	c.Analytics.Event(cfanalytics.CREDHUB_ENV)
+
+	if args.Shell == "" {
+		args.Shell = shell.Detect()
+	}
+
+	envs := map[string]string{}
+	for key, value := range c.Workspace.EnvsMapping() {
+		if strings.HasPrefix(key, "CREDHUB_") {
+			envs[key] = value
+		}
+	}
+
+	if envs["CREDHUB_SERVER"] == "" {
+		return errors.New("the CredHub credentials could not be found. Please make sure CF Dev is running")
+	}
+
+	// The CredHub CLI accepts a file path for the CA certificate,
+	// which spares shells that cannot handle multi-line values
+	if caCert, ok := envs["CREDHUB_CA_CERT"]; ok && strings.Contains(caCert, "\n") {
+		caCertPath := filepath.Join(c.Config.StateBosh, "credhub-ca.crt")
+		if err := ioutil.WriteFile(caCertPath, []byte(caCert), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %s", caCertPath, err)
+		}
+
+		envs["CREDHUB_CA_CERT"] = caCertPath
+	}
+
+	if args.Envrc != "" {
+		if err := shell.WriteEnvrc(args.Envrc, envs); err != nil {
+			return fmt.Errorf("failed to write %s: %s", args.Envrc, err)
+		}
+
+		c.UI.Say("Wrote the CredHub environment to %s", args.Envrc)
+		return nil
+	}
+
+	output, err := shell.Format(args.Shell, envs, nil)
+	if err != nil {
+		return err
+	}
+
+	c.UI.Say(output)
+	return nil
+}
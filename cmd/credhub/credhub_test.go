@@ -0,0 +1,106 @@
+package credhub_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/shell"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	cmd "code.cloudfoundry.org/cfdev/cmd/credhub"
+	"code.cloudfoundry.org/cfdev/cmd/credhub/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Credhub", func() {
+	var (
+		mockController      *gomock.Controller
+		mockUI              *mocks.MockUI
+		mockAnalyticsClient *mocks.MockAnalyticsClient
+		tmpDir              string
+		credhubCmd          *cmd.Credhub
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockAnalyticsClient = mocks.NewMockAnalyticsClient(mockController)
+		mockUI = mocks.NewMockUI(mockController)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-credhub-env-")
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg := config.Config{
+			StateBosh: tmpDir,
+		}
+
+		credhubCmd = &cmd.Credhub{
+			UI:        mockUI,
+			Analytics: mockAnalyticsClient,
+			Config:    cfg,
+			Workspace: workspace.New(cfg),
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(tmpDir)
+	})
+
+	Describe("Env", func() {
+		Context("when the env.yml contains the credhub credentials", func() {
+			BeforeEach(func() {
+				content := `---
+BOSH_ENVIRONMENT: 10.0.0.1
+CREDHUB_SERVER: https://10.0.0.1:8844
+CREDHUB_CLIENT: credhub-admin
+CREDHUB_SECRET: some-secret
+CREDHUB_CA_CERT: |
+  some ca cert
+  end pem block`
+
+				ioutil.WriteFile(filepath.Join(tmpDir, "env.yml"), []byte(content), 0600)
+			})
+
+			It("prints the credhub variables only", func() {
+				mockAnalyticsClient.EXPECT().Event(cfanalytics.CREDHUB_ENV)
+				mockUI.EXPECT().Say(gomock.Any()).Do(func(arg string) {
+					Expect(arg).To(ContainSubstring(`export CREDHUB_SERVER='https://10.0.0.1:8844';`))
+					Expect(arg).To(ContainSubstring(`export CREDHUB_CLIENT='credhub-admin';`))
+					Expect(arg).To(ContainSubstring(`export CREDHUB_SECRET='some-secret';`))
+					Expect(arg).NotTo(ContainSubstring("BOSH_ENVIRONMENT"))
+				})
+
+				Expect(credhubCmd.Env(shell.EnvArgs{Shell: "bash"})).To(Succeed())
+			})
+
+			It("replaces the certificate with its file path", func() {
+				caCertPath := filepath.Join(tmpDir, "credhub-ca.crt")
+
+				mockAnalyticsClient.EXPECT().Event(cfanalytics.CREDHUB_ENV)
+				mockUI.EXPECT().Say(gomock.Any()).Do(func(arg string) {
+					Expect(arg).To(ContainSubstring(fmt.Sprintf(`export CREDHUB_CA_CERT='%s';`, caCertPath)))
+				})
+
+				Expect(credhubCmd.Env(shell.EnvArgs{Shell: "bash"})).To(Succeed())
+
+				contents, err := ioutil.ReadFile(caCertPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("some ca cert\nend pem block"))
+			})
+		})
+
+		Context("when the env.yml does not contain the credhub credentials", func() {
+			It("returns an error", func() {
+				mockAnalyticsClient.EXPECT().Event(cfanalytics.CREDHUB_ENV)
+
+				Expect(credhubCmd.Env(shell.EnvArgs{Shell: "bash"})).To(MatchError(ContainSubstring("CredHub credentials could not be found")))
+			})
+		})
+	})
+})
@@ -6,11 +6,13 @@ import (
 	e "code.cloudfoundry.org/cfdev/errors"
 	"code.cloudfoundry.org/cfdev/provision"
 	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"io"
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -28,8 +30,15 @@ type MetaDataReader interface {
 //go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/deploy-service Provisioner
 type Provisioner interface {
 	Ping(duration time.Duration) error
-	DeployServices(provision.UI, []workspace.Service, []string) error
+	DeployServices(context.Context, provision.UI, []workspace.Service, []string) error
 	GetWhiteListedService(string, []workspace.Service) (*workspace.Service, error)
+	ManifestDeploymentName(workspace.CustomDeployment) (string, error)
+	DeployManifest(context.Context, provision.UI, workspace.CustomDeployment) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/deploy-service Workspace
+type Workspace interface {
+	RecordDeployment(workspace.CustomDeployment) error
 }
 
 //go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/stop Analytics
@@ -44,38 +53,59 @@ type DeployService struct {
 	UI             UI
 	Provisioner    Provisioner
 	MetaDataReader MetaDataReader
+	Workspace      Workspace
 	Config         config.Config
 	Analytics      Analytics
 }
 
 type Args struct {
-	Service string
+	Service   string
+	Manifest  string
+	OpsFiles  []string
+	VarsFiles []string
+	Releases  []string
 }
 
 func (c *DeployService) Cmd() *cobra.Command {
+	args := Args{}
 	cmd := &cobra.Command{
 		Use:   "deploy-service",
-		RunE:  c.RunE,
 		Short: "Deploy a new service",
-		Long:  "Command deploy a new service provided as a parameter",
+		Long: `Command deploy a new service provided as a parameter.
+
+A BOSH manifest can be deployed instead with:
+  cf dev deploy-service --manifest my.yml [--ops-file x.yml] [--vars-file v.yml] [--release r.tgz]`,
+		RunE: func(_ *cobra.Command, positional []string) error {
+			go func() {
+				<-c.Exit
+				time.Sleep(provision.CancelGracePeriod)
+				os.Exit(128)
+			}()
+
+			if args.Manifest != "" {
+				if len(positional) != 0 {
+					return errors.New("A service name cannot be passed together with --manifest")
+				}
+
+				return c.Execute(args)
+			}
+
+			if len(positional) != 1 {
+				return errors.New("A service name need to be passed as a argument")
+			}
+
+			args.Service = positional[0]
+			return c.Execute(args)
+		},
 	}
 
-	return cmd
-}
+	pf := cmd.PersistentFlags()
+	pf.StringVarP(&args.Manifest, "manifest", "m", "", "path to a BOSH manifest to deploy")
+	pf.StringSliceVarP(&args.OpsFiles, "ops-file", "o", nil, "ops file to apply to the manifest (can be repeated)")
+	pf.StringSliceVarP(&args.VarsFiles, "vars-file", "l", nil, "vars file to interpolate the manifest with (can be repeated)")
+	pf.StringSliceVarP(&args.Releases, "release", "r", nil, "release tarball to upload before deploying (can be repeated)")
 
-func (c *DeployService) RunE(cmd *cobra.Command, args []string) error {
-	go func() {
-		<-c.Exit
-		os.Exit(128)
-	}()
-
-	if len(args) != 1 {
-		return errors.New("A service name need to be passed as a argument")
-	}
-
-	return c.Execute(Args{
-		Service: args[0],
-	})
+	return cmd
 }
 
 func (c *DeployService) Execute(args Args) error {
@@ -92,12 +122,27 @@ func (c *DeployService) Execute(args Args) error {
 		return fmt.Errorf("cf dev is not running. Please execute 'cf dev start'")
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.Exit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if args.Manifest != "" {
+		return c.deployManifest(ctx, args)
+	}
+
 	service, err := c.Provisioner.GetWhiteListedService(args.Service, metadataConfig.Services)
 	if err != nil {
 		return e.SafeWrap(err, "Failed to whitelist service")
 	}
 
-	if err := c.Provisioner.DeployServices(c.UI, []workspace.Service{*service}, []string{}); err != nil {
+	if err := c.Provisioner.DeployServices(ctx, c.UI, []workspace.Service{*service}, []string{}); err != nil {
 		return e.SafeWrap(err, "Failed to deploy services")
 	}
 
@@ -106,3 +151,66 @@ func (c *DeployService) Execute(args Args) error {
 
 	return nil
 }
+
+func (c *DeployService) deployManifest(ctx context.Context, args Args) error {
+	deployment := workspace.CustomDeployment{}
+
+	var err error
+	if deployment.Manifest, err = absPath(args.Manifest); err != nil {
+		return err
+	}
+
+	for _, path := range args.OpsFiles {
+		path, err := absPath(path)
+		if err != nil {
+			return err
+		}
+		deployment.OpsFiles = append(deployment.OpsFiles, path)
+	}
+
+	for _, path := range args.VarsFiles {
+		path, err := absPath(path)
+		if err != nil {
+			return err
+		}
+		deployment.VarsFiles = append(deployment.VarsFiles, path)
+	}
+
+	for _, path := range args.Releases {
+		path, err := absPath(path)
+		if err != nil {
+			return err
+		}
+		deployment.Releases = append(deployment.Releases, path)
+	}
+
+	deployment.Name, err = c.Provisioner.ManifestDeploymentName(deployment)
+	if err != nil {
+		return e.SafeWrap(err, "Failed to read the deployment name from the manifest")
+	}
+
+	if err := c.Provisioner.DeployManifest(ctx, c.UI, deployment); err != nil {
+		return e.SafeWrap(err, "Failed to deploy manifest")
+	}
+
+	if err := c.Workspace.RecordDeployment(deployment); err != nil {
+		return e.SafeWrap(err, "Failed to record the deployment")
+	}
+
+	c.Analytics.Event(cfanalytics.DEPLOY_SERVICE, map[string]interface{}{"custom_manifest": true})
+
+	return nil
+}
+
+func absPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(abs); err != nil {
+		return "", fmt.Errorf("file '%s' does not exist", path)
+	}
+
+	return abs, nil
+}
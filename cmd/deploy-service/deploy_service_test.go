@@ -1,11 +1,17 @@
 package deploy_service_test
 
 import (
+	"context"
+
 	"code.cloudfoundry.org/cfdev/cmd/deploy-service"
 	"code.cloudfoundry.org/cfdev/cmd/deploy-service/mocks"
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/workspace"
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -17,6 +23,7 @@ var _ = Describe("DeployService", func() {
 		mockMetadataReader *mocks.MockMetaDataReader
 		mockProvisioner    *mocks.MockProvisioner
 		mockUI             *mocks.MockUI
+		mockWorkspace      *mocks.MockWorkspace
 		mockAnalytics      *mocks.MockAnalytics
 		cmd                *deploy_service.DeployService
 	)
@@ -25,12 +32,14 @@ var _ = Describe("DeployService", func() {
 		mockUI = mocks.NewMockUI(mockController)
 		mockMetadataReader = mocks.NewMockMetaDataReader(mockController)
 		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
 		mockAnalytics = mocks.NewMockAnalytics(mockController)
 
 		cmd = &deploy_service.DeployService{
 			UI:             mockUI,
 			MetaDataReader: mockMetadataReader,
 			Provisioner:    mockProvisioner,
+			Workspace:      mockWorkspace,
 			Config: config.Config{
 				StateDir: "some-state-dir",
 			},
@@ -52,7 +61,7 @@ var _ = Describe("DeployService", func() {
 
 			mockProvisioner.EXPECT().Ping(gomock.Any()).Return(nil)
 			mockProvisioner.EXPECT().GetWhiteListedService("some-service", []workspace.Service{service}).Return(&service, nil)
-			mockProvisioner.EXPECT().DeployServices(mockUI, []workspace.Service{service}, []string{}).Return(nil)
+			mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, []workspace.Service{service}, []string{}).Return(nil)
 
 			mockAnalytics.EXPECT().Event("deployed service", map[string]interface{}{"name": "some-service"})
 
@@ -103,4 +112,83 @@ var _ = Describe("DeployService", func() {
 			Expect(err.Error()).To(ContainSubstring("Failed to whitelist service"))
 		})
 	})
+
+	Describe("deploying a manifest", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "cfdev-deploy-manifest-")
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, name := range []string{"my.yml", "ops.yml", "vars.yml", "release.tgz"} {
+				Expect(ioutil.WriteFile(filepath.Join(tmpDir, name), []byte("some-content"), 0600)).To(Succeed())
+			}
+
+			mockMetadataReader.EXPECT().Metadata().Return(workspace.Metadata{Version: "v5"}, nil)
+			mockProvisioner.EXPECT().Ping(gomock.Any()).Return(nil)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("deploys the manifest and records the deployment", func() {
+			deployment := workspace.CustomDeployment{
+				Manifest:  filepath.Join(tmpDir, "my.yml"),
+				OpsFiles:  []string{filepath.Join(tmpDir, "ops.yml")},
+				VarsFiles: []string{filepath.Join(tmpDir, "vars.yml")},
+				Releases:  []string{filepath.Join(tmpDir, "release.tgz")},
+			}
+
+			mockProvisioner.EXPECT().ManifestDeploymentName(deployment).Return("my-deployment", nil)
+
+			deployment.Name = "my-deployment"
+			gomock.InOrder(
+				mockProvisioner.EXPECT().DeployManifest(gomock.Any(), mockUI, deployment),
+				mockWorkspace.EXPECT().RecordDeployment(deployment),
+				mockAnalytics.EXPECT().Event("deployed service", map[string]interface{}{"custom_manifest": true}),
+			)
+
+			err := cmd.Execute(deploy_service.Args{
+				Manifest:  filepath.Join(tmpDir, "my.yml"),
+				OpsFiles:  []string{filepath.Join(tmpDir, "ops.yml")},
+				VarsFiles: []string{filepath.Join(tmpDir, "vars.yml")},
+				Releases:  []string{filepath.Join(tmpDir, "release.tgz")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does not record a failed deployment", func() {
+			mockProvisioner.EXPECT().ManifestDeploymentName(gomock.Any()).Return("my-deployment", nil)
+			mockProvisioner.EXPECT().DeployManifest(gomock.Any(), mockUI, gomock.Any()).Return(errors.New("some-error"))
+
+			err := cmd.Execute(deploy_service.Args{Manifest: filepath.Join(tmpDir, "my.yml")})
+			Expect(err).To(MatchError(ContainSubstring("Failed to deploy manifest")))
+		})
+
+		It("cancels the deployment on exit", func() {
+			exit := make(chan struct{})
+			cmd.Exit = exit
+
+			mockProvisioner.EXPECT().ManifestDeploymentName(gomock.Any()).Return("my-deployment", nil)
+			mockProvisioner.EXPECT().DeployManifest(gomock.Any(), mockUI, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, _ interface{}, _ workspace.CustomDeployment) error {
+					close(exit)
+					<-ctx.Done()
+					return errors.New("the deployment was cancelled")
+				})
+
+			err := cmd.Execute(deploy_service.Args{Manifest: filepath.Join(tmpDir, "my.yml")})
+			Expect(err).To(MatchError(ContainSubstring("the deployment was cancelled")))
+		})
+
+		It("returns an error when a file is missing", func() {
+			err := cmd.Execute(deploy_service.Args{
+				Manifest: filepath.Join(tmpDir, "my.yml"),
+				OpsFiles: []string{filepath.Join(tmpDir, "missing.yml")},
+			})
+			Expect(err).To(MatchError(ContainSubstring("missing.yml' does not exist")))
+		})
+	})
 })
@@ -7,6 +7,7 @@ package mocks
 import (
 	provision "code.cloudfoundry.org/cfdev/provision"
 	workspace "code.cloudfoundry.org/cfdev/workspace"
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 	time "time"
@@ -35,16 +36,28 @@ func (m *MockProvisioner) EXPECT() *MockProvisionerMockRecorder {
 	return m.recorder
 }
 
+// DeployManifest mocks base method
+func (m *MockProvisioner) DeployManifest(arg0 context.Context, arg1 provision.UI, arg2 workspace.CustomDeployment) error {
+	ret := m.ctrl.Call(m, "DeployManifest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeployManifest indicates an expected call of DeployManifest
+func (mr *MockProvisionerMockRecorder) DeployManifest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployManifest", reflect.TypeOf((*MockProvisioner)(nil).DeployManifest), arg0, arg1, arg2)
+}
+
 // DeployServices mocks base method
-func (m *MockProvisioner) DeployServices(arg0 provision.UI, arg1 []workspace.Service, arg2 []string) error {
-	ret := m.ctrl.Call(m, "DeployServices", arg0, arg1, arg2)
+func (m *MockProvisioner) DeployServices(arg0 context.Context, arg1 provision.UI, arg2 []workspace.Service, arg3 []string) error {
+	ret := m.ctrl.Call(m, "DeployServices", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeployServices indicates an expected call of DeployServices
-func (mr *MockProvisionerMockRecorder) DeployServices(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployServices", reflect.TypeOf((*MockProvisioner)(nil).DeployServices), arg0, arg1, arg2)
+func (mr *MockProvisionerMockRecorder) DeployServices(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployServices", reflect.TypeOf((*MockProvisioner)(nil).DeployServices), arg0, arg1, arg2, arg3)
 }
 
 // GetWhiteListedService mocks base method
@@ -60,6 +73,19 @@ func (mr *MockProvisionerMockRecorder) GetWhiteListedService(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWhiteListedService", reflect.TypeOf((*MockProvisioner)(nil).GetWhiteListedService), arg0, arg1)
 }
 
+// ManifestDeploymentName mocks base method
+func (m *MockProvisioner) ManifestDeploymentName(arg0 workspace.CustomDeployment) (string, error) {
+	ret := m.ctrl.Call(m, "ManifestDeploymentName", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ManifestDeploymentName indicates an expected call of ManifestDeploymentName
+func (mr *MockProvisionerMockRecorder) ManifestDeploymentName(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManifestDeploymentName", reflect.TypeOf((*MockProvisioner)(nil).ManifestDeploymentName), arg0)
+}
+
 // Ping mocks base method
 func (m *MockProvisioner) Ping(arg0 time.Duration) error {
 	ret := m.ctrl.Call(m, "Ping", arg0)
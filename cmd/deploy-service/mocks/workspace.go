@@ -0,0 +1,46 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/deploy-service (interfaces: Workspace)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	workspace "code.cloudfoundry.org/cfdev/workspace"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWorkspace is a mock of Workspace interface
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// RecordDeployment mocks base method
+func (m *MockWorkspace) RecordDeployment(arg0 workspace.CustomDeployment) error {
+	ret := m.ctrl.Call(m, "RecordDeployment", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDeployment indicates an expected call of RecordDeployment
+func (mr *MockWorkspaceMockRecorder) RecordDeployment(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeployment", reflect.TypeOf((*MockWorkspace)(nil).RecordDeployment), arg0)
+}
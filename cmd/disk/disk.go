@@ -0,0 +1,314 @@
+package disk
+
+import (
+	"code.cloudfoundry.org/bytefmt"
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/driver"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/disk UI
+type UI interface {
+	Say(message string, args ...interface{})
+	Writer() io.Writer
+}
+
+//go:generate mockgen -package mocks -destination mocks/driver.go code.cloudfoundry.org/cfdev/cmd/disk Driver
+type Driver interface {
+	driver.Driver
+	driver.DiskManager
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/disk Provisioner
+type Provisioner interface {
+	Ping(duration time.Duration) error
+	DeployBosh() error
+	RecoverDeployments(ui provision.UI) error
+	GuestDiskUsage() ([]provision.FileSystem, error)
+	TrimDisk() (string, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/disk Workspace
+type Workspace interface {
+	Settings() (workspace.Settings, error)
+	SaveSettings(settings workspace.Settings) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/disk Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+type Disk struct {
+	Exit        chan struct{}
+	UI          UI
+	Config      config.Config
+	Driver      driver.Driver
+	Provisioner Provisioner
+	Workspace   Workspace
+	Analytics   Analytics
+}
+
+func (d *Disk) Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disk",
+		Short: "Inspect, compact and grow the disk of the VM",
+	}
+
+	usageCmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Show the space the disk of the VM takes on the host, and how much of it the VM uses",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := d.Usage(); err != nil {
+				return e.SafeWrap(err, "cf dev disk usage")
+			}
+			return nil
+		},
+	}
+
+	compactCmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Reclaim the space the VM no longer uses, restarting the VM when it runs",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := d.Compact(); err != nil {
+				return e.SafeWrap(err, "cf dev disk compact")
+			}
+			return nil
+		},
+	}
+
+	growCmd := &cobra.Command{
+		Use:   "grow <size in GB>",
+		Short: "Grow the disk of the running VM, restarting it",
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("the new size of the disk in GB needs to be passed as an argument")
+			}
+
+			size, err := strconv.Atoi(strings.TrimSuffix(strings.ToUpper(args[0]), "G"))
+			if err != nil || size <= 0 {
+				return fmt.Errorf("invalid disk size '%s', it must be a number of GB", args[0])
+			}
+
+			if err := d.Grow(size); err != nil {
+				return e.SafeWrap(err, "cf dev disk grow")
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(usageCmd, compactCmd, growCmd)
+	return cmd
+}
+
+func (d *Disk) Usage() error {
+	go func() {
+		<-d.Exit
+		os.Exit(128)
+	}()
+
+	d.Analytics.Event(cfanalytics.DISK_USAGE)
+
+	manager, err := d.manager()
+	if err != nil {
+		return err
+	}
+
+	usage, err := manager.DiskUsage()
+	if os.IsNotExist(err) {
+		return errors.New("there is no environment. Please execute 'cf dev start'")
+	} else if err != nil {
+		return err
+	}
+
+	d.UI.Say("Disk of the VM: %s", usage.Path)
+	d.UI.Say("  Size: %s", bytefmt.ByteSize(usage.Size))
+	d.UI.Say("  Space taken on the host: %s", bytefmt.ByteSize(usage.Allocated))
+
+	if running, err := d.Driver.IsRunning(); err != nil || !running {
+		d.UI.Say("The VM is not running, start it to see the usage of its file systems.")
+		return nil
+	}
+
+	fileSystems, err := d.Provisioner.GuestDiskUsage()
+	if err != nil {
+		return err
+	}
+
+	var used uint64
+	d.UI.Say("File systems of the VM:")
+	for _, fs := range fileSystems {
+		used += fs.Used
+		d.UI.Say("  %s on %s: %s used of %s (%d%%)", fs.Device, fs.MountPoint, bytefmt.ByteSize(fs.Used), bytefmt.ByteSize(fs.Size), percent(fs.Used, fs.Size))
+	}
+
+	if usage.Allocated > used+1<<30 {
+		d.UI.Say("Run 'cf dev disk compact' to reclaim up to %s.", bytefmt.ByteSize(usage.Allocated-used))
+	}
+
+	return nil
+}
+
+func (d *Disk) Compact() error {
+	go func() {
+		<-d.Exit
+		os.Exit(128)
+	}()
+
+	d.Analytics.Event(cfanalytics.DISK_COMPACT)
+
+	manager, err := d.manager()
+	if err != nil {
+		return err
+	}
+
+	before, err := manager.DiskUsage()
+	if os.IsNotExist(err) {
+		return errors.New("there is no environment. Please execute 'cf dev start'")
+	} else if err != nil {
+		return err
+	}
+
+	running, err := d.Driver.IsRunning()
+	if err != nil {
+		return e.SafeWrap(err, "is running")
+	}
+
+	var settings workspace.Settings
+	if running {
+		settings, err = d.Workspace.Settings()
+		if err != nil {
+			return e.SafeWrap(err, "there is no provisioned environment to restart")
+		}
+
+		d.UI.Say("Trimming the file systems of the VM...")
+		output, err := d.Provisioner.TrimDisk()
+		if err != nil {
+			return err
+		}
+
+		for _, line := range strings.Split(output, "\n") {
+			if line != "" {
+				d.UI.Say("  %s", line)
+			}
+		}
+
+		d.UI.Say("Stopping the VM...")
+		if err := d.Driver.Stop(); err != nil {
+			return e.SafeWrap(err, "failed to stop the VM")
+		}
+	} else {
+		d.UI.Say("The VM is not running, so its file systems cannot be trimmed first.")
+	}
+
+	d.UI.Say("Compacting the disk of the VM...")
+	if err := manager.CompactDisk(); err != nil {
+		return err
+	}
+
+	if after, err := manager.DiskUsage(); err == nil {
+		d.UI.Say("The disk of the VM takes %s on the host, down from %s.", bytefmt.ByteSize(after.Allocated), bytefmt.ByteSize(before.Allocated))
+	}
+
+	if !running {
+		return nil
+	}
+
+	return d.restart(settings)
+}
+
+func (d *Disk) Grow(size int) error {
+	go func() {
+		<-d.Exit
+		os.Exit(128)
+	}()
+
+	d.Analytics.Event(cfanalytics.DISK_GROW, map[string]interface{}{"size": size})
+
+	if running, err := d.Driver.IsRunning(); err != nil {
+		return e.SafeWrap(err, "is running")
+	} else if !running {
+		return fmt.Errorf("CF Dev is not running. Please execute 'cf dev start --disk %d'", size)
+	}
+
+	settings, err := d.Workspace.Settings()
+	if err != nil {
+		return e.SafeWrap(err, "there is no provisioned environment to grow")
+	}
+
+	if current := settings.DiskSize(); size <= current {
+		return fmt.Errorf("the disk of the VM is %d GB, it can only grow", current)
+	}
+
+	d.UI.Say("Stopping the VM...")
+	if err := d.Driver.Stop(); err != nil {
+		return e.SafeWrap(err, "failed to stop the VM")
+	}
+
+	settings.Disk = size
+	if err := d.Workspace.SaveSettings(settings); err != nil {
+		return e.SafeWrap(err, "Unable to save the settings")
+	}
+
+	if err := d.restart(settings); err != nil {
+		return err
+	}
+
+	d.UI.Say("The disk of the VM is now %d GB.", size)
+	return nil
+}
+
+// manager returns the driver when it can manage the disk of the VM.
+func (d *Disk) manager() (driver.DiskManager, error) {
+	manager, ok := d.Driver.(driver.DiskManager)
+	if !ok {
+		return nil, errors.New("managing the disk of the VM is not supported on this platform")
+	}
+
+	return manager, nil
+}
+
+// restart boots the environment again, as 'cf dev import' does.
+func (d *Disk) restart(settings workspace.Settings) error {
+	err := d.Driver.Start(settings.Cpus, settings.Memory, settings.DiskSize(), filepath.Join(d.Config.BinaryDir, "cfdev-efi-v2.iso"))
+	if err != nil {
+		return err
+	}
+
+	d.UI.Say("Waiting for the VM...")
+	if err := d.Provisioner.Ping(2 * time.Minute); err != nil {
+		return e.SafeWrap(err, "Timed out waiting for the VM")
+	}
+
+	d.UI.Say("Starting the BOSH Director...")
+	if err := d.Provisioner.DeployBosh(); err != nil {
+		return e.SafeWrap(err, "Failed to start the BOSH Director")
+	}
+
+	if err := d.Provisioner.RecoverDeployments(d.UI); err != nil {
+		return e.SafeWrap(err, "Failed to recover the deployments")
+	}
+
+	return nil
+}
+
+func percent(part, total uint64) uint64 {
+	if total == 0 {
+		return 0
+	}
+
+	return part * 100 / total
+}
@@ -0,0 +1,194 @@
+package disk_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/disk"
+	"code.cloudfoundry.org/cfdev/cmd/disk/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/driver"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Disk", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockDriver      *mocks.MockDriver
+		mockProvisioner *mocks.MockProvisioner
+		mockWorkspace   *mocks.MockWorkspace
+		mockAnalytics   *mocks.MockAnalytics
+		cmd             *disk.Disk
+		usage           driver.DiskUsage
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockDriver = mocks.NewMockDriver(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		cmd = &disk.Disk{
+			UI:          mockUI,
+			Config:      config.Config{BinaryDir: "/home/.cfdev/bin"},
+			Driver:      mockDriver,
+			Provisioner: mockProvisioner,
+			Workspace:   mockWorkspace,
+			Analytics:   mockAnalytics,
+		}
+
+		usage = driver.DiskUsage{Path: "/home/.cfdev/state/linuxkit/disk.qcow2", Size: 120 << 30, Allocated: 40 << 30}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	Describe("usage", func() {
+		BeforeEach(func() {
+			mockAnalytics.EXPECT().Event(cfanalytics.DISK_USAGE)
+		})
+
+		It("compares the disk on the host with the file systems of the VM", func() {
+			gomock.InOrder(
+				mockDriver.EXPECT().DiskUsage().Return(usage, nil),
+				mockUI.EXPECT().Say("Disk of the VM: %s", "/home/.cfdev/state/linuxkit/disk.qcow2"),
+				mockUI.EXPECT().Say("  Size: %s", "120G"),
+				mockUI.EXPECT().Say("  Space taken on the host: %s", "40G"),
+				mockDriver.EXPECT().IsRunning().Return(true, nil),
+				mockProvisioner.EXPECT().GuestDiskUsage().Return([]provision.FileSystem{
+					{Device: "/dev/vda1", MountPoint: "/var/lib", Size: 118 << 30, Used: 30 << 30},
+				}, nil),
+				mockUI.EXPECT().Say("File systems of the VM:"),
+				mockUI.EXPECT().Say("  %s on %s: %s used of %s (%d%%)", "/dev/vda1", "/var/lib", "30G", "118G", uint64(25)),
+				mockUI.EXPECT().Say("Run 'cf dev disk compact' to reclaim up to %s.", "10G"),
+			)
+
+			Expect(cmd.Usage()).To(Succeed())
+		})
+
+		It("only reports the host side when the VM is stopped", func() {
+			gomock.InOrder(
+				mockDriver.EXPECT().DiskUsage().Return(usage, nil),
+				mockUI.EXPECT().Say(gomock.Any(), gomock.Any()).Times(3),
+				mockDriver.EXPECT().IsRunning().Return(false, nil),
+				mockUI.EXPECT().Say("The VM is not running, start it to see the usage of its file systems."),
+			)
+
+			Expect(cmd.Usage()).To(Succeed())
+		})
+
+		It("fails when there is no environment", func() {
+			mockDriver.EXPECT().DiskUsage().Return(driver.DiskUsage{}, &os.PathError{Op: "open", Path: usage.Path, Err: os.ErrNotExist})
+
+			Expect(cmd.Usage()).To(MatchError("there is no environment. Please execute 'cf dev start'"))
+		})
+	})
+
+	Describe("compact", func() {
+		BeforeEach(func() {
+			mockAnalytics.EXPECT().Event(cfanalytics.DISK_COMPACT)
+		})
+
+		It("trims the file systems, compacts the disk offline and restarts the environment", func() {
+			compacted := usage
+			compacted.Allocated = 25 << 30
+
+			gomock.InOrder(
+				mockDriver.EXPECT().DiskUsage().Return(usage, nil),
+				mockDriver.EXPECT().IsRunning().Return(true, nil),
+				mockWorkspace.EXPECT().Settings().Return(workspace.Settings{Cpus: 4, Memory: 8192, Disk: 200}, nil),
+				mockUI.EXPECT().Say("Trimming the file systems of the VM..."),
+				mockProvisioner.EXPECT().TrimDisk().Return("/var/lib: 15 GiB (16106127360 bytes) trimmed", nil),
+				mockUI.EXPECT().Say("  %s", "/var/lib: 15 GiB (16106127360 bytes) trimmed"),
+				mockUI.EXPECT().Say("Stopping the VM..."),
+				mockDriver.EXPECT().Stop(),
+				mockUI.EXPECT().Say("Compacting the disk of the VM..."),
+				mockDriver.EXPECT().CompactDisk(),
+				mockDriver.EXPECT().DiskUsage().Return(compacted, nil),
+				mockUI.EXPECT().Say("The disk of the VM takes %s on the host, down from %s.", "25G", "40G"),
+				mockDriver.EXPECT().Start(4, 8192, 200, "/home/.cfdev/bin/cfdev-efi-v2.iso"),
+				mockUI.EXPECT().Say("Waiting for the VM..."),
+				mockProvisioner.EXPECT().Ping(2*time.Minute),
+				mockUI.EXPECT().Say("Starting the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RecoverDeployments(mockUI),
+			)
+
+			Expect(cmd.Compact()).To(Succeed())
+		})
+
+		It("compacts the disk of a stopped environment without starting it", func() {
+			gomock.InOrder(
+				mockDriver.EXPECT().DiskUsage().Return(usage, nil),
+				mockDriver.EXPECT().IsRunning().Return(false, nil),
+				mockUI.EXPECT().Say("The VM is not running, so its file systems cannot be trimmed first."),
+				mockUI.EXPECT().Say("Compacting the disk of the VM..."),
+				mockDriver.EXPECT().CompactDisk(),
+				mockDriver.EXPECT().DiskUsage().Return(usage, nil),
+				mockUI.EXPECT().Say(gomock.Any(), "40G", "40G"),
+			)
+
+			Expect(cmd.Compact()).To(Succeed())
+		})
+
+		It("does not stop the VM when trimming fails", func() {
+			gomock.InOrder(
+				mockDriver.EXPECT().DiskUsage().Return(usage, nil),
+				mockDriver.EXPECT().IsRunning().Return(true, nil),
+				mockWorkspace.EXPECT().Settings().Return(workspace.Settings{Cpus: 4, Memory: 8192}, nil),
+				mockUI.EXPECT().Say("Trimming the file systems of the VM..."),
+				mockProvisioner.EXPECT().TrimDisk().Return("", errors.New("trimming the disk of the VM: fstrim: not found")),
+			)
+
+			Expect(cmd.Compact()).To(MatchError("trimming the disk of the VM: fstrim: not found"))
+		})
+	})
+
+	Describe("grow", func() {
+		BeforeEach(func() {
+			mockAnalytics.EXPECT().Event(cfanalytics.DISK_GROW, map[string]interface{}{"size": 200})
+		})
+
+		It("restarts the environment with a larger disk", func() {
+			gomock.InOrder(
+				mockDriver.EXPECT().IsRunning().Return(true, nil),
+				mockWorkspace.EXPECT().Settings().Return(workspace.Settings{Cpus: 4, Memory: 8192}, nil),
+				mockUI.EXPECT().Say("Stopping the VM..."),
+				mockDriver.EXPECT().Stop(),
+				mockWorkspace.EXPECT().SaveSettings(workspace.Settings{Cpus: 4, Memory: 8192, Disk: 200}),
+				mockDriver.EXPECT().Start(4, 8192, 200, "/home/.cfdev/bin/cfdev-efi-v2.iso"),
+				mockUI.EXPECT().Say("Waiting for the VM..."),
+				mockProvisioner.EXPECT().Ping(2*time.Minute),
+				mockUI.EXPECT().Say("Starting the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RecoverDeployments(mockUI),
+				mockUI.EXPECT().Say("The disk of the VM is now %d GB.", 200),
+			)
+
+			Expect(cmd.Grow(200)).To(Succeed())
+		})
+
+		It("refuses to shrink the disk", func() {
+			mockDriver.EXPECT().IsRunning().Return(true, nil)
+			mockWorkspace.EXPECT().Settings().Return(workspace.Settings{Disk: 250}, nil)
+
+			Expect(cmd.Grow(200)).To(MatchError("the disk of the VM is 250 GB, it can only grow"))
+		})
+
+		It("points to cf dev start when the environment is stopped", func() {
+			mockDriver.EXPECT().IsRunning().Return(false, nil)
+
+			Expect(cmd.Grow(200)).To(MatchError("CF Dev is not running. Please execute 'cf dev start --disk 200'"))
+		})
+	})
+})
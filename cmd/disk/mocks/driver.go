@@ -0,0 +1,135 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/disk (interfaces: Driver)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	driver "code.cloudfoundry.org/cfdev/driver"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	net "net"
+	reflect "reflect"
+)
+
+// MockDriver is a mock of Driver interface
+type MockDriver struct {
+	ctrl     *gomock.Controller
+	recorder *MockDriverMockRecorder
+}
+
+// MockDriverMockRecorder is the mock recorder for MockDriver
+type MockDriverMockRecorder struct {
+	mock *MockDriver
+}
+
+// NewMockDriver creates a new mock instance
+func NewMockDriver(ctrl *gomock.Controller) *MockDriver {
+	mock := &MockDriver{ctrl: ctrl}
+	mock.recorder = &MockDriverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDriver) EXPECT() *MockDriverMockRecorder {
+	return m.recorder
+}
+
+// Address mocks base method
+func (m *MockDriver) Address(arg0 context.Context) (net.IP, error) {
+	ret := m.ctrl.Call(m, "Address", arg0)
+	ret0, _ := ret[0].(net.IP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Address indicates an expected call of Address
+func (mr *MockDriverMockRecorder) Address(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Address", reflect.TypeOf((*MockDriver)(nil).Address), arg0)
+}
+
+// CheckRequirements mocks base method
+func (m *MockDriver) CheckRequirements() error {
+	ret := m.ctrl.Call(m, "CheckRequirements")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckRequirements indicates an expected call of CheckRequirements
+func (mr *MockDriverMockRecorder) CheckRequirements() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRequirements", reflect.TypeOf((*MockDriver)(nil).CheckRequirements))
+}
+
+// CompactDisk mocks base method
+func (m *MockDriver) CompactDisk() error {
+	ret := m.ctrl.Call(m, "CompactDisk")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompactDisk indicates an expected call of CompactDisk
+func (mr *MockDriverMockRecorder) CompactDisk() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompactDisk", reflect.TypeOf((*MockDriver)(nil).CompactDisk))
+}
+
+// DiskUsage mocks base method
+func (m *MockDriver) DiskUsage() (driver.DiskUsage, error) {
+	ret := m.ctrl.Call(m, "DiskUsage")
+	ret0, _ := ret[0].(driver.DiskUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiskUsage indicates an expected call of DiskUsage
+func (mr *MockDriverMockRecorder) DiskUsage() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiskUsage", reflect.TypeOf((*MockDriver)(nil).DiskUsage))
+}
+
+// IsRunning mocks base method
+func (m *MockDriver) IsRunning() (bool, error) {
+	ret := m.ctrl.Call(m, "IsRunning")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRunning indicates an expected call of IsRunning
+func (mr *MockDriverMockRecorder) IsRunning() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRunning", reflect.TypeOf((*MockDriver)(nil).IsRunning))
+}
+
+// Prestart mocks base method
+func (m *MockDriver) Prestart() error {
+	ret := m.ctrl.Call(m, "Prestart")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Prestart indicates an expected call of Prestart
+func (mr *MockDriverMockRecorder) Prestart() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prestart", reflect.TypeOf((*MockDriver)(nil).Prestart))
+}
+
+// Start mocks base method
+func (m *MockDriver) Start(arg0, arg1, arg2 int, arg3 string) error {
+	ret := m.ctrl.Call(m, "Start", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start
+func (mr *MockDriverMockRecorder) Start(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockDriver)(nil).Start), arg0, arg1, arg2, arg3)
+}
+
+// Stop mocks base method
+func (m *MockDriver) Stop() error {
+	ret := m.ctrl.Call(m, "Stop")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop
+func (mr *MockDriverMockRecorder) Stop() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockDriver)(nil).Stop))
+}
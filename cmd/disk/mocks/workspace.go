@@ -0,0 +1,59 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/disk (interfaces: Workspace)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	workspace "code.cloudfoundry.org/cfdev/workspace"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWorkspace is a mock of Workspace interface
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// SaveSettings mocks base method
+func (m *MockWorkspace) SaveSettings(arg0 workspace.Settings) error {
+	ret := m.ctrl.Call(m, "SaveSettings", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSettings indicates an expected call of SaveSettings
+func (mr *MockWorkspaceMockRecorder) SaveSettings(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSettings", reflect.TypeOf((*MockWorkspace)(nil).SaveSettings), arg0)
+}
+
+// Settings mocks base method
+func (m *MockWorkspace) Settings() (workspace.Settings, error) {
+	ret := m.ctrl.Call(m, "Settings")
+	ret0, _ := ret[0].(workspace.Settings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Settings indicates an expected call of Settings
+func (mr *MockWorkspaceMockRecorder) Settings() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settings", reflect.TypeOf((*MockWorkspace)(nil).Settings))
+}
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/export UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/export Workspace
+type Workspace interface {
+	Metadata() (workspace.Metadata, error)
+	Settings() (workspace.Settings, error)
+	Export(path string, manifest workspace.ExportManifest) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/driver.go code.cloudfoundry.org/cfdev/cmd/export Driver
+type Driver interface {
+	IsRunning() (bool, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/export Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+type Export struct {
+	Exit      chan struct{}
+	UI        UI
+	Config    config.Config
+	Workspace Workspace
+	Driver    Driver
+	Analytics Analytics
+}
+
+func (x *Export) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export <path>",
+		Short: "Package a provisioned environment to be imported elsewhere",
+		RunE: func(_ *cobra.Command, args []string) error {
+			go func() {
+				<-x.Exit
+				os.Exit(128)
+			}()
+
+			if len(args) != 1 {
+				return errors.New("the path of the package needs to be passed as an argument")
+			}
+
+			if err := x.Execute(args[0]); err != nil {
+				return e.SafeWrap(err, "cf dev export")
+			}
+			return nil
+		},
+	}
+}
+
+func (x *Export) Execute(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return e.SafeWrap(err, "determining absolute path to the package")
+	}
+
+	if running, err := x.Driver.IsRunning(); err != nil {
+		return e.SafeWrap(err, "is running")
+	} else if running {
+		return errors.New("CF Dev needs to be stopped before it can be exported. Please execute 'cf dev stop'")
+	}
+
+	metadata, err := x.Workspace.Metadata()
+	if err != nil {
+		return e.SafeWrap(err, "there is no environment to export. Please execute 'cf dev start'")
+	}
+
+	settings, err := x.Workspace.Settings()
+	if err != nil {
+		return e.SafeWrap(err, "there is no provisioned environment to export. Please execute 'cf dev start'")
+	}
+
+	x.UI.Say("Exporting the environment to %s...", path)
+	err = x.Workspace.Export(path, workspace.ExportManifest{
+		PluginVersion:        x.Config.CliVersion.Original,
+		ArtifactVersion:      metadata.ArtifactVersion,
+		CompatibilityVersion: metadata.Version,
+		Platform:             runtime.GOOS,
+		Settings:             settings,
+	})
+	if err != nil {
+		os.Remove(path)
+		return e.SafeWrap(err, "failed to export the environment")
+	}
+
+	x.Analytics.Event(cfanalytics.EXPORT, map[string]interface{}{"artifact": metadata.ArtifactVersion})
+	x.UI.Say("Exported the environment. Import it with 'cf dev import %s'", filepath.Base(path))
+	return nil
+}
@@ -0,0 +1,83 @@
+package export_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/export"
+	"code.cloudfoundry.org/cfdev/cmd/export/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+	"runtime"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Export", func() {
+	var (
+		mockController *gomock.Controller
+		mockUI         *mocks.MockUI
+		mockWorkspace  *mocks.MockWorkspace
+		mockDriver     *mocks.MockDriver
+		mockAnalytics  *mocks.MockAnalytics
+		cmd            *export.Export
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
+		mockDriver = mocks.NewMockDriver(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		version, _ := config.NewSemver("0.0.16")
+		cmd = &export.Export{
+			UI:        mockUI,
+			Config:    config.Config{CliVersion: version},
+			Workspace: mockWorkspace,
+			Driver:    mockDriver,
+			Analytics: mockAnalytics,
+		}
+
+		mockUI.EXPECT().Say(gomock.Any(), gomock.Any()).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("packages the environment with its manifest", func() {
+		settings := workspace.Settings{Cpus: 4, Memory: 8192}
+
+		gomock.InOrder(
+			mockDriver.EXPECT().IsRunning().Return(false, nil),
+			mockWorkspace.EXPECT().Metadata().Return(workspace.Metadata{Version: "v5", ArtifactVersion: "some-version"}, nil),
+			mockWorkspace.EXPECT().Settings().Return(settings, nil),
+			mockWorkspace.EXPECT().Export("/some/env.tgz", workspace.ExportManifest{
+				PluginVersion:        "0.0.16",
+				ArtifactVersion:      "some-version",
+				CompatibilityVersion: "v5",
+				Platform:             runtime.GOOS,
+				Settings:             settings,
+			}),
+			mockAnalytics.EXPECT().Event(cfanalytics.EXPORT, map[string]interface{}{"artifact": "some-version"}),
+		)
+
+		Expect(cmd.Execute("/some/env.tgz")).To(Succeed())
+	})
+
+	It("refuses to export a running environment", func() {
+		mockDriver.EXPECT().IsRunning().Return(true, nil)
+
+		Expect(cmd.Execute("/some/env.tgz")).To(MatchError(ContainSubstring("cf dev stop")))
+	})
+
+	It("refuses to export an environment that was never started", func() {
+		mockDriver.EXPECT().IsRunning().Return(false, nil)
+		mockWorkspace.EXPECT().Metadata().Return(workspace.Metadata{Version: "v5"}, nil)
+		mockWorkspace.EXPECT().Settings().Return(workspace.Settings{}, errors.New("no settings"))
+
+		Expect(cmd.Execute("/some/env.tgz")).To(MatchError(ContainSubstring("no settings")))
+	})
+})
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/export (interfaces: Workspace)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	workspace "code.cloudfoundry.org/cfdev/workspace"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWorkspace is a mock of Workspace interface
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method
+func (m *MockWorkspace) Export(arg0 string, arg1 workspace.ExportManifest) error {
+	ret := m.ctrl.Call(m, "Export", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export
+func (mr *MockWorkspaceMockRecorder) Export(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockWorkspace)(nil).Export), arg0, arg1)
+}
+
+// Metadata mocks base method
+func (m *MockWorkspace) Metadata() (workspace.Metadata, error) {
+	ret := m.ctrl.Call(m, "Metadata")
+	ret0, _ := ret[0].(workspace.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Metadata indicates an expected call of Metadata
+func (mr *MockWorkspaceMockRecorder) Metadata() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metadata", reflect.TypeOf((*MockWorkspace)(nil).Metadata))
+}
+
+// Settings mocks base method
+func (m *MockWorkspace) Settings() (workspace.Settings, error) {
+	ret := m.ctrl.Call(m, "Settings")
+	ret0, _ := ret[0].(workspace.Settings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Settings indicates an expected call of Settings
+func (mr *MockWorkspaceMockRecorder) Settings() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settings", reflect.TypeOf((*MockWorkspace)(nil).Settings))
+}
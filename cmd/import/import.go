@@ -0,0 +1,252 @@
+package import_env
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/resource"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/import UI
+type UI interface {
+	Say(message string, args ...interface{})
+	Writer() io.Writer
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/import Workspace
+type Workspace interface {
+	CreateDirs() error
+	Settings() (workspace.Settings, error)
+	ReadExportManifest(path string) (workspace.ExportManifest, error)
+	Import(path string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/driver.go code.cloudfoundry.org/cfdev/cmd/import Driver
+type Driver interface {
+	CheckRequirements() error
+	Prestart() error
+	Start(cpus int, memory int, disk int, efiPath string) error
+	Stop() error
+	IsRunning() (bool, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/stop.go code.cloudfoundry.org/cfdev/cmd/import Stop
+type Stop interface {
+	RunE(cmd *cobra.Command, args []string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/cache.go code.cloudfoundry.org/cfdev/cmd/import Cache
+type Cache interface {
+	Sync(resource.Catalog) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/import Provisioner
+type Provisioner interface {
+	Ping(duration time.Duration) error
+	DeployBosh() error
+	RecoverDeployments(ui provision.UI) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/import Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+const compatibilityVersion = "v5"
+
+type Args struct {
+	Path  string
+	Force bool
+}
+
+type Import struct {
+	Exit        chan struct{}
+	UI          UI
+	Config      config.Config
+	Workspace   Workspace
+	Driver      Driver
+	Stop        Stop
+	Cache       Cache
+	Provisioner Provisioner
+	Analytics   Analytics
+}
+
+func (i *Import) Cmd() *cobra.Command {
+	args := Args{}
+	cmd := &cobra.Command{
+		Use:   "import <path>",
+		Short: "Install and boot an environment packaged with 'cf dev export'",
+		RunE: func(_ *cobra.Command, positional []string) error {
+			if len(positional) != 1 {
+				return errors.New("the path of the package needs to be passed as an argument")
+			}
+
+			args.Path = positional[0]
+			if err := i.Execute(args); err != nil {
+				return e.SafeWrap(err, "cf dev import")
+			}
+			return nil
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.BoolVar(&args.Force, "force", false, "replace the environment kept by 'cf dev stop'")
+	return cmd
+}
+
+func (i *Import) Execute(args Args) error {
+	var (
+		provisioning = make(chan struct{})
+		provisioned  = make(chan struct{})
+	)
+
+	go func() {
+		<-i.Exit
+
+		// The BOSH Director is given time to finish
+		// the step in progress before its VM is stopped
+		select {
+		case <-provisioning:
+			select {
+			case <-provisioned:
+			case <-time.After(provision.CancelGracePeriod):
+			}
+		default:
+		}
+
+		i.Driver.Stop()
+		os.Exit(128)
+	}()
+
+	path, err := filepath.Abs(args.Path)
+	if err != nil {
+		return e.SafeWrap(err, "determining absolute path to the package")
+	}
+
+	manifest, err := i.Workspace.ReadExportManifest(path)
+	if err != nil {
+		return e.SafeWrap(err, fmt.Sprintf("%s is not a CF Dev environment package", path))
+	}
+
+	if err := i.checkCompatibility(manifest); err != nil {
+		return err
+	}
+
+	if err := i.Driver.CheckRequirements(); err != nil {
+		return err
+	}
+
+	if running, err := i.Driver.IsRunning(); err != nil {
+		return e.SafeWrap(err, "is running")
+	} else if running {
+		return errors.New("CF Dev is already running. Please execute 'cf dev stop' before importing an environment")
+	}
+
+	if _, err := i.Workspace.Settings(); err == nil && !args.Force {
+		return errors.New("an environment kept by 'cf dev stop' would be replaced. Please execute 'cf dev stop --destroy' or pass --force to replace it")
+	}
+
+	if err := i.Stop.RunE(nil, nil); err != nil {
+		return e.SafeWrap(err, "stopping cfdev")
+	}
+
+	if err := i.Workspace.CreateDirs(); err != nil {
+		return e.SafeWrap(err, "setting up cfdev home dir")
+	}
+
+	if err := i.Driver.Prestart(); err != nil {
+		return e.SafeWrap(err, "Unable to invoke pre-start")
+	}
+
+	// The deps tarball is not needed, its contents are part of the package
+	dependencies := i.Config.Dependencies
+	dependencies.Remove("cfdev-deps.tgz")
+
+	i.UI.Say("Downloading Resources...")
+	if err := i.Cache.Sync(dependencies); err != nil {
+		return e.SafeWrap(err, "Unable to sync assets")
+	}
+
+	i.UI.Say("Importing the environment...")
+	if err := i.Workspace.Import(path); err != nil {
+		// Never leave a partially extracted environment behind
+		if cleanupErr := i.Workspace.CreateDirs(); cleanupErr != nil {
+			return e.SafeWrap(fmt.Errorf("%s (cleanup: %s)", err, cleanupErr), "failed to import the environment and to remove the partially extracted one")
+		}
+		return e.SafeWrap(err, "failed to import the environment")
+	}
+
+	settings := manifest.Settings
+	err = i.Driver.Start(settings.Cpus, settings.Memory, settings.DiskSize(), filepath.Join(i.Config.BinaryDir, "cfdev-efi-v2.iso"))
+	if err != nil {
+		return err
+	}
+
+	i.UI.Say("Waiting for the VM...")
+	if err := i.Provisioner.Ping(2 * time.Minute); err != nil {
+		return e.SafeWrap(err, "Timed out waiting for the VM")
+	}
+
+	close(provisioning)
+	err = i.provision()
+	close(provisioned)
+
+	if err != nil {
+		select {
+		case <-i.Exit:
+			// The exit handler stops the VM and exits
+			select {}
+		default:
+			return err
+		}
+	}
+
+	i.Analytics.Event(cfanalytics.IMPORT, map[string]interface{}{"artifact": manifest.ArtifactVersion})
+	i.UI.Say("Imported the environment (%s)", manifest.ArtifactVersion)
+	return nil
+}
+
+func (i *Import) provision() error {
+	i.UI.Say("Starting the BOSH Director...")
+	if err := i.Provisioner.DeployBosh(); err != nil {
+		return e.SafeWrap(err, "Failed to start the BOSH Director")
+	}
+
+	if err := i.Provisioner.RecoverDeployments(i.UI); err != nil {
+		return e.SafeWrap(err, "Failed to recover the deployments")
+	}
+
+	return nil
+}
+
+func (i *Import) checkCompatibility(manifest workspace.ExportManifest) error {
+	exported, err := config.NewSemver(manifest.PluginVersion)
+	if err != nil {
+		return e.SafeWrap(err, "invalid plugin version in package")
+	}
+
+	current := i.Config.CliVersion
+	if exported.Major != current.Major || exported.Minor != current.Minor {
+		return fmt.Errorf("the package was exported with version %s of the plugin, which is incompatible with %s", manifest.PluginVersion, current.Original)
+	}
+
+	if manifest.Platform != runtime.GOOS {
+		return fmt.Errorf("the package was exported on %s and cannot be imported on %s", manifest.Platform, runtime.GOOS)
+	}
+
+	if manifest.CompatibilityVersion != compatibilityVersion {
+		return fmt.Errorf("the package contains assets that are not compatible with CF Dev")
+	}
+
+	return nil
+}
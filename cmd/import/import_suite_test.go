@@ -0,0 +1,13 @@
+package import_env_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Import Suite")
+}
@@ -0,0 +1,181 @@
+package import_env_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/import"
+	"code.cloudfoundry.org/cfdev/cmd/import/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/resource"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+	"runtime"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Import", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockWorkspace   *mocks.MockWorkspace
+		mockDriver      *mocks.MockDriver
+		mockStop        *mocks.MockStop
+		mockCache       *mocks.MockCache
+		mockProvisioner *mocks.MockProvisioner
+		mockAnalytics   *mocks.MockAnalytics
+		cmd             *import_env.Import
+		manifest        workspace.ExportManifest
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
+		mockDriver = mocks.NewMockDriver(mockController)
+		mockStop = mocks.NewMockStop(mockController)
+		mockCache = mocks.NewMockCache(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		version, _ := config.NewSemver("0.0.16")
+		cmd = &import_env.Import{
+			UI: mockUI,
+			Config: config.Config{
+				CliVersion: version,
+				BinaryDir:  "/home/.cfdev/bin",
+				Dependencies: resource.Catalog{Items: []resource.Item{
+					{Name: "cfdev-deps.tgz"},
+					{Name: "some-binary"},
+				}},
+			},
+			Workspace:   mockWorkspace,
+			Driver:      mockDriver,
+			Stop:        mockStop,
+			Cache:       mockCache,
+			Provisioner: mockProvisioner,
+			Analytics:   mockAnalytics,
+		}
+
+		manifest = workspace.ExportManifest{
+			PluginVersion:        "0.0.15",
+			ArtifactVersion:      "some-version",
+			CompatibilityVersion: "v5",
+			Platform:             runtime.GOOS,
+			Settings:             workspace.Settings{Cpus: 4, Memory: 8192},
+		}
+
+		mockUI.EXPECT().Say(gomock.Any()).AnyTimes()
+		mockUI.EXPECT().Say(gomock.Any(), gomock.Any()).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("installs and boots the packaged environment", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().ReadExportManifest("/some/env.tgz").Return(manifest, nil),
+			mockDriver.EXPECT().CheckRequirements(),
+			mockDriver.EXPECT().IsRunning().Return(false, nil),
+			mockWorkspace.EXPECT().Settings().Return(workspace.Settings{}, errors.New("no settings")),
+			mockStop.EXPECT().RunE(nil, nil),
+			mockWorkspace.EXPECT().CreateDirs(),
+			mockDriver.EXPECT().Prestart(),
+			mockCache.EXPECT().Sync(resource.Catalog{Items: []resource.Item{{Name: "some-binary"}}}),
+			mockWorkspace.EXPECT().Import("/some/env.tgz"),
+			mockDriver.EXPECT().Start(4, 8192, 120, "/home/.cfdev/bin/cfdev-efi-v2.iso"),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockProvisioner.EXPECT().DeployBosh(),
+			mockProvisioner.EXPECT().RecoverDeployments(mockUI),
+			mockAnalytics.EXPECT().Event(cfanalytics.IMPORT, map[string]interface{}{"artifact": "some-version"}),
+		)
+
+		Expect(cmd.Execute(import_env.Args{Path: "/some/env.tgz"})).To(Succeed())
+	})
+
+	It("refuses a package exported with an incompatible plugin version", func() {
+		manifest.PluginVersion = "0.1.0"
+		mockWorkspace.EXPECT().ReadExportManifest("/some/env.tgz").Return(manifest, nil)
+
+		Expect(cmd.Execute(import_env.Args{Path: "/some/env.tgz"})).To(MatchError(ContainSubstring("incompatible")))
+	})
+
+	It("refuses a package exported on another platform", func() {
+		manifest.Platform = "some-os"
+		mockWorkspace.EXPECT().ReadExportManifest("/some/env.tgz").Return(manifest, nil)
+
+		Expect(cmd.Execute(import_env.Args{Path: "/some/env.tgz"})).To(MatchError(ContainSubstring("exported on some-os")))
+	})
+
+	It("refuses to import while an environment is running", func() {
+		mockWorkspace.EXPECT().ReadExportManifest("/some/env.tgz").Return(manifest, nil)
+		mockDriver.EXPECT().CheckRequirements()
+		mockDriver.EXPECT().IsRunning().Return(true, nil)
+
+		Expect(cmd.Execute(import_env.Args{Path: "/some/env.tgz"})).To(MatchError(ContainSubstring("cf dev stop")))
+	})
+
+	It("does not boot an environment that failed verification", func() {
+		mockWorkspace.EXPECT().ReadExportManifest("/some/env.tgz").Return(manifest, nil)
+		mockDriver.EXPECT().CheckRequirements()
+		mockDriver.EXPECT().IsRunning().Return(false, nil)
+		mockWorkspace.EXPECT().Settings().Return(workspace.Settings{}, errors.New("no settings"))
+		mockStop.EXPECT().RunE(nil, nil)
+		mockWorkspace.EXPECT().CreateDirs().Times(2)
+		mockDriver.EXPECT().Prestart()
+		mockCache.EXPECT().Sync(gomock.Any())
+		mockWorkspace.EXPECT().Import("/some/env.tgz").Return(errors.New("checksum mismatch for state/bosh/state.json"))
+
+		Expect(cmd.Execute(import_env.Args{Path: "/some/env.tgz"})).To(MatchError(ContainSubstring("checksum mismatch")))
+	})
+
+	It("reports when the partially extracted environment cannot be removed", func() {
+		mockWorkspace.EXPECT().ReadExportManifest("/some/env.tgz").Return(manifest, nil)
+		mockDriver.EXPECT().CheckRequirements()
+		mockDriver.EXPECT().IsRunning().Return(false, nil)
+		mockWorkspace.EXPECT().Settings().Return(workspace.Settings{}, errors.New("no settings"))
+		mockStop.EXPECT().RunE(nil, nil)
+		gomock.InOrder(
+			mockWorkspace.EXPECT().CreateDirs(),
+			mockWorkspace.EXPECT().CreateDirs().Return(errors.New("permission denied")),
+		)
+		mockDriver.EXPECT().Prestart()
+		mockCache.EXPECT().Sync(gomock.Any())
+		mockWorkspace.EXPECT().Import("/some/env.tgz").Return(errors.New("checksum mismatch for state/bosh/state.json"))
+
+		err := cmd.Execute(import_env.Args{Path: "/some/env.tgz"})
+		Expect(err).To(MatchError(ContainSubstring("checksum mismatch for state/bosh/state.json (cleanup: permission denied)")))
+	})
+
+	Context("when an environment kept by 'cf dev stop' exists", func() {
+		BeforeEach(func() {
+			mockWorkspace.EXPECT().ReadExportManifest("/some/env.tgz").Return(manifest, nil)
+			mockDriver.EXPECT().CheckRequirements()
+			mockDriver.EXPECT().IsRunning().Return(false, nil)
+			mockWorkspace.EXPECT().Settings().Return(workspace.Settings{Cpus: 4}, nil)
+		})
+
+		It("refuses to replace it", func() {
+			Expect(cmd.Execute(import_env.Args{Path: "/some/env.tgz"})).To(MatchError(ContainSubstring("--force")))
+		})
+
+		It("replaces it with --force", func() {
+			gomock.InOrder(
+				mockStop.EXPECT().RunE(nil, nil),
+				mockWorkspace.EXPECT().CreateDirs(),
+				mockDriver.EXPECT().Prestart(),
+				mockCache.EXPECT().Sync(gomock.Any()),
+				mockWorkspace.EXPECT().Import("/some/env.tgz"),
+				mockDriver.EXPECT().Start(4, 8192, 120, "/home/.cfdev/bin/cfdev-efi-v2.iso"),
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RecoverDeployments(mockUI),
+				mockAnalytics.EXPECT().Event(cfanalytics.IMPORT, map[string]interface{}{"artifact": "some-version"}),
+			)
+
+			Expect(cmd.Execute(import_env.Args{Path: "/some/env.tgz", Force: true})).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/import (interfaces: Provisioner)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	provision "code.cloudfoundry.org/cfdev/provision"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockProvisioner is a mock of Provisioner interface
+type MockProvisioner struct {
+	ctrl     *gomock.Controller
+	recorder *MockProvisionerMockRecorder
+}
+
+// MockProvisionerMockRecorder is the mock recorder for MockProvisioner
+type MockProvisionerMockRecorder struct {
+	mock *MockProvisioner
+}
+
+// NewMockProvisioner creates a new mock instance
+func NewMockProvisioner(ctrl *gomock.Controller) *MockProvisioner {
+	mock := &MockProvisioner{ctrl: ctrl}
+	mock.recorder = &MockProvisionerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockProvisioner) EXPECT() *MockProvisionerMockRecorder {
+	return m.recorder
+}
+
+// DeployBosh mocks base method
+func (m *MockProvisioner) DeployBosh() error {
+	ret := m.ctrl.Call(m, "DeployBosh")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeployBosh indicates an expected call of DeployBosh
+func (mr *MockProvisionerMockRecorder) DeployBosh() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployBosh", reflect.TypeOf((*MockProvisioner)(nil).DeployBosh))
+}
+
+// Ping mocks base method
+func (m *MockProvisioner) Ping(arg0 time.Duration) error {
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping
+func (mr *MockProvisionerMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockProvisioner)(nil).Ping), arg0)
+}
+
+// RecoverDeployments mocks base method
+func (m *MockProvisioner) RecoverDeployments(arg0 provision.UI) error {
+	ret := m.ctrl.Call(m, "RecoverDeployments", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecoverDeployments indicates an expected call of RecoverDeployments
+func (mr *MockProvisionerMockRecorder) RecoverDeployments(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverDeployments", reflect.TypeOf((*MockProvisioner)(nil).RecoverDeployments), arg0)
+}
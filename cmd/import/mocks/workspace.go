@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/import (interfaces: Workspace)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	workspace "code.cloudfoundry.org/cfdev/workspace"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWorkspace is a mock of Workspace interface
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// CreateDirs mocks base method
+func (m *MockWorkspace) CreateDirs() error {
+	ret := m.ctrl.Call(m, "CreateDirs")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDirs indicates an expected call of CreateDirs
+func (mr *MockWorkspaceMockRecorder) CreateDirs() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDirs", reflect.TypeOf((*MockWorkspace)(nil).CreateDirs))
+}
+
+// Import mocks base method
+func (m *MockWorkspace) Import(arg0 string) error {
+	ret := m.ctrl.Call(m, "Import", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Import indicates an expected call of Import
+func (mr *MockWorkspaceMockRecorder) Import(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockWorkspace)(nil).Import), arg0)
+}
+
+// ReadExportManifest mocks base method
+func (m *MockWorkspace) ReadExportManifest(arg0 string) (workspace.ExportManifest, error) {
+	ret := m.ctrl.Call(m, "ReadExportManifest", arg0)
+	ret0, _ := ret[0].(workspace.ExportManifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadExportManifest indicates an expected call of ReadExportManifest
+func (mr *MockWorkspaceMockRecorder) ReadExportManifest(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadExportManifest", reflect.TypeOf((*MockWorkspace)(nil).ReadExportManifest), arg0)
+}
+
+// Settings mocks base method
+func (m *MockWorkspace) Settings() (workspace.Settings, error) {
+	ret := m.ctrl.Call(m, "Settings")
+	ret0, _ := ret[0].(workspace.Settings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Settings indicates an expected call of Settings
+func (mr *MockWorkspaceMockRecorder) Settings() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settings", reflect.TypeOf((*MockWorkspace)(nil).Settings))
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	provision "code.cloudfoundry.org/cfdev/provision"
 	workspace "code.cloudfoundry.org/cfdev/workspace"
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 	time "time"
@@ -48,15 +49,28 @@ func (mr *MockProvisionerMockRecorder) DeployBosh() *gomock.Call {
 }
 
 // DeployServices mocks base method
-func (m *MockProvisioner) DeployServices(arg0 provision.UI, arg1 []workspace.Service, arg2 []string) error {
-	ret := m.ctrl.Call(m, "DeployServices", arg0, arg1, arg2)
+func (m *MockProvisioner) DeployServices(arg0 context.Context, arg1 provision.UI, arg2 []workspace.Service, arg3 []string) error {
+	ret := m.ctrl.Call(m, "DeployServices", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeployServices indicates an expected call of DeployServices
-func (mr *MockProvisionerMockRecorder) DeployServices(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployServices", reflect.TypeOf((*MockProvisioner)(nil).DeployServices), arg0, arg1, arg2)
+func (mr *MockProvisionerMockRecorder) DeployServices(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployServices", reflect.TypeOf((*MockProvisioner)(nil).DeployServices), arg0, arg1, arg2, arg3)
+}
+
+// DeployedServices mocks base method
+func (m *MockProvisioner) DeployedServices(arg0 []workspace.Service) ([]workspace.Service, error) {
+	ret := m.ctrl.Call(m, "DeployedServices", arg0)
+	ret0, _ := ret[0].([]workspace.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeployedServices indicates an expected call of DeployedServices
+func (mr *MockProvisionerMockRecorder) DeployedServices(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployedServices", reflect.TypeOf((*MockProvisioner)(nil).DeployedServices), arg0)
 }
 
 // Ping mocks base method
@@ -71,6 +85,30 @@ func (mr *MockProvisionerMockRecorder) Ping(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockProvisioner)(nil).Ping), arg0)
 }
 
+// RecoverDeployments mocks base method
+func (m *MockProvisioner) RecoverDeployments(arg0 provision.UI) error {
+	ret := m.ctrl.Call(m, "RecoverDeployments", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecoverDeployments indicates an expected call of RecoverDeployments
+func (mr *MockProvisionerMockRecorder) RecoverDeployments(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverDeployments", reflect.TypeOf((*MockProvisioner)(nil).RecoverDeployments), arg0)
+}
+
+// RunHooks mocks base method
+func (m *MockProvisioner) RunHooks(arg0 provision.UI, arg1 string) error {
+	ret := m.ctrl.Call(m, "RunHooks", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunHooks indicates an expected call of RunHooks
+func (mr *MockProvisionerMockRecorder) RunHooks(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunHooks", reflect.TypeOf((*MockProvisioner)(nil).RunHooks), arg0, arg1)
+}
+
 // WhiteListServices mocks base method
 func (m *MockProvisioner) WhiteListServices(arg0 string, arg1 []workspace.Service) ([]workspace.Service, error) {
 	ret := m.ctrl.Call(m, "WhiteListServices", arg0, arg1)
@@ -0,0 +1,46 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/provision (interfaces: SmokeTest)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	smoketest "code.cloudfoundry.org/cfdev/cmd/smoketest"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockSmokeTest is a mock of SmokeTest interface
+type MockSmokeTest struct {
+	ctrl     *gomock.Controller
+	recorder *MockSmokeTestMockRecorder
+}
+
+// MockSmokeTestMockRecorder is the mock recorder for MockSmokeTest
+type MockSmokeTestMockRecorder struct {
+	mock *MockSmokeTest
+}
+
+// NewMockSmokeTest creates a new mock instance
+func NewMockSmokeTest(ctrl *gomock.Controller) *MockSmokeTest {
+	mock := &MockSmokeTest{ctrl: ctrl}
+	mock.recorder = &MockSmokeTestMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSmokeTest) EXPECT() *MockSmokeTestMockRecorder {
+	return m.recorder
+}
+
+// Execute mocks base method
+func (m *MockSmokeTest) Execute(arg0 smoketest.Args) error {
+	ret := m.ctrl.Call(m, "Execute", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Execute indicates an expected call of Execute
+func (mr *MockSmokeTestMockRecorder) Execute(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockSmokeTest)(nil).Execute), arg0)
+}
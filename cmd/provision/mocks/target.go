@@ -0,0 +1,46 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/provision (interfaces: Target)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	target "code.cloudfoundry.org/cfdev/cmd/target"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockTarget is a mock of Target interface
+type MockTarget struct {
+	ctrl     *gomock.Controller
+	recorder *MockTargetMockRecorder
+}
+
+// MockTargetMockRecorder is the mock recorder for MockTarget
+type MockTargetMockRecorder struct {
+	mock *MockTarget
+}
+
+// NewMockTarget creates a new mock instance
+func NewMockTarget(ctrl *gomock.Controller) *MockTarget {
+	mock := &MockTarget{ctrl: ctrl}
+	mock.recorder = &MockTargetMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTarget) EXPECT() *MockTargetMockRecorder {
+	return m.recorder
+}
+
+// Execute mocks base method
+func (m *MockTarget) Execute(arg0 target.Args) error {
+	ret := m.ctrl.Call(m, "Execute", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Execute indicates an expected call of Execute
+func (mr *MockTargetMockRecorder) Execute(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockTarget)(nil).Execute), arg0)
+}
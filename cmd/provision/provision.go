@@ -1,11 +1,14 @@
 package provision
 
 import (
+	"code.cloudfoundry.org/cfdev/cmd/smoketest"
 	"code.cloudfoundry.org/cfdev/cmd/start"
+	"code.cloudfoundry.org/cfdev/cmd/target"
 	"code.cloudfoundry.org/cfdev/config"
 	e "code.cloudfoundry.org/cfdev/errors"
 	"code.cloudfoundry.org/cfdev/provision"
 	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
 	"io"
@@ -32,7 +35,20 @@ type Provisioner interface {
 	Ping(duration time.Duration) error
 	DeployBosh() error
 	WhiteListServices(string, []workspace.Service) ([]workspace.Service, error)
-	DeployServices(provision.UI, []workspace.Service, []string) error
+	DeployServices(context.Context, provision.UI, []workspace.Service, []string) error
+	RecoverDeployments(ui provision.UI) error
+	DeployedServices(services []workspace.Service) ([]workspace.Service, error)
+	RunHooks(ui provision.UI, point string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/target.go code.cloudfoundry.org/cfdev/cmd/provision Target
+type Target interface {
+	Execute(args target.Args) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/smoketest.go code.cloudfoundry.org/cfdev/cmd/provision SmokeTest
+type SmokeTest interface {
+	Execute(args smoketest.Args) error
 }
 
 const compatibilityVersion = "v5"
@@ -42,6 +58,8 @@ type Provision struct {
 	UI             UI
 	Provisioner    Provisioner
 	MetaDataReader MetaDataReader
+	Target         Target
+	SmokeTest      SmokeTest
 	Config         config.Config
 }
 
@@ -57,6 +75,7 @@ func (c *Provision) Cmd() *cobra.Command {
 func (c *Provision) RunE(cmd *cobra.Command, args []string) error {
 	go func() {
 		<-c.Exit
+		time.Sleep(provision.CancelGracePeriod)
 		os.Exit(128)
 	}()
 
@@ -78,10 +97,21 @@ func (c *Provision) Execute(args start.Args) error {
 		return e.SafeWrap(err, "Unable to parse docker registries")
 	}
 
-	return c.provision(metadataConfig, registries, args.DeploySingleService)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.Exit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return c.provision(ctx, metadataConfig, registries, args.DeploySingleService, args.Target, args.SmokeTest)
 }
 
-func (c *Provision) provision(metadataConfig workspace.Metadata, registries []string, deploySingleService string) error {
+func (c *Provision) provision(ctx context.Context, metadataConfig workspace.Metadata, registries []string, deploySingleService string, targetCF bool, smokeTest bool) error {
 	err := c.Provisioner.Ping(10 * time.Second)
 	if err != nil {
 		return e.SafeWrap(err, "VM is not running. Please execute 'cf dev start'")
@@ -92,15 +122,36 @@ func (c *Provision) provision(metadataConfig workspace.Metadata, registries []st
 		return e.SafeWrap(err, "Failed to deploy the BOSH Director")
 	}
 
+	if err := c.Provisioner.RunHooks(c.UI, workspace.HookPostDirector); err != nil {
+		return err
+	}
+
 	services, err := c.Provisioner.WhiteListServices(deploySingleService, metadataConfig.Services)
 	if err != nil {
 		return e.SafeWrap(err, "Failed to whitelist services")
 	}
 
-	if err := c.Provisioner.DeployServices(c.UI, services, registries); err != nil {
+	// An environment kept by 'cf dev stop' still has its deployments,
+	// they only need their VMs recreated
+	if err := c.Provisioner.RecoverDeployments(c.UI); err != nil {
+		return e.SafeWrap(err, "Failed to recover the deployments")
+	}
+
+	deployed, err := c.Provisioner.DeployedServices(services)
+	if err != nil {
+		return e.SafeWrap(err, "Failed to check the deployed services")
+	}
+
+	services = c.skipDeployed(services, deployed)
+
+	if err := c.Provisioner.DeployServices(ctx, c.UI, services, registries); err != nil {
 		return e.SafeWrap(err, "Failed to deploy services")
 	}
 
+	if err := c.Provisioner.RunHooks(c.UI, workspace.HookPostProvision); err != nil {
+		return err
+	}
+
 	if metadataConfig.Message != "" {
 		t := template.Must(template.New("message").Parse(metadataConfig.Message))
 		err := t.Execute(c.UI.Writer(), map[string]string{"SYSTEM_DOMAIN": c.Config.CFDomain})
@@ -109,9 +160,44 @@ func (c *Provision) provision(metadataConfig workspace.Metadata, registries []st
 		}
 	}
 
+	if targetCF {
+		c.UI.Say("Logging in to CF Dev...")
+		if err := c.Target.Execute(target.Args{}); err != nil {
+			return e.SafeWrap(err, "Failed to log in to CF Dev")
+		}
+	}
+
+	if smokeTest {
+		if err := c.SmokeTest.Execute(smoketest.Args{}); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// skipDeployed returns the services that are not deployed and healthy yet.
+func (c *Provision) skipDeployed(services []workspace.Service, deployed []workspace.Service) []workspace.Service {
+	var pending []workspace.Service
+
+	for _, service := range services {
+		var isDeployed bool
+		for _, d := range deployed {
+			if d.Name == service.Name {
+				isDeployed = true
+			}
+		}
+
+		if isDeployed {
+			c.UI.Say("Skipping %s, it is already deployed and healthy.", service.Name)
+		} else {
+			pending = append(pending, service)
+		}
+	}
+
+	return pending
+}
+
 func (c *Provision) parseDockerRegistriesFlag(flag string) ([]string, error) {
 	if flag == "" {
 		return nil, nil
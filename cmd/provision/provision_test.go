@@ -3,7 +3,9 @@ package provision_test
 import (
 	"code.cloudfoundry.org/cfdev/cmd/provision"
 	"code.cloudfoundry.org/cfdev/cmd/provision/mocks"
+	"code.cloudfoundry.org/cfdev/cmd/smoketest"
 	"code.cloudfoundry.org/cfdev/cmd/start"
+	"code.cloudfoundry.org/cfdev/cmd/target"
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/workspace"
 	"code.cloudfoundry.org/cli/cf/errors"
@@ -18,6 +20,8 @@ var _ = Describe("Provision", func() {
 		mockUI             *mocks.MockUI
 		mockMetadataReader *mocks.MockMetaDataReader
 		mockProvisioner    *mocks.MockProvisioner
+		mockTarget         *mocks.MockTarget
+		mockSmokeTest      *mocks.MockSmokeTest
 		cmd                *provision.Provision
 	)
 
@@ -26,6 +30,8 @@ var _ = Describe("Provision", func() {
 		mockUI = mocks.NewMockUI(mockController)
 		mockProvisioner = mocks.NewMockProvisioner(mockController)
 		mockMetadataReader = mocks.NewMockMetaDataReader(mockController)
+		mockTarget = mocks.NewMockTarget(mockController)
+		mockSmokeTest = mocks.NewMockSmokeTest(mockController)
 
 		localExitChan := make(chan struct{}, 3)
 
@@ -34,6 +40,8 @@ var _ = Describe("Provision", func() {
 			UI:             mockUI,
 			Provisioner:    mockProvisioner,
 			MetaDataReader: mockMetadataReader,
+			Target:         mockTarget,
+			SmokeTest:      mockSmokeTest,
 			Config: config.Config{
 				StateDir: "some-state-dir",
 			},
@@ -49,8 +57,12 @@ var _ = Describe("Provision", func() {
 				mockProvisioner.EXPECT().Ping(gomock.Any()),
 				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
 				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostDirector),
 				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]workspace.Service{}, nil),
-				mockProvisioner.EXPECT().DeployServices(mockUI, []workspace.Service{}, nil),
+				mockProvisioner.EXPECT().RecoverDeployments(mockUI),
+				mockProvisioner.EXPECT().DeployedServices([]workspace.Service{}),
+				mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, nil, nil),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostProvision),
 			)
 
 			err := cmd.Execute(start.Args{})
@@ -58,6 +70,34 @@ var _ = Describe("Provision", func() {
 		})
 	})
 
+	Describe("when the environment was kept by cf dev stop", func() {
+		It("recovers its deployments and only deploys the services that are not healthy", func() {
+			var (
+				cf    = workspace.Service{Name: "cf", Deployment: "cf"}
+				mysql = workspace.Service{Name: "mysql", Deployment: "cf-mysql"}
+			)
+
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Metadata().Return(workspace.Metadata{
+					Version:  "v5",
+					Services: []workspace.Service{cf, mysql},
+				}, nil),
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostDirector),
+				mockProvisioner.EXPECT().WhiteListServices("", []workspace.Service{cf, mysql}).Return([]workspace.Service{cf, mysql}, nil),
+				mockProvisioner.EXPECT().RecoverDeployments(mockUI),
+				mockProvisioner.EXPECT().DeployedServices([]workspace.Service{cf, mysql}).Return([]workspace.Service{cf}, nil),
+				mockUI.EXPECT().Say("Skipping %s, it is already deployed and healthy.", "cf"),
+				mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, []workspace.Service{mysql}, nil),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostProvision),
+			)
+
+			Expect(cmd.Execute(start.Args{})).To(Succeed())
+		})
+	})
+
 	Describe("when version is not compatible", func() {
 		It("return an error", func() {
 			gomock.InOrder(
@@ -80,8 +120,12 @@ var _ = Describe("Provision", func() {
 				mockProvisioner.EXPECT().Ping(gomock.Any()),
 				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
 				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostDirector),
 				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]workspace.Service{}, nil),
-				mockProvisioner.EXPECT().DeployServices(mockUI, []workspace.Service{}, []string{"domain1.com", "domain2.com"}),
+				mockProvisioner.EXPECT().RecoverDeployments(mockUI),
+				mockProvisioner.EXPECT().DeployedServices([]workspace.Service{}),
+				mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, nil, []string{"domain1.com", "domain2.com"}),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostProvision),
 			)
 
 			err := cmd.Execute(start.Args{
@@ -91,6 +135,74 @@ var _ = Describe("Provision", func() {
 		})
 	})
 
+	Describe("when the target flag is present", func() {
+		It("logs the cf CLI in after provisioning", func() {
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Metadata().Return(workspace.Metadata{
+					Version: "v5",
+				}, nil),
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostDirector),
+				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]workspace.Service{}, nil),
+				mockProvisioner.EXPECT().RecoverDeployments(mockUI),
+				mockProvisioner.EXPECT().DeployedServices([]workspace.Service{}),
+				mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, nil, nil),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostProvision),
+				mockUI.EXPECT().Say("Logging in to CF Dev..."),
+				mockTarget.EXPECT().Execute(target.Args{}),
+			)
+
+			err := cmd.Execute(start.Args{
+				Target: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("when the smoke-test flag is present", func() {
+		It("runs the smoke tests after provisioning", func() {
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Metadata().Return(workspace.Metadata{
+					Version: "v5",
+				}, nil),
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostDirector),
+				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]workspace.Service{}, nil),
+				mockProvisioner.EXPECT().RecoverDeployments(mockUI),
+				mockProvisioner.EXPECT().DeployedServices([]workspace.Service{}),
+				mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, nil, nil),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostProvision),
+				mockSmokeTest.EXPECT().Execute(smoketest.Args{}).Return(errors.New("1 smoke test(s) failed")),
+			)
+
+			err := cmd.Execute(start.Args{
+				SmokeTest: true,
+			})
+			Expect(err).To(MatchError("1 smoke test(s) failed"))
+		})
+	})
+
+	Describe("when a post-director hook fails", func() {
+		It("does not deploy the services", func() {
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Metadata().Return(workspace.Metadata{
+					Version: "v5",
+				}, nil),
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPostDirector).Return(errors.New("the post-director hook seed failed")),
+			)
+
+			err := cmd.Execute(start.Args{})
+			Expect(err).To(MatchError("the post-director hook seed failed"))
+		})
+	})
+
 	Describe("when the vm is not running", func() {
 		It("return an error", func() {
 			gomock.InOrder(
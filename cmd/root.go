@@ -5,23 +5,35 @@ import (
 	"code.cloudfoundry.org/cfdev/workspace"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
 	"code.cloudfoundry.org/cfdev/cfanalytics"
 	b2 "code.cloudfoundry.org/cfdev/cmd/bosh"
 	b3 "code.cloudfoundry.org/cfdev/cmd/catalog"
+	b18 "code.cloudfoundry.org/cfdev/cmd/console"
+	b10 "code.cloudfoundry.org/cfdev/cmd/credhub"
 	b9 "code.cloudfoundry.org/cfdev/cmd/deploy-service"
+	b19 "code.cloudfoundry.org/cfdev/cmd/disk"
 	b4 "code.cloudfoundry.org/cfdev/cmd/download"
+	b13 "code.cloudfoundry.org/cfdev/cmd/export"
+	b14 "code.cloudfoundry.org/cfdev/cmd/import"
 	b8 "code.cloudfoundry.org/cfdev/cmd/provision"
+	b16 "code.cloudfoundry.org/cfdev/cmd/services"
+	b15 "code.cloudfoundry.org/cfdev/cmd/smoketest"
 	b5 "code.cloudfoundry.org/cfdev/cmd/start"
 	b6 "code.cloudfoundry.org/cfdev/cmd/stop"
+	b11 "code.cloudfoundry.org/cfdev/cmd/target"
 	b7 "code.cloudfoundry.org/cfdev/cmd/telemetry"
+	b17 "code.cloudfoundry.org/cfdev/cmd/undeploy-service"
+	b12 "code.cloudfoundry.org/cfdev/cmd/upgrade"
 	b1 "code.cloudfoundry.org/cfdev/cmd/version"
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/provision"
 	"code.cloudfoundry.org/cfdev/resource"
 	"code.cloudfoundry.org/cfdev/resource/progress"
+	"code.cloudfoundry.org/cli/plugin"
 	"github.com/spf13/cobra"
 )
 
@@ -46,12 +58,18 @@ type Toggle interface {
 	SetProp(k, v string) error
 }
 
-func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient AnalyticsClient, analyticsToggle Toggle) *cobra.Command {
+// CliConnection is only handed to the plugin once a command is run,
+// which is after the command tree has been built.
+type CliConnection struct {
+	plugin.CliConnection
+}
+
+func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient AnalyticsClient, analyticsToggle Toggle, cli *CliConnection) *cobra.Command {
 	var (
 		writer      = ui.Writer()
 		driver      = newDriver(ui, config)
 		workspace   = workspace.New(config)
-		provisioner = provision.NewController(config)
+		provisioner = provision.NewController(config, driver)
 		analyticsD  = &cfanalytics.AnalyticsD{
 			Config:       config,
 			DaemonRunner: newDaemonRunner(config),
@@ -87,6 +105,14 @@ func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient An
 			Workspace: workspace,
 		}
 
+		credhub = &b10.Credhub{
+			Exit:      exit,
+			UI:        ui,
+			Config:    config,
+			Analytics: analyticsClient,
+			Workspace: workspace,
+		}
+
 		catalog = &b3.Catalog{
 			UI:     ui,
 			Config: config,
@@ -107,18 +133,42 @@ func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient An
 			AnalyticsD:      analyticsD,
 		}
 
+		target = &b11.Target{
+			Exit:        exit,
+			UI:          ui,
+			CLI:         newTargetCLI(config, cli),
+			Workspace:   workspace,
+			Provisioner: provisioner,
+			Analytics:   analyticsClient,
+			Config:      config,
+		}
+
+		smokeTest = &b15.SmokeTest{
+			Exit:        exit,
+			UI:          ui,
+			Config:      config,
+			Workspace:   workspace,
+			Provisioner: provisioner,
+			Analytics:   analyticsClient,
+		}
+
 		provision = &b8.Provision{
 			Exit:           exit,
 			UI:             ui,
 			Provisioner:    provisioner,
 			MetaDataReader: workspace,
+			Target:         target,
+			SmokeTest:      smokeTest,
 			Config:         config,
 		}
 
 		stop = &b6.Stop{
-			Analytics:  analyticsClient,
-			AnalyticsD: analyticsD,
-			Driver:     driver,
+			UI:          ui,
+			Analytics:   analyticsClient,
+			AnalyticsD:  analyticsD,
+			Driver:      driver,
+			Provisioner: provisioner,
+			Workspace:   workspace,
 		}
 
 		start = &b5.Start{
@@ -138,13 +188,82 @@ func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient An
 		}
 
 		deployService = &b9.DeployService{
+			Exit:           exit,
 			UI:             ui,
 			Provisioner:    provisioner,
 			MetaDataReader: workspace,
+			Workspace:      workspace,
 			Analytics:      analyticsClient,
 			Config:         config,
 		}
 
+		services = &b16.Services{
+			Exit:        exit,
+			UI:          ui,
+			Config:      config,
+			Workspace:   workspace,
+			Provisioner: provisioner,
+			Analytics:   analyticsClient,
+		}
+
+		undeployService = &b17.UndeployService{
+			Exit:        exit,
+			UI:          ui,
+			Config:      config,
+			Workspace:   workspace,
+			Provisioner: provisioner,
+			Analytics:   analyticsClient,
+		}
+
+		upgrade = &b12.Upgrade{
+			Exit:        exit,
+			UI:          ui,
+			Config:      config,
+			Workspace:   workspace,
+			Provisioner: provisioner,
+			Analytics:   analyticsClient,
+		}
+
+		export = &b13.Export{
+			Exit:      exit,
+			UI:        ui,
+			Config:    config,
+			Workspace: workspace,
+			Driver:    driver,
+			Analytics: analyticsClient,
+		}
+
+		importCmd = &b14.Import{
+			Exit:        exit,
+			UI:          ui,
+			Config:      config,
+			Workspace:   workspace,
+			Driver:      driver,
+			Stop:        stop,
+			Cache:       cache,
+			Provisioner: provisioner,
+			Analytics:   analyticsClient,
+		}
+
+		console = &b18.Console{
+			Exit:      exit,
+			UI:        ui,
+			Config:    config,
+			Analytics: analyticsClient,
+			Stdin:     os.Stdin,
+			Stdout:    os.Stdout,
+		}
+
+		disk = &b19.Disk{
+			Exit:        exit,
+			UI:          ui,
+			Config:      config,
+			Driver:      driver,
+			Provisioner: provisioner,
+			Workspace:   workspace,
+			Analytics:   analyticsClient,
+		}
+
 		helpCmd = &cobra.Command{
 			Use:   "help [command]",
 			Short: "Help about any command",
@@ -165,6 +284,7 @@ func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient An
 	root.AddCommand(dev)
 	dev.AddCommand(version.Cmd())
 	dev.AddCommand(bosh.Cmd())
+	dev.AddCommand(credhub.Cmd())
 	dev.AddCommand(catalog.Cmd())
 	dev.AddCommand(download.Cmd())
 	dev.AddCommand(start.Cmd())
@@ -172,6 +292,25 @@ func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient An
 	dev.AddCommand(telemetryCmd.Cmd())
 	dev.AddCommand(provision.Cmd())
 	dev.AddCommand(deployService.Cmd())
+	dev.AddCommand(services.Cmd())
+	dev.AddCommand(undeployService.Cmd())
+	dev.AddCommand(target.Cmd())
+	dev.AddCommand(upgrade.Cmd())
+	dev.AddCommand(export.Cmd())
+	dev.AddCommand(importCmd.Cmd())
+	dev.AddCommand(smokeTest.Cmd())
+	dev.AddCommand(console.Cmd())
+	dev.AddCommand(disk.Cmd())
 	dev.AddCommand(helpCmd)
 	return root
 }
+
+// newTargetCLI runs the cf CLI through the proxy to CF Dev when there is
+// one, as the commands of the plugin connection cannot go through it.
+func newTargetCLI(config config.Config, cli *CliConnection) b11.CLI {
+	if proxy := config.CFProxy(); proxy != "" {
+		return &b11.ProxiedCLI{Proxy: proxy}
+	}
+
+	return cli
+}
@@ -8,11 +8,19 @@ import (
 )
 
 func newDaemonRunner(config config.Config) driver.DaemonRunner {
+	if config.Rootless {
+		return daemon.NewProcessRunner(config)
+	}
+
 	return daemon.NewServiceWrapper(config)
 }
 
 func newDriver(ui UI, config config.Config) driver.Driver {
 	daemonRunner := newDaemonRunner(config)
 
+	if config.Rootless {
+		return kvm.NewRootless(config, daemonRunner, ui)
+	}
+
 	return kvm.New(config, daemonRunner, ui)
-}
\ No newline at end of file
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/services (interfaces: Provisioner)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockProvisioner is a mock of Provisioner interface
+type MockProvisioner struct {
+	ctrl     *gomock.Controller
+	recorder *MockProvisionerMockRecorder
+}
+
+// MockProvisionerMockRecorder is the mock recorder for MockProvisioner
+type MockProvisionerMockRecorder struct {
+	mock *MockProvisioner
+}
+
+// NewMockProvisioner creates a new mock instance
+func NewMockProvisioner(ctrl *gomock.Controller) *MockProvisioner {
+	mock := &MockProvisioner{ctrl: ctrl}
+	mock.recorder = &MockProvisionerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockProvisioner) EXPECT() *MockProvisionerMockRecorder {
+	return m.recorder
+}
+
+// DeploymentMemory mocks base method
+func (m *MockProvisioner) DeploymentMemory(arg0 string) (uint64, error) {
+	ret := m.ctrl.Call(m, "DeploymentMemory", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeploymentMemory indicates an expected call of DeploymentMemory
+func (mr *MockProvisionerMockRecorder) DeploymentMemory(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeploymentMemory", reflect.TypeOf((*MockProvisioner)(nil).DeploymentMemory), arg0)
+}
+
+// Deployments mocks base method
+func (m *MockProvisioner) Deployments() ([]string, error) {
+	ret := m.ctrl.Call(m, "Deployments")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deployments indicates an expected call of Deployments
+func (mr *MockProvisionerMockRecorder) Deployments() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deployments", reflect.TypeOf((*MockProvisioner)(nil).Deployments))
+}
+
+// Ping mocks base method
+func (m *MockProvisioner) Ping(arg0 time.Duration) error {
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping
+func (mr *MockProvisionerMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockProvisioner)(nil).Ping), arg0)
+}
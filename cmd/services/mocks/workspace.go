@@ -0,0 +1,73 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/services (interfaces: Workspace)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	workspace "code.cloudfoundry.org/cfdev/workspace"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWorkspace is a mock of Workspace interface
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// CustomDeployments mocks base method
+func (m *MockWorkspace) CustomDeployments() ([]workspace.CustomDeployment, error) {
+	ret := m.ctrl.Call(m, "CustomDeployments")
+	ret0, _ := ret[0].([]workspace.CustomDeployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CustomDeployments indicates an expected call of CustomDeployments
+func (mr *MockWorkspaceMockRecorder) CustomDeployments() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomDeployments", reflect.TypeOf((*MockWorkspace)(nil).CustomDeployments))
+}
+
+// Metadata mocks base method
+func (m *MockWorkspace) Metadata() (workspace.Metadata, error) {
+	ret := m.ctrl.Call(m, "Metadata")
+	ret0, _ := ret[0].(workspace.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Metadata indicates an expected call of Metadata
+func (mr *MockWorkspaceMockRecorder) Metadata() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metadata", reflect.TypeOf((*MockWorkspace)(nil).Metadata))
+}
+
+// ServiceStates mocks base method
+func (m *MockWorkspace) ServiceStates() ([]workspace.ServiceState, error) {
+	ret := m.ctrl.Call(m, "ServiceStates")
+	ret0, _ := ret[0].([]workspace.ServiceState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ServiceStates indicates an expected call of ServiceStates
+func (mr *MockWorkspaceMockRecorder) ServiceStates() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServiceStates", reflect.TypeOf((*MockWorkspace)(nil).ServiceStates))
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"code.cloudfoundry.org/bytefmt"
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/services UI
+type UI interface {
+	Say(message string, args ...interface{})
+	Writer() io.Writer
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/services Workspace
+type Workspace interface {
+	Metadata() (workspace.Metadata, error)
+	ServiceStates() ([]workspace.ServiceState, error)
+	CustomDeployments() ([]workspace.CustomDeployment, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/services Provisioner
+type Provisioner interface {
+	Ping(duration time.Duration) error
+	Deployments() ([]string, error)
+	DeploymentMemory(deployment string) (uint64, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/services Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+type Services struct {
+	Exit        chan struct{}
+	UI          UI
+	Config      config.Config
+	Workspace   Workspace
+	Provisioner Provisioner
+	Analytics   Analytics
+}
+
+func (s *Services) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "services",
+		Short: "List the services of CF Dev and their state",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			go func() {
+				<-s.Exit
+				os.Exit(128)
+			}()
+
+			return s.Execute()
+		},
+	}
+}
+
+func (s *Services) Execute() error {
+	metadata, err := s.Workspace.Metadata()
+	if err != nil {
+		return e.SafeWrap(err, "something went wrong while reading the assets. Please execute 'cf dev start'")
+	}
+
+	if err := s.Provisioner.Ping(10 * time.Second); err != nil {
+		return e.SafeWrap(err, "cf dev is not running. Please execute 'cf dev start'")
+	}
+
+	deployments, err := s.Provisioner.Deployments()
+	if err != nil {
+		return e.SafeWrap(err, "failed to list the deployments")
+	}
+
+	states, err := s.Workspace.ServiceStates()
+	if err != nil {
+		return e.SafeWrap(err, "failed to read the state of the services")
+	}
+
+	customDeployments, err := s.Workspace.CustomDeployments()
+	if err != nil {
+		return e.SafeWrap(err, "failed to read the custom deployments")
+	}
+
+	w := tabwriter.NewWriter(s.UI.Writer(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "name\tflag\tstate\tdeployment\tmemory")
+
+	for _, service := range metadata.Services {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			service.Name,
+			service.Flagname,
+			serviceState(service, deployments, states),
+			service.Deployment,
+			s.memory(service.Deployment, deployments))
+	}
+
+	for _, deployment := range customDeployments {
+		state := workspace.ServiceNotDeployed
+		if contains(deployments, deployment.Name) {
+			state = workspace.ServiceDeployed
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", deployment.Name, "(custom)", state, deployment.Name, s.memory(deployment.Name, deployments))
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	s.Analytics.Event(cfanalytics.SERVICES)
+	return nil
+}
+
+// serviceState reports the deployment of the service as deployed when
+// the BOSH Director knows it, unless its last deployment did not succeed.
+func serviceState(service workspace.Service, deployments []string, states []workspace.ServiceState) string {
+	for _, state := range states {
+		if state.Name == service.Name && (state.State == workspace.ServiceFailed || state.State == workspace.ServiceIncomplete) {
+			return state.State
+		}
+	}
+
+	if contains(deployments, service.Deployment) {
+		return workspace.ServiceDeployed
+	}
+
+	return workspace.ServiceNotDeployed
+}
+
+// memory reports the memory used by the VMs of the deployment
+// when it is deployed and the BOSH Director knows their vitals.
+func (s *Services) memory(deployment string, deployments []string) string {
+	if !contains(deployments, deployment) {
+		return "-"
+	}
+
+	used, err := s.Provisioner.DeploymentMemory(deployment)
+	if err != nil || used == 0 {
+		return "-"
+	}
+
+	return bytefmt.ByteSize(used)
+}
+
+func contains(deployments []string, deployment string) bool {
+	for _, d := range deployments {
+		if d == deployment {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"bytes"
+	"code.cloudfoundry.org/bytefmt"
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/services"
+	"code.cloudfoundry.org/cfdev/cmd/services/mocks"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Services", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockWorkspace   *mocks.MockWorkspace
+		mockProvisioner *mocks.MockProvisioner
+		mockAnalytics   *mocks.MockAnalytics
+		output          *bytes.Buffer
+		cmd             *services.Services
+
+		metadata = workspace.Metadata{
+			Services: []workspace.Service{
+				{Name: "Cloud Foundry", Flagname: "always-include", Deployment: "cf"},
+				{Name: "Mysql", Flagname: "mysql", Deployment: "cf-mysql"},
+				{Name: "Redis", Flagname: "redis", Deployment: "cf-redis"},
+				{Name: "RabbitMQ", Flagname: "rabbitmq", Deployment: "cf-rabbitmq"},
+			},
+		}
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+		output = &bytes.Buffer{}
+
+		mockUI.EXPECT().Writer().Return(output).AnyTimes()
+
+		cmd = &services.Services{
+			UI:          mockUI,
+			Workspace:   mockWorkspace,
+			Provisioner: mockProvisioner,
+			Analytics:   mockAnalytics,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("lists the services with their state, deployment and memory", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(metadata, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockProvisioner.EXPECT().Deployments().Return([]string{"cf", "cf-mysql", "cf-rabbitmq", "my-kafka"}, nil),
+			mockWorkspace.EXPECT().ServiceStates().Return([]workspace.ServiceState{
+				{Name: "Mysql", State: workspace.ServiceDeployed},
+				{Name: "Redis", State: workspace.ServiceFailed},
+				{Name: "RabbitMQ", State: workspace.ServiceIncomplete},
+			}, nil),
+			mockWorkspace.EXPECT().CustomDeployments().Return([]workspace.CustomDeployment{{Name: "my-kafka"}, {Name: "my-zookeeper"}}, nil),
+			mockProvisioner.EXPECT().DeploymentMemory("cf").Return(uint64(6*bytefmt.GIGABYTE), nil),
+			mockProvisioner.EXPECT().DeploymentMemory("cf-mysql").Return(uint64(1536*bytefmt.MEGABYTE), nil),
+			mockProvisioner.EXPECT().DeploymentMemory("cf-rabbitmq").Return(uint64(0), nil),
+			mockProvisioner.EXPECT().DeploymentMemory("my-kafka").Return(uint64(0), errors.New("some-error")),
+			mockAnalytics.EXPECT().Event(cfanalytics.SERVICES),
+		)
+
+		Expect(cmd.Execute()).To(Succeed())
+		Expect(output.String()).To(Equal(
+			"name            flag             state          deployment     memory\n" +
+				"Cloud Foundry   always-include   deployed       cf             6G\n" +
+				"Mysql           mysql            deployed       cf-mysql       1.5G\n" +
+				"Redis           redis            failed         cf-redis       -\n" +
+				"RabbitMQ        rabbitmq         incomplete     cf-rabbitmq    -\n" +
+				"my-kafka        (custom)         deployed       my-kafka       -\n" +
+				"my-zookeeper    (custom)         not deployed   my-zookeeper   -\n"))
+	})
+
+	It("fails when CF Dev is not running", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(metadata, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()).Return(errors.New("not running")),
+		)
+
+		Expect(cmd.Execute()).To(MatchError(ContainSubstring("cf dev is not running")))
+	})
+})
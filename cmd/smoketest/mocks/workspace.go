@@ -0,0 +1,58 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/smoketest (interfaces: Workspace)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	workspace "code.cloudfoundry.org/cfdev/workspace"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWorkspace is a mock of Workspace interface
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// CFCredentials mocks base method
+func (m *MockWorkspace) CFCredentials() workspace.Credentials {
+	ret := m.ctrl.Call(m, "CFCredentials")
+	ret0, _ := ret[0].(workspace.Credentials)
+	return ret0
+}
+
+// CFCredentials indicates an expected call of CFCredentials
+func (mr *MockWorkspaceMockRecorder) CFCredentials() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CFCredentials", reflect.TypeOf((*MockWorkspace)(nil).CFCredentials))
+}
+
+// EnvsMapping mocks base method
+func (m *MockWorkspace) EnvsMapping() map[string]string {
+	ret := m.ctrl.Call(m, "EnvsMapping")
+	ret0, _ := ret[0].(map[string]string)
+	return ret0
+}
+
+// EnvsMapping indicates an expected call of EnvsMapping
+func (mr *MockWorkspaceMockRecorder) EnvsMapping() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvsMapping", reflect.TypeOf((*MockWorkspace)(nil).EnvsMapping))
+}
@@ -0,0 +1,128 @@
+package smoketest
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/smoketest"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/smoketest UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/smoketest Workspace
+type Workspace interface {
+	EnvsMapping() map[string]string
+	CFCredentials() workspace.Credentials
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/smoketest Provisioner
+type Provisioner interface {
+	Ping(duration time.Duration) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/smoketest Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+type SmokeTest struct {
+	Exit        chan struct{}
+	UI          UI
+	Config      config.Config
+	Workspace   Workspace
+	Provisioner Provisioner
+	Analytics   Analytics
+	args        Args
+}
+
+type Args struct {
+	SkipApp bool
+}
+
+func (s *SmokeTest) Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "smoke-test",
+		Short: "Check that the CF Dev components are healthy",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			go func() {
+				<-s.Exit
+				os.Exit(128)
+			}()
+
+			return s.Execute(s.args)
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.BoolVar(&s.args.SkipApp, "skip-app", false, "skip pushing the test app")
+
+	return cmd
+}
+
+func (s *SmokeTest) Execute(args Args) error {
+	if err := s.Provisioner.Ping(10 * time.Second); err != nil {
+		return e.SafeWrap(err, "cf dev is not running. Please execute 'cf dev start'")
+	}
+
+	cfg := smoketest.Config{
+		APIURL:      "https://api." + s.Config.CFDomain,
+		UAAURL:      "https://uaa." + s.Config.CFDomain,
+		RouterAddr:  net.JoinHostPort(s.Config.CFRouterIP, "80"),
+		Domain:      s.Config.CFDomain,
+		Credentials: s.Workspace.CFCredentials(),
+		BoshEnvs:    s.Workspace.EnvsMapping(),
+		Proxy:       s.Config.CFProxy(),
+	}
+
+	s.UI.Say("Running smoke tests...")
+
+	var failed int
+	smoketest.Run(context.Background(), s.checks(cfg, args), func(r smoketest.Result) {
+		if r.Passed() {
+			s.UI.Say("  PASS  %s (%s)", r.Name, r.Duration.Round(100*time.Millisecond))
+		} else {
+			failed++
+			s.UI.Say("  FAIL  %s: %s", r.Name, r.Err)
+		}
+	})
+
+	s.Analytics.Event(cfanalytics.SMOKE_TEST, map[string]interface{}{"failed": failed})
+
+	if failed > 0 {
+		return e.SafeWrap(nil, fmt.Sprintf("%d smoke test(s) failed", failed))
+	}
+
+	s.UI.Say("All smoke tests passed")
+	return nil
+}
+
+func (s *SmokeTest) checks(cfg smoketest.Config, args Args) []smoketest.Check {
+	checks := smoketest.Checks(cfg)
+	if args.SkipApp {
+		return checks
+	}
+
+	cfBinary, err := exec.LookPath("cf")
+	if err != nil {
+		return append(checks, smoketest.Check{
+			Name:    "App push, curl and delete",
+			Timeout: time.Second,
+			Run: func(context.Context) error {
+				return fmt.Errorf("the cf CLI was not found in the PATH")
+			},
+		})
+	}
+
+	return append(checks, smoketest.AppCheck(cfg, cfBinary))
+}
@@ -0,0 +1,13 @@
+package smoketest_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSmokeTest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd SmokeTest Suite")
+}
@@ -0,0 +1,71 @@
+package smoketest_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/smoketest"
+	"code.cloudfoundry.org/cfdev/cmd/smoketest/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SmokeTest", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockWorkspace   *mocks.MockWorkspace
+		mockProvisioner *mocks.MockProvisioner
+		mockAnalytics   *mocks.MockAnalytics
+		cmd             *smoketest.SmokeTest
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		cmd = &smoketest.SmokeTest{
+			UI: mockUI,
+			Config: config.Config{
+				CFDomain:   "cfdev.invalid",
+				CFRouterIP: "127.0.0.1",
+			},
+			Workspace:   mockWorkspace,
+			Provisioner: mockProvisioner,
+			Analytics:   mockAnalytics,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("prints a line per check and fails when a check fails", func() {
+		var failures []string
+
+		mockProvisioner.EXPECT().Ping(gomock.Any())
+		mockWorkspace.EXPECT().CFCredentials().Return(workspace.Credentials{Username: "admin", Password: "admin"})
+		mockWorkspace.EXPECT().EnvsMapping().Return(map[string]string{})
+		mockUI.EXPECT().Say("Running smoke tests...")
+		mockUI.EXPECT().Say("  FAIL  %s: %s", gomock.Any(), gomock.Any()).Do(func(_ string, args ...interface{}) {
+			failures = append(failures, args[0].(string))
+		}).Times(4)
+		mockAnalytics.EXPECT().Event(cfanalytics.SMOKE_TEST, map[string]interface{}{"failed": 4})
+
+		err := cmd.Execute(smoketest.Args{SkipApp: true})
+		Expect(err).To(MatchError("4 smoke test(s) failed"))
+		Expect(failures).To(Equal([]string{"CF API", "UAA token endpoint", "Router", "BOSH Director"}))
+	})
+
+	It("returns an error when cf dev is not running", func() {
+		mockProvisioner.EXPECT().Ping(gomock.Any()).Return(errors.New("some-error"))
+
+		Expect(cmd.Execute(smoketest.Args{})).To(MatchError(ContainSubstring("cf dev is not running")))
+	})
+})
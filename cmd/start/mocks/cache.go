@@ -33,6 +33,19 @@ func (m *MockCache) EXPECT() *MockCacheMockRecorder {
 	return m.recorder
 }
 
+// Pending mocks base method
+func (m *MockCache) Pending(arg0 resource.Catalog) ([]resource.Item, error) {
+	ret := m.ctrl.Call(m, "Pending", arg0)
+	ret0, _ := ret[0].([]resource.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pending indicates an expected call of Pending
+func (mr *MockCacheMockRecorder) Pending(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockCache)(nil).Pending), arg0)
+}
+
 // Sync mocks base method
 func (m *MockCache) Sync(arg0 resource.Catalog) error {
 	ret := m.ctrl.Call(m, "Sync", arg0)
@@ -1,57 +1,122 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: code.cloudfoundry.org/cfdev/cmd/start (interfaces: Env)
+// Source: code.cloudfoundry.org/cfdev/cmd/start (interfaces: Workspace)
 
 // Package mocks is a generated GoMock package.
 package mocks
 
 import (
+	workspace "code.cloudfoundry.org/cfdev/workspace"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
-// MockEnv is a mock of Env interface
-type MockEnv struct {
+// MockWorkspace is a mock of Workspace interface
+type MockWorkspace struct {
 	ctrl     *gomock.Controller
-	recorder *MockEnvMockRecorder
+	recorder *MockWorkspaceMockRecorder
 }
 
-// MockEnvMockRecorder is the mock recorder for MockEnv
-type MockEnvMockRecorder struct {
-	mock *MockEnv
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
 }
 
-// NewMockEnv creates a new mock instance
-func NewMockEnv(ctrl *gomock.Controller) *MockEnv {
-	mock := &MockEnv{ctrl: ctrl}
-	mock.recorder = &MockEnvMockRecorder{mock}
+// NewMockWorkspace creates a new mock instance
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockEnv) EXPECT() *MockEnvMockRecorder {
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
 	return m.recorder
 }
 
 // CreateDirs mocks base method
-func (m *MockEnv) CreateDirs() error {
+func (m *MockWorkspace) CreateDirs() error {
 	ret := m.ctrl.Call(m, "CreateDirs")
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateDirs indicates an expected call of CreateDirs
-func (mr *MockEnvMockRecorder) CreateDirs() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDirs", reflect.TypeOf((*MockEnv)(nil).CreateDirs))
+func (mr *MockWorkspaceMockRecorder) CreateDirs() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDirs", reflect.TypeOf((*MockWorkspace)(nil).CreateDirs))
+}
+
+// Durations mocks base method
+func (m *MockWorkspace) Durations() (map[string]time.Duration, error) {
+	ret := m.ctrl.Call(m, "Durations")
+	ret0, _ := ret[0].(map[string]time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Durations indicates an expected call of Durations
+func (mr *MockWorkspaceMockRecorder) Durations() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Durations", reflect.TypeOf((*MockWorkspace)(nil).Durations))
+}
+
+// Metadata mocks base method
+func (m *MockWorkspace) Metadata() (workspace.Metadata, error) {
+	ret := m.ctrl.Call(m, "Metadata")
+	ret0, _ := ret[0].(workspace.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Metadata indicates an expected call of Metadata
+func (mr *MockWorkspaceMockRecorder) Metadata() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metadata", reflect.TypeOf((*MockWorkspace)(nil).Metadata))
+}
+
+// ReadDepsMetadata mocks base method
+func (m *MockWorkspace) ReadDepsMetadata(arg0 string) (workspace.Metadata, error) {
+	ret := m.ctrl.Call(m, "ReadDepsMetadata", arg0)
+	ret0, _ := ret[0].(workspace.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDepsMetadata indicates an expected call of ReadDepsMetadata
+func (mr *MockWorkspaceMockRecorder) ReadDepsMetadata(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDepsMetadata", reflect.TypeOf((*MockWorkspace)(nil).ReadDepsMetadata), arg0)
+}
+
+// RecordDuration mocks base method
+func (m *MockWorkspace) RecordDuration(arg0 string, arg1 time.Duration) error {
+	ret := m.ctrl.Call(m, "RecordDuration", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDuration indicates an expected call of RecordDuration
+func (mr *MockWorkspaceMockRecorder) RecordDuration(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDuration", reflect.TypeOf((*MockWorkspace)(nil).RecordDuration), arg0, arg1)
+}
+
+// SaveSettings mocks base method
+func (m *MockWorkspace) SaveSettings(arg0 workspace.Settings) error {
+	ret := m.ctrl.Call(m, "SaveSettings", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSettings indicates an expected call of SaveSettings
+func (mr *MockWorkspaceMockRecorder) SaveSettings(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSettings", reflect.TypeOf((*MockWorkspace)(nil).SaveSettings), arg0)
 }
 
 // SetupState mocks base method
-func (m *MockEnv) SetupState(arg0 string) error {
+func (m *MockWorkspace) SetupState(arg0 string) error {
 	ret := m.ctrl.Call(m, "SetupState", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetupState indicates an expected call of SetupState
-func (mr *MockEnvMockRecorder) SetupState(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetupState", reflect.TypeOf((*MockEnv)(nil).SetupState), arg0)
+func (mr *MockWorkspaceMockRecorder) SetupState(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetupState", reflect.TypeOf((*MockWorkspace)(nil).SetupState), arg0)
 }
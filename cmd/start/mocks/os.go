@@ -34,14 +34,18 @@ func (m *MockOS) EXPECT() *MockOSMockRecorder {
 }
 
 // Stats mocks base method
-func (m *MockOS) Stats() (os.Stats, error) {
-	ret := m.ctrl.Call(m, "Stats")
+func (m *MockOS) Stats(arg0 ...string) (os.Stats, error) {
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Stats", varargs...)
 	ret0, _ := ret[0].(os.Stats)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Stats indicates an expected call of Stats
-func (mr *MockOSMockRecorder) Stats() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockOS)(nil).Stats))
+func (mr *MockOSMockRecorder) Stats(arg0 ...interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockOS)(nil).Stats), arg0...)
 }
@@ -5,6 +5,8 @@
 package mocks
 
 import (
+	provision "code.cloudfoundry.org/cfdev/provision"
+	workspace "code.cloudfoundry.org/cfdev/workspace"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 	time "time"
@@ -44,3 +46,28 @@ func (m *MockProvisioner) Ping(arg0 time.Duration) error {
 func (mr *MockProvisionerMockRecorder) Ping(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockProvisioner)(nil).Ping), arg0)
 }
+
+// RunHooks mocks base method
+func (m *MockProvisioner) RunHooks(arg0 provision.UI, arg1 string) error {
+	ret := m.ctrl.Call(m, "RunHooks", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunHooks indicates an expected call of RunHooks
+func (mr *MockProvisionerMockRecorder) RunHooks(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunHooks", reflect.TypeOf((*MockProvisioner)(nil).RunHooks), arg0, arg1)
+}
+
+// WhiteListServices mocks base method
+func (m *MockProvisioner) WhiteListServices(arg0 string, arg1 []workspace.Service) ([]workspace.Service, error) {
+	ret := m.ctrl.Call(m, "WhiteListServices", arg0, arg1)
+	ret0, _ := ret[0].([]workspace.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WhiteListServices indicates an expected call of WhiteListServices
+func (mr *MockProvisionerMockRecorder) WhiteListServices(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WhiteListServices", reflect.TypeOf((*MockProvisioner)(nil).WhiteListServices), arg0, arg1)
+}
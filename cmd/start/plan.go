@@ -0,0 +1,172 @@
+package start
+
+import (
+	"code.cloudfoundry.org/bytefmt"
+	e "code.cloudfoundry.org/cfdev/errors"
+	cfdevos "code.cloudfoundry.org/cfdev/os"
+	"code.cloudfoundry.org/cfdev/resource"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// plan reports what 'cf dev start' would download and deploy
+// with the given arguments, without changing anything.
+func (s *Start) plan(args Args, depsPath string, stats cfdevos.Stats) error {
+	go func() {
+		<-s.Exit
+		os.Exit(128)
+	}()
+
+	s.UI.Say("Plan for 'cf dev start':")
+
+	requirementsErr := s.Driver.CheckRequirements()
+	if requirementsErr != nil {
+		s.UI.Say("Host requirements: FAILED: %s", requirementsErr)
+	} else {
+		s.UI.Say("Host requirements: OK")
+	}
+
+	if running, err := s.Driver.IsRunning(); err == nil && running {
+		s.UI.Say("CF Dev is already running, 'cf dev start' would not do anything.")
+		return requirementsErr
+	}
+
+	pending, err := s.Cache.Pending(s.Config.Dependencies)
+	if err != nil {
+		return e.SafeWrap(err, "Unable to check the downloaded assets")
+	}
+
+	s.printDownloads(pending)
+
+	metadata, err := s.planMetadata(depsPath)
+	if err != nil {
+		s.UI.Say("Deployments: unknown until %s is downloaded", filepath.Base(depsPath))
+		return requirementsErr
+	}
+
+	if metadata.Version != compatibilityVersion {
+		return fmt.Errorf("%s is not compatible with CF Dev. Please use a compatible file", depsPath)
+	}
+
+	if args.DeploySingleService != "" && !s.isServiceSupported(args.DeploySingleService, metadata.Services) {
+		return fmt.Errorf("Service: '%v' is not supported", args.DeploySingleService)
+	}
+
+	memory, err := s.allocateMemory(metadata, stats, args.Mem)
+	if err != nil {
+		return err
+	}
+
+	s.UI.Say("VM: %d CPUs, %d MB of memory, %d GB of disk", args.Cpus, memory, args.Disk)
+
+	services, err := s.Provisioner.WhiteListServices(args.DeploySingleService, metadata.Services)
+	if err != nil {
+		return e.SafeWrap(err, "Failed to whitelist services")
+	}
+
+	durations, err := s.Workspace.Durations()
+	if err != nil {
+		durations = map[string]time.Duration{}
+	}
+
+	s.printDeployments(services, durations)
+
+	if args.NoProvision {
+		s.UI.Say("The VM would not be provisioned because of the '-n' (no-provision) flag.")
+	}
+
+	return requirementsErr
+}
+
+// planMetadata reads the metadata of the deps file that would be used,
+// or of the installed assets while the deps file is not downloaded yet.
+func (s *Start) planMetadata(depsPath string) (workspace.Metadata, error) {
+	if _, err := os.Stat(depsPath); err == nil {
+		s.UI.Say("Reading %s...", depsPath)
+		return s.Workspace.ReadDepsMetadata(depsPath)
+	}
+
+	metadata, err := s.Workspace.Metadata()
+	if err != nil {
+		return workspace.Metadata{}, err
+	}
+
+	s.UI.Say("The deployments below are the ones of the installed assets, %s may differ.", filepath.Base(depsPath))
+	return metadata, nil
+}
+
+func (s *Start) printDownloads(pending []resource.Item) {
+	if len(pending) == 0 {
+		s.UI.Say("Downloads: none, every asset is cached")
+		return
+	}
+
+	var total uint64
+	s.UI.Say("Downloads:")
+	for _, item := range pending {
+		s.UI.Say("  %s (%s)", item.Name, bytefmt.ByteSize(item.Size))
+		total += item.Size
+	}
+
+	s.UI.Say("  Total: %s", bytefmt.ByteSize(total))
+}
+
+func (s *Start) printDeployments(services []workspace.Service, durations map[string]time.Duration) {
+	var (
+		estimate time.Duration
+		unknown  int
+		w        = tabwriter.NewWriter(s.UI.Writer(), 0, 0, 3, ' ', 0)
+		step     = func(name string, key string) {
+			d, ok := durations[key]
+			if !ok {
+				unknown++
+				fmt.Fprintf(w, "  %s\tno previous run\n", name)
+				return
+			}
+
+			estimate += d
+			fmt.Fprintf(w, "  %s\t~%s\n", name, formatDuration(d))
+		}
+	)
+
+	s.UI.Say("Deployments:")
+	step("Starting the VM", workspace.DurationVM)
+	step("BOSH Director", workspace.DurationBosh)
+
+	for _, service := range services {
+		kind := "deployment " + service.Deployment
+		if service.IsErrand {
+			kind = "errand of " + service.Deployment
+		}
+
+		step(fmt.Sprintf("%s (%s)", service.Name, kind), service.Name)
+	}
+
+	w.Flush()
+
+	switch {
+	case estimate == 0:
+		s.UI.Say("Estimated duration: unknown, CF Dev was not provisioned on this machine before")
+	case unknown > 0:
+		s.UI.Say("Estimated duration: more than %s, %d step(s) never ran on this machine (downloads excluded)", formatDuration(estimate), unknown)
+	default:
+		s.UI.Say("Estimated duration: %s (downloads excluded)", formatDuration(estimate))
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+
+	d = d.Round(time.Minute)
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh%02dm", d/time.Hour, d%time.Hour/time.Minute)
+	}
+
+	return fmt.Sprintf("%dm", d/time.Minute)
+}
@@ -0,0 +1,112 @@
+package start
+
+import (
+	"code.cloudfoundry.org/cfdev/driver"
+	e "code.cloudfoundry.org/cfdev/errors"
+	cfdevos "code.cloudfoundry.org/cfdev/os"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// vmDiskHeadroom is the space in GB the disk of the VM
+// takes on the host once a new environment is provisioned.
+const vmDiskHeadroom = 20
+
+// preflight checks that the host can run the VM with the given arguments
+// before anything is downloaded. It fails on what would make the start
+// fail later on, and warns about what would make CF Dev slow.
+func (s *Start) preflight(args Args, depsPath string, stats cfdevos.Stats, resume bool) error {
+	var problems []string
+
+	switch {
+	case args.Cpus <= 0:
+		problems = append(problems, fmt.Sprintf("invalid number of CPUs %d", args.Cpus))
+	case stats.CPUs > 0 && args.Cpus > stats.CPUs:
+		problems = append(problems, fmt.Sprintf("%d CPUs were requested, this machine has %d", args.Cpus, stats.CPUs))
+	}
+
+	switch {
+	case args.Mem < 0:
+		problems = append(problems, fmt.Sprintf("invalid memory size %d", args.Mem))
+	case stats.TotalMemory > 0 && uint64(args.Mem) > stats.TotalMemory:
+		problems = append(problems, fmt.Sprintf("%d MB of memory were requested, this machine has %d MB", args.Mem, stats.TotalMemory))
+	}
+
+	if stats.CPUs > 0 && stats.Load >= float64(stats.CPUs) {
+		s.UI.Say("WARNING: The load of this machine is %.1f for %d CPUs, CF Dev may be slow to start.", stats.Load, stats.CPUs)
+	}
+
+	// The cache and the state of CF Dev are both in its home directory
+	if free, ok := stats.FreeDisk[s.Config.CFDevHome]; ok {
+		needed, err := s.diskNeeded(depsPath, resume)
+		if err != nil {
+			return e.SafeWrap(err, "Unable to check the downloaded assets")
+		}
+
+		if free < needed {
+			problems = append(problems, fmt.Sprintf("%s has %d GB free, CF Dev needs about %d GB for its assets and the disk of the VM",
+				s.Config.CFDevHome, free/1024, (needed+1023)/1024))
+		}
+	}
+
+	if portUser, ok := s.Driver.(driver.PortUser); ok {
+		for _, address := range portUser.HostPorts() {
+			if portInUse(address) {
+				problems = append(problems, fmt.Sprintf("%s is already in use by another program", address))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return e.SafeWrap(fmt.Errorf("\n- %s", strings.Join(problems, "\n- ")), "The host cannot run CF Dev")
+}
+
+// diskNeeded is the space in MB taken by the assets still to be downloaded,
+// the extracted assets and the disk of the VM of a new environment.
+func (s *Start) diskNeeded(depsPath string, resume bool) (uint64, error) {
+	pending, err := s.Cache.Pending(s.Config.Dependencies)
+	if err != nil {
+		return 0, err
+	}
+
+	var needed uint64
+	for _, item := range pending {
+		needed += item.Size
+	}
+
+	if resume {
+		return needed / (1 << 20), nil
+	}
+
+	if deps := s.Config.Dependencies.Lookup("cfdev-deps.tgz"); deps != nil {
+		needed += deps.Size
+	} else if info, err := os.Stat(depsPath); err == nil {
+		needed += uint64(info.Size())
+	}
+
+	return needed/(1<<20) + vmDiskHeadroom*1024, nil
+}
+
+// portInUse tells whether another program accepts connections
+// on the address, which cannot be listened on.
+func portInUse(address string) bool {
+	listener, err := net.Listen("tcp", address)
+	if err == nil {
+		listener.Close()
+		return false
+	}
+
+	conn, err := net.DialTimeout("tcp", address, 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+
+	conn.Close()
+	return true
+}
@@ -0,0 +1,163 @@
+package start
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/driver"
+	cfdevos "code.cloudfoundry.org/cfdev/os"
+	"code.cloudfoundry.org/cfdev/resource"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const gigabyte = 1 << 30
+
+type fakeUI struct {
+	messages *[]string
+}
+
+func (u fakeUI) Say(message string, args ...interface{}) {
+	*u.messages = append(*u.messages, fmt.Sprintf(message, args...))
+}
+
+func (u fakeUI) Writer() io.Writer {
+	return ioutil.Discard
+}
+
+type fakeCache struct {
+	pending []resource.Item
+}
+
+func (c *fakeCache) Sync(resource.Catalog) error {
+	return nil
+}
+
+func (c *fakeCache) Pending(resource.Catalog) ([]resource.Item, error) {
+	return c.pending, nil
+}
+
+type fakeDriver struct {
+	driver.Driver
+	ports []string
+}
+
+func (d fakeDriver) HostPorts() []string {
+	return d.ports
+}
+
+var _ = Describe("Preflight", func() {
+	var (
+		messages []string
+		cache    *fakeCache
+		d        *fakeDriver
+		s        *Start
+		args     Args
+		stats    cfdevos.Stats
+	)
+
+	BeforeEach(func() {
+		messages = nil
+		cache = &fakeCache{}
+		d = &fakeDriver{}
+		s = &Start{
+			UI:     fakeUI{&messages},
+			Cache:  cache,
+			Driver: d,
+			Config: config.Config{
+				CFDevHome: "/home/user/.cfdev",
+				Dependencies: resource.Catalog{
+					Items: []resource.Item{{Name: "cfdev-deps.tgz", Size: 10 * gigabyte}},
+				},
+			},
+		}
+
+		args = Args{Cpus: 4, Mem: 8192, Disk: 120}
+		stats = cfdevos.Stats{
+			TotalMemory: 16384,
+			CPUs:        8,
+			FreeDisk:    map[string]uint64{"/home/user/.cfdev": 100 * 1024},
+		}
+	})
+
+	It("passes on a host that can run the VM", func() {
+		Expect(s.preflight(args, "", stats, false)).To(Succeed())
+		Expect(messages).To(BeEmpty())
+	})
+
+	It("reports more CPUs than the host has", func() {
+		args.Cpus = 16
+
+		Expect(s.preflight(args, "", stats, false)).To(MatchError(ContainSubstring("16 CPUs were requested, this machine has 8")))
+	})
+
+	It("reports more memory than the host has", func() {
+		args.Mem = 32768
+
+		Expect(s.preflight(args, "", stats, false)).To(MatchError(ContainSubstring("32768 MB of memory were requested, this machine has 16384 MB")))
+	})
+
+	It("warns when the host is busy", func() {
+		stats.Load = 9.5
+
+		Expect(s.preflight(args, "", stats, false)).To(Succeed())
+		Expect(messages).To(ConsistOf("WARNING: The load of this machine is 9.5 for 8 CPUs, CF Dev may be slow to start."))
+	})
+
+	Context("when the environment is new", func() {
+		It("needs space for the assets and the disk of the VM", func() {
+			stats.FreeDisk["/home/user/.cfdev"] = 25 * 1024
+
+			Expect(s.preflight(args, "", stats, false)).To(MatchError(ContainSubstring("/home/user/.cfdev has 25 GB free, CF Dev needs about 30 GB")))
+		})
+
+		It("needs space for the downloads as well", func() {
+			cache.pending = []resource.Item{{Name: "cfdev-deps.tgz", Size: 10 * gigabyte}}
+			stats.FreeDisk["/home/user/.cfdev"] = 35 * 1024
+
+			Expect(s.preflight(args, "", stats, false)).To(MatchError(ContainSubstring("has 35 GB free, CF Dev needs about 40 GB")))
+		})
+	})
+
+	Context("when the environment is resumed", func() {
+		It("only needs space for the downloads", func() {
+			stats.FreeDisk["/home/user/.cfdev"] = 5 * 1024
+			Expect(s.preflight(args, "", stats, true)).To(Succeed())
+
+			cache.pending = []resource.Item{{Name: "analyticsd", Size: 6 * gigabyte}}
+			Expect(s.preflight(args, "", stats, true)).To(MatchError(ContainSubstring("has 5 GB free, CF Dev needs about 6 GB")))
+		})
+	})
+
+	It("reports the ports used by another program", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		free, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		freeAddress := free.Addr().String()
+		free.Close()
+
+		d.ports = []string{listener.Addr().String(), freeAddress}
+
+		err = s.preflight(args, "", stats, false)
+		Expect(err).To(MatchError(ContainSubstring(listener.Addr().String() + " is already in use by another program")))
+		Expect(err).NotTo(MatchError(ContainSubstring(freeAddress)))
+	})
+
+	It("reports every problem at once", func() {
+		args.Cpus = 16
+		args.Mem = 32768
+		stats.FreeDisk["/home/user/.cfdev"] = 1024
+
+		err := s.preflight(args, "", stats, false)
+		Expect(err).To(MatchError(ContainSubstring("The host cannot run CF Dev")))
+		Expect(err).To(MatchError(ContainSubstring("CPUs were requested")))
+		Expect(err).To(MatchError(ContainSubstring("of memory were requested")))
+		Expect(err).To(MatchError(ContainSubstring("GB free")))
+	})
+})
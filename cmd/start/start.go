@@ -2,6 +2,7 @@ package start
 
 import (
 	"code.cloudfoundry.org/cfdev/driver"
+	"code.cloudfoundry.org/cfdev/provision"
 	"code.cloudfoundry.org/cfdev/workspace"
 	"io"
 	"time"
@@ -48,12 +49,14 @@ type AnalyticsD interface {
 
 //go:generate mockgen -package mocks -destination mocks/os.go code.cloudfoundry.org/cfdev/cmd/start OS
 type OS interface {
-	Stats() (cfdevos.Stats, error)
+	Stats(dirs ...string) (cfdevos.Stats, error)
 }
 
 //go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/start Provisioner
 type Provisioner interface {
 	Ping(duration time.Duration) error
+	WhiteListServices(string, []workspace.Service) ([]workspace.Service, error)
+	RunHooks(ui provision.UI, point string) error
 }
 
 //go:generate mockgen -package mocks -destination mocks/provision.go code.cloudfoundry.org/cfdev/cmd/start Provision
@@ -71,11 +74,17 @@ type Workspace interface {
 	CreateDirs() error
 	SetupState(depsFile string) error
 	Metadata() (workspace.Metadata, error)
+	SaveSettings(settings workspace.Settings) error
+	Settings() (workspace.Settings, error)
+	ReadDepsMetadata(depsFile string) (workspace.Metadata, error)
+	Durations() (map[string]time.Duration, error)
+	RecordDuration(step string, duration time.Duration) error
 }
 
 //go:generate mockgen -package mocks -destination mocks/cache.go code.cloudfoundry.org/cfdev/cmd/start Cache
 type Cache interface {
 	Sync(resource.Catalog) error
+	Pending(resource.Catalog) ([]resource.Item, error)
 }
 
 type Args struct {
@@ -84,8 +93,13 @@ type Args struct {
 	DepsPath            string
 	EFIPath             string
 	NoProvision         bool
+	Target              bool
+	SmokeTest           bool
+	Plan                bool
 	Cpus                int
 	Mem                 int
+	Disk                int
+	Driver              string
 }
 
 type Start struct {
@@ -126,7 +140,12 @@ func (s *Start) Cmd() *cobra.Command {
 	pf.StringVarP(&args.Registries, "registries", "r", "", "docker registries that skip ssl validation - ie. host:port,host2:port2")
 	pf.IntVarP(&args.Cpus, "cpus", "c", 4, "cpus to allocate to vm")
 	pf.IntVarP(&args.Mem, "memory", "m", 0, "memory to allocate to vm in MB")
+	pf.IntVar(&args.Disk, "disk", config.DefaultDiskSize, "size of the vm disk in GB, an existing disk only grows")
+	pf.StringVar(&args.Driver, "driver", "", "hypervisor that runs the vm on Linux: qemu or cloud-hypervisor")
 	pf.BoolVarP(&args.NoProvision, "no-provision", "n", false, "start vm but do not provision")
+	pf.BoolVarP(&args.Target, "target", "t", false, "log the cf CLI in to CF Dev once provisioned")
+	pf.BoolVar(&args.SmokeTest, "smoke-test", false, "check that the CF Dev components are healthy once provisioned")
+	pf.BoolVar(&args.Plan, "plan", false, "show what would be downloaded and deployed, without starting anything")
 	pf.StringVarP(&args.DeploySingleService, "white-listed-services", "s", "", "list of supported services to deploy")
 	pf.StringVarP(&args.EFIPath, "efi", "e", filepath.Join(s.Config.BinaryDir, "cfdev-efi-v2.iso"), "path to efi boot iso")
 
@@ -136,14 +155,7 @@ func (s *Start) Cmd() *cobra.Command {
 }
 
 func (s *Start) Execute(args Args) error {
-	go func() {
-		<-s.Exit
-
-		s.Driver.Stop()
-		os.Exit(128)
-	}()
-
-	stats, _ := s.OS.Stats()
+	stats, _ := s.OS.Stats(s.Config.CFDevHome)
 	depsPath := filepath.Join(s.Config.CacheDir, "cfdev-deps.tgz")
 
 	if args.DepsPath != "" {
@@ -160,6 +172,48 @@ func (s *Start) Execute(args Args) error {
 		s.Config.Dependencies.Remove("cfdev-deps.tgz")
 	}
 
+	if args.Disk <= 0 {
+		return fmt.Errorf("invalid disk size %d, it must be a number of GB", args.Disk)
+	}
+
+	if args.Driver != "" {
+		selector, ok := s.Driver.(driver.HypervisorSelector)
+		if !ok {
+			return fmt.Errorf("choosing the driver with --driver is only supported on Linux")
+		}
+
+		if err := selector.SelectHypervisor(args.Driver); err != nil {
+			return err
+		}
+	}
+
+	if args.Plan {
+		return s.plan(args, depsPath, stats)
+	}
+
+	var (
+		provisioning = make(chan struct{})
+		provisioned  = make(chan struct{})
+	)
+
+	go func() {
+		<-s.Exit
+
+		// Provision cancels the deployments in progress on exit,
+		// they are given time to stop before their VM is stopped
+		select {
+		case <-provisioning:
+			select {
+			case <-provisioned:
+			case <-time.After(provision.CancelGracePeriod):
+			}
+		default:
+		}
+
+		s.Driver.Stop()
+		os.Exit(128)
+	}()
+
 	if err := s.Driver.CheckRequirements(); err != nil {
 		return err
 	}
@@ -176,8 +230,20 @@ func (s *Start) Execute(args Args) error {
 		return e.SafeWrap(err, "stopping cfdev")
 	}
 
-	if err := s.Workspace.CreateDirs(); err != nil {
-		return e.SafeWrap(err, "setting up cfdev home dir")
+	resume := s.resumable(depsPath)
+	if !resume {
+		if err := s.Workspace.CreateDirs(); err != nil {
+			return e.SafeWrap(err, "setting up cfdev home dir")
+		}
+	}
+
+	// The environment replaced by a new one no longer takes space
+	if current, err := s.OS.Stats(s.Config.CFDevHome); err == nil {
+		stats = current
+	}
+
+	if err := s.preflight(args, depsPath, stats, resume); err != nil {
+		return err
 	}
 
 	if cfdevd := s.Config.Dependencies.Lookup("cfdevd"); cfdevd != nil {
@@ -202,9 +268,11 @@ func (s *Start) Execute(args Args) error {
 		return e.SafeWrap(err, "Unable to sync assets")
 	}
 
-	s.UI.Say("Setting State...")
-	if err := s.Workspace.SetupState(depsPath); err != nil {
-		return e.SafeWrap(err, "Unable to setup directories")
+	if !resume {
+		s.UI.Say("Setting State...")
+		if err := s.Workspace.SetupState(depsPath); err != nil {
+			return e.SafeWrap(err, "Unable to setup directories")
+		}
 	}
 
 	metaData, err := s.Workspace.Metadata()
@@ -239,7 +307,23 @@ func (s *Start) Execute(args Args) error {
 		return err
 	}
 
-	err = s.Driver.Start(args.Cpus, memoryToAllocate, args.EFIPath)
+	err = s.Workspace.SaveSettings(workspace.Settings{
+		Cpus:       args.Cpus,
+		Memory:     memoryToAllocate,
+		Disk:       args.Disk,
+		Registries: args.Registries,
+		Services:   args.DeploySingleService,
+	})
+	if err != nil {
+		return e.SafeWrap(err, "Unable to save the settings")
+	}
+
+	if err := s.Provisioner.RunHooks(s.UI, workspace.HookPreStart); err != nil {
+		return err
+	}
+
+	vmStart := time.Now()
+	err = s.Driver.Start(args.Cpus, memoryToAllocate, args.Disk, args.EFIPath)
 	if err != nil {
 		return err
 	}
@@ -250,13 +334,29 @@ func (s *Start) Execute(args Args) error {
 		return e.SafeWrap(err, "Timed out waiting for the VM")
 	}
 
+	s.Workspace.RecordDuration(workspace.DurationVM, time.Now().Sub(vmStart))
+
+	if err := s.Provisioner.RunHooks(s.UI, workspace.HookPostVM); err != nil {
+		return err
+	}
+
 	if args.NoProvision {
 		s.UI.Say("VM will not be provisioned because '-n' (no-provision) flag was specified.")
 		return nil
 	}
 
-	if err := s.Provision.Execute(args); err != nil {
-		return err
+	close(provisioning)
+	err = s.Provision.Execute(args)
+	close(provisioned)
+
+	if err != nil {
+		select {
+		case <-s.Exit:
+			// The exit handler stops the VM and exits
+			select {}
+		default:
+			return err
+		}
 	}
 
 	if s.AnalyticsToggle.Enabled() {
@@ -267,6 +367,42 @@ func (s *Start) Execute(args Args) error {
 	return nil
 }
 
+// resumable tells whether the environment kept by 'cf dev stop' was
+// provisioned from the same assets, so that it is booted again rather
+// than provisioned from scratch.
+func (s *Start) resumable(depsPath string) bool {
+	if _, err := s.Workspace.Settings(); err != nil {
+		return false
+	}
+
+	current, err := s.Workspace.Metadata()
+	if err != nil {
+		return false
+	}
+
+	// Assets that still need to be downloaded are not the ones of the environment
+	if deps := s.Config.Dependencies.Lookup("cfdev-deps.tgz"); deps != nil {
+		pending, err := s.Cache.Pending(resource.Catalog{Items: []resource.Item{*deps}})
+		if err != nil || len(pending) > 0 {
+			s.UI.Say("Provisioning a new environment with new assets, the one kept by 'cf dev stop' is replaced...")
+			return false
+		}
+	}
+
+	next, err := s.Workspace.ReadDepsMetadata(depsPath)
+	if err != nil {
+		return false
+	}
+
+	if next.ArtifactVersion != current.ArtifactVersion {
+		s.UI.Say("The environment kept by 'cf dev stop' runs %s, provisioning a new one with %s...", current.ArtifactVersion, next.ArtifactVersion)
+		return false
+	}
+
+	s.UI.Say("Booting the environment kept by 'cf dev stop' (%s). Run 'cf dev stop --destroy' first to provision a new one.", current.ArtifactVersion)
+	return true
+}
+
 func (s *Start) isServiceSupported(service string, services []workspace.Service) bool {
 	if strings.ToLower(service) == "all" || strings.ToLower(service) == "none" {
 		return true
@@ -311,7 +447,7 @@ func (s *Start) allocateMemory(metaData workspace.Metadata, stats cfdevos.Stats,
 		}
 
 		if requestedMem < baseMem {
-			s.UI.Say(fmt.Sprintf("WARNING: It is recommended that you run %s Dev with at least %v MB of RAM.", strings.ToUpper(metaData.DeploymentName), baseMem))
+			s.UI.Say("WARNING: It is recommended that you run %s Dev with at least %v MB of RAM.", strings.ToUpper(metaData.DeploymentName), baseMem)
 			if stats.AvailableMemory >= uint64(requestedMem) {
 				return requestedMem, nil
 			}
@@ -325,7 +461,7 @@ func (s *Start) allocateMemory(metaData workspace.Metadata, stats cfdevos.Stats,
 		if stats.AvailableMemory >= uint64(baseMem) {
 			return baseMem, nil
 		} else {
-			s.UI.Say(fmt.Sprintf("WARNING: %s Dev requires %v MB of RAM to run. This machine may not have enough free RAM.", strings.ToUpper(metaData.DeploymentName), baseMem))
+			s.UI.Say("WARNING: %s Dev requires %v MB of RAM to run. This machine may not have enough free RAM.", strings.ToUpper(metaData.DeploymentName), baseMem)
 			return baseMem, nil
 		}
 	}
@@ -1,4 +1,4 @@
-package start_test
+package start
 
 import (
 	. "github.com/onsi/ginkgo"
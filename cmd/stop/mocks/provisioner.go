@@ -0,0 +1,46 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/stop (interfaces: Provisioner)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	provision "code.cloudfoundry.org/cfdev/provision"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockProvisioner is a mock of Provisioner interface
+type MockProvisioner struct {
+	ctrl     *gomock.Controller
+	recorder *MockProvisionerMockRecorder
+}
+
+// MockProvisionerMockRecorder is the mock recorder for MockProvisioner
+type MockProvisionerMockRecorder struct {
+	mock *MockProvisioner
+}
+
+// NewMockProvisioner creates a new mock instance
+func NewMockProvisioner(ctrl *gomock.Controller) *MockProvisioner {
+	mock := &MockProvisioner{ctrl: ctrl}
+	mock.recorder = &MockProvisionerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockProvisioner) EXPECT() *MockProvisionerMockRecorder {
+	return m.recorder
+}
+
+// RunHooks mocks base method
+func (m *MockProvisioner) RunHooks(arg0 provision.UI, arg1 string) error {
+	ret := m.ctrl.Call(m, "RunHooks", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunHooks indicates an expected call of RunHooks
+func (mr *MockProvisionerMockRecorder) RunHooks(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunHooks", reflect.TypeOf((*MockProvisioner)(nil).RunHooks), arg0, arg1)
+}
@@ -4,9 +4,18 @@ import (
 	"code.cloudfoundry.org/cfdev/cfanalytics"
 	"code.cloudfoundry.org/cfdev/driver"
 	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/workspace"
 	"github.com/spf13/cobra"
+	"io"
 )
 
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/stop UI
+type UI interface {
+	Say(message string, args ...interface{})
+	Writer() io.Writer
+}
+
 //go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/stop Analytics
 type Analytics interface {
 	Event(event string, data ...map[string]interface{}) error
@@ -18,24 +27,64 @@ type AnalyticsD interface {
 	Destroy() error
 }
 
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/stop Provisioner
+type Provisioner interface {
+	RunHooks(ui provision.UI, point string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/stop Workspace
+type Workspace interface {
+	Destroy() error
+}
+
+//go:generate mockgen -package mocks -destination mocks/driver.go code.cloudfoundry.org/cfdev/driver Driver
+
 type Stop struct {
-	Driver       driver.Driver
-	Analytics    Analytics
-	AnalyticsD   AnalyticsD
+	UI          UI
+	Driver      driver.Driver
+	Provisioner Provisioner
+	Analytics   Analytics
+	AnalyticsD  AnalyticsD
+	Workspace   Workspace
+}
+
+type Args struct {
+	Destroy bool
 }
 
 func (s *Stop) Cmd() *cobra.Command {
-	return &cobra.Command{
-		Use:  "stop",
-		RunE: s.RunE,
+	args := Args{}
+	cmd := &cobra.Command{
+		Use: "stop",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return s.Execute(args)
+		},
 	}
+
+	pf := cmd.PersistentFlags()
+	pf.BoolVar(&args.Destroy, "destroy", false, "delete the environment, the next start provisions a new one")
+	return cmd
 }
 
+// RunE stops the environment and keeps it, so that the next start boots it again.
 func (s *Stop) RunE(cmd *cobra.Command, args []string) error {
-	s.Analytics.Event(cfanalytics.STOP)
+	return s.Execute(Args{})
+}
+
+func (s *Stop) Execute(args Args) error {
+	s.Analytics.Event(cfanalytics.STOP, map[string]interface{}{"destroy": args.Destroy})
 
+	// A host that no longer meets the requirements
+	// to start must still be able to stop its VM
 	if err := s.Driver.CheckRequirements(); err != nil {
-		return err
+		s.UI.Say("Warning: %s", err)
+	}
+
+	// The pre-stop hooks can only act on a running environment
+	if running, err := s.Driver.IsRunning(); err == nil && running {
+		if err := s.Provisioner.RunHooks(s.UI, workspace.HookPreStop); err != nil {
+			return errors.SafeWrap(err, "cf dev stop")
+		}
 	}
 
 	var reterr error
@@ -56,5 +105,12 @@ func (s *Stop) RunE(cmd *cobra.Command, args []string) error {
 		return errors.SafeWrap(reterr, "cf dev stop")
 	}
 
+	if args.Destroy {
+		s.UI.Say("Deleting the environment...")
+		if err := s.Workspace.Destroy(); err != nil {
+			return errors.SafeWrap(err, "cf dev stop")
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,85 @@
+package stop_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/stop"
+	"code.cloudfoundry.org/cfdev/cmd/stop/mocks"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+	"github.com/golang/mock/gomock"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stop", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockDriver      *mocks.MockDriver
+		mockAnalytics   *mocks.MockAnalytics
+		mockAnalyticsD  *mocks.MockAnalyticsD
+		mockProvisioner *mocks.MockProvisioner
+		mockWorkspace   *mocks.MockWorkspace
+		cmd             *stop.Stop
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockDriver = mocks.NewMockDriver(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+		mockAnalyticsD = mocks.NewMockAnalyticsD(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
+
+		cmd = &stop.Stop{
+			UI:          mockUI,
+			Driver:      mockDriver,
+			Provisioner: mockProvisioner,
+			Analytics:   mockAnalytics,
+			AnalyticsD:  mockAnalyticsD,
+			Workspace:   mockWorkspace,
+		}
+
+		mockDriver.EXPECT().IsRunning().Return(true, nil)
+		mockProvisioner.EXPECT().RunHooks(mockUI, workspace.HookPreStop)
+		mockAnalyticsD.EXPECT().Stop()
+		mockAnalyticsD.EXPECT().Destroy()
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("stops the VM and keeps the environment", func() {
+		mockDriver.EXPECT().CheckRequirements()
+		mockAnalytics.EXPECT().Event(cfanalytics.STOP, map[string]interface{}{"destroy": false})
+		mockDriver.EXPECT().Stop()
+
+		Expect(cmd.RunE(nil, nil)).To(Succeed())
+	})
+
+	It("deletes the environment with --destroy", func() {
+		mockDriver.EXPECT().CheckRequirements()
+		mockAnalytics.EXPECT().Event(cfanalytics.STOP, map[string]interface{}{"destroy": true})
+		gomock.InOrder(
+			mockDriver.EXPECT().Stop(),
+			mockUI.EXPECT().Say("Deleting the environment..."),
+			mockWorkspace.EXPECT().Destroy(),
+		)
+
+		Expect(cmd.Execute(stop.Args{Destroy: true})).To(Succeed())
+	})
+
+	It("only warns when the host no longer meets the requirements", func() {
+		mockAnalytics.EXPECT().Event(cfanalytics.STOP, map[string]interface{}{"destroy": false})
+		gomock.InOrder(
+			mockDriver.EXPECT().CheckRequirements().Return(errors.New("dnsmasq is not installed")),
+			mockUI.EXPECT().Say("Warning: %s", errors.New("dnsmasq is not installed")),
+			mockDriver.EXPECT().Stop(),
+		)
+
+		Expect(cmd.Execute(stop.Args{})).To(Succeed())
+	})
+})
@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/target (interfaces: CLI)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "code.cloudfoundry.org/cli/plugin/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockCLI is a mock of CLI interface
+type MockCLI struct {
+	ctrl     *gomock.Controller
+	recorder *MockCLIMockRecorder
+}
+
+// MockCLIMockRecorder is the mock recorder for MockCLI
+type MockCLIMockRecorder struct {
+	mock *MockCLI
+}
+
+// NewMockCLI creates a new mock instance
+func NewMockCLI(ctrl *gomock.Controller) *MockCLI {
+	mock := &MockCLI{ctrl: ctrl}
+	mock.recorder = &MockCLIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCLI) EXPECT() *MockCLIMockRecorder {
+	return m.recorder
+}
+
+// CliCommandWithoutTerminalOutput mocks base method
+func (m *MockCLI) CliCommandWithoutTerminalOutput(arg0 ...string) ([]string, error) {
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CliCommandWithoutTerminalOutput", varargs...)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CliCommandWithoutTerminalOutput indicates an expected call of CliCommandWithoutTerminalOutput
+func (mr *MockCLIMockRecorder) CliCommandWithoutTerminalOutput(arg0 ...interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CliCommandWithoutTerminalOutput", reflect.TypeOf((*MockCLI)(nil).CliCommandWithoutTerminalOutput), arg0...)
+}
+
+// GetOrgs mocks base method
+func (m *MockCLI) GetOrgs() ([]models.GetOrgs_Model, error) {
+	ret := m.ctrl.Call(m, "GetOrgs")
+	ret0, _ := ret[0].([]models.GetOrgs_Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrgs indicates an expected call of GetOrgs
+func (mr *MockCLIMockRecorder) GetOrgs() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrgs", reflect.TypeOf((*MockCLI)(nil).GetOrgs))
+}
+
+// GetSpaces mocks base method
+func (m *MockCLI) GetSpaces() ([]models.GetSpaces_Model, error) {
+	ret := m.ctrl.Call(m, "GetSpaces")
+	ret0, _ := ret[0].([]models.GetSpaces_Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpaces indicates an expected call of GetSpaces
+func (mr *MockCLIMockRecorder) GetSpaces() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpaces", reflect.TypeOf((*MockCLI)(nil).GetSpaces))
+}
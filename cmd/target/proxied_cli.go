@@ -0,0 +1,88 @@
+package target
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cli/plugin/models"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ProxiedCLI runs the cf CLI in its own process, through the proxy to
+// CF Dev. The commands of the plugin connection run in the process of
+// the cf CLI that started the plugin, whose environment is out of reach.
+type ProxiedCLI struct {
+	Binary string
+	Proxy  string
+}
+
+func (c *ProxiedCLI) CliCommandWithoutTerminalOutput(args ...string) ([]string, error) {
+	binary := c.Binary
+	if binary == "" {
+		var err error
+		if binary, err = exec.LookPath("cf"); err != nil {
+			return nil, err
+		}
+	}
+
+	cmd := exec.Command(binary, args...)
+	cmd.Env = append(append(os.Environ(), config.ProxyEnvs(c.Proxy)...), "CF_COLOR=false")
+
+	output, err := cmd.CombinedOutput()
+	return strings.Split(strings.TrimSpace(string(output)), "\n"), err
+}
+
+// GetOrgs only fills in the names of the orgs, which is all Target needs.
+func (c *ProxiedCLI) GetOrgs() ([]plugin_models.GetOrgs_Model, error) {
+	names, err := c.names("orgs")
+	if err != nil {
+		return nil, err
+	}
+
+	var orgs []plugin_models.GetOrgs_Model
+	for _, name := range names {
+		orgs = append(orgs, plugin_models.GetOrgs_Model{Name: name})
+	}
+
+	return orgs, nil
+}
+
+// GetSpaces only fills in the names of the spaces of the targeted org.
+func (c *ProxiedCLI) GetSpaces() ([]plugin_models.GetSpaces_Model, error) {
+	names, err := c.names("spaces")
+	if err != nil {
+		return nil, err
+	}
+
+	var spaces []plugin_models.GetSpaces_Model
+	for _, name := range names {
+		spaces = append(spaces, plugin_models.GetSpaces_Model{Name: name})
+	}
+
+	return spaces, nil
+}
+
+// names are the lines that follow the name header of a cf CLI listing.
+func (c *ProxiedCLI) names(command string) ([]string, error) {
+	output, err := c.CliCommandWithoutTerminalOutput(command)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		names  []string
+		listed bool
+	)
+	for _, line := range output {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case listed && line != "":
+			names = append(names, line)
+		case line == "name":
+			listed = true
+		}
+	}
+
+	return names, nil
+}
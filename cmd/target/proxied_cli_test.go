@@ -0,0 +1,59 @@
+package target_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cmd/target"
+	"code.cloudfoundry.org/cli/plugin/models"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProxiedCLI", func() {
+	var (
+		tmpDir string
+		cli    *target.ProxiedCLI
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-proxied-cli-")
+		Expect(err).NotTo(HaveOccurred())
+
+		script := `#!/bin/sh
+case "$1" in
+orgs) printf 'Getting orgs as admin...\n\nname\ncfdev-org\nsystem\n' ;;
+spaces) printf 'Getting spaces in org cfdev-org as admin...\n\nname\ncfdev-space\n' ;;
+*) echo "$https_proxy $NO_PROXY $*" ;;
+esac
+`
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "cf"), []byte(script), 0755)).To(Succeed())
+
+		cli = &target.ProxiedCLI{
+			Binary: filepath.Join(tmpDir, "cf"),
+			Proxy:  "socks5://127.0.0.1:1080",
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("runs the cf CLI through the proxy", func() {
+		output, err := cli.CliCommandWithoutTerminalOutput("api", "https://api.dev.cfdev.sh")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(Equal([]string{"socks5://127.0.0.1:1080 localhost,127.0.0.1 api https://api.dev.cfdev.sh"}))
+	})
+
+	It("lists the orgs and the spaces", func() {
+		orgs, err := cli.GetOrgs()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orgs).To(Equal([]plugin_models.GetOrgs_Model{{Name: "cfdev-org"}, {Name: "system"}}))
+
+		spaces, err := cli.GetSpaces()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spaces).To(Equal([]plugin_models.GetSpaces_Model{{Name: "cfdev-space"}}))
+	})
+})
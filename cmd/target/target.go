@@ -0,0 +1,214 @@
+package target
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"code.cloudfoundry.org/cli/plugin/models"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/target UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/cli.go code.cloudfoundry.org/cfdev/cmd/target CLI
+type CLI interface {
+	CliCommandWithoutTerminalOutput(args ...string) ([]string, error)
+	GetOrgs() ([]plugin_models.GetOrgs_Model, error)
+	GetSpaces() ([]plugin_models.GetSpaces_Model, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/target Workspace
+type Workspace interface {
+	CFCredentials() workspace.Credentials
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/target Provisioner
+type Provisioner interface {
+	Ping(duration time.Duration) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/target Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+const (
+	defaultOrg      = "cfdev-org"
+	defaultSpace    = "cfdev-space"
+	defaultPassword = "password"
+)
+
+type Args struct {
+	Org      string
+	Space    string
+	User     string
+	Password string
+}
+
+type Target struct {
+	Exit        chan struct{}
+	UI          UI
+	CLI         CLI
+	Workspace   Workspace
+	Provisioner Provisioner
+	Analytics   Analytics
+	Config      config.Config
+}
+
+func (t *Target) Cmd() *cobra.Command {
+	args := Args{}
+	cmd := &cobra.Command{
+		Use:   "target",
+		Short: "Log the cf CLI in to CF Dev",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			go func() {
+				<-t.Exit
+				os.Exit(128)
+			}()
+
+			if err := t.Execute(args); err != nil {
+				return e.SafeWrap(err, "cf dev target")
+			}
+			return nil
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.StringVarP(&args.Org, "org", "o", defaultOrg, "org to create and target")
+	pf.StringVarP(&args.Space, "space", "s", defaultSpace, "space to create and target")
+	pf.StringVarP(&args.User, "user", "u", "", "log in as a non-admin developer account, created if missing")
+	pf.StringVarP(&args.Password, "password", "p", defaultPassword, "password of the developer account")
+	return cmd
+}
+
+func (t *Target) Execute(args Args) error {
+	if args.Org == "" {
+		args.Org = defaultOrg
+	}
+
+	if args.Space == "" {
+		args.Space = defaultSpace
+	}
+
+	if args.User != "" && args.Password == "" {
+		args.Password = defaultPassword
+	}
+
+	if err := t.Provisioner.Ping(10 * time.Second); err != nil {
+		return e.SafeWrap(err, "cf dev is not running. Please execute 'cf dev start'")
+	}
+
+	var (
+		admin = t.Workspace.CFCredentials()
+		api   = "https://api." + t.Config.CFDomain
+	)
+
+	if err := t.cf("api", api, "--skip-ssl-validation"); err != nil {
+		return e.SafeWrap(err, "failed to set the api endpoint")
+	}
+
+	if err := t.cf("auth", admin.Username, admin.Password); err != nil {
+		return e.SafeWrap(err, "failed to authenticate as the admin user")
+	}
+
+	if err := t.ensureOrg(args.Org); err != nil {
+		return err
+	}
+
+	if err := t.ensureSpace(args.Org, args.Space); err != nil {
+		return err
+	}
+
+	username := admin.Username
+	if args.User != "" {
+		if err := t.ensureUser(args); err != nil {
+			return err
+		}
+
+		if err := t.cf("auth", args.User, args.Password); err != nil {
+			return e.SafeWrap(err, "failed to authenticate as the developer user")
+		}
+
+		username = args.User
+	}
+
+	if err := t.cf("target", "-o", args.Org, "-s", args.Space); err != nil {
+		return e.SafeWrap(err, "failed to target the org and space")
+	}
+
+	t.Analytics.Event(cfanalytics.TARGET, map[string]interface{}{"developer_user": args.User != ""})
+	t.UI.Say("Logged in to %s as %s, targeting org '%s' and space '%s'", api, username, args.Org, args.Space)
+	return nil
+}
+
+func (t *Target) ensureOrg(org string) error {
+	orgs, err := t.CLI.GetOrgs()
+	if err != nil {
+		return e.SafeWrap(err, "failed to retrieve the orgs")
+	}
+
+	for _, o := range orgs {
+		if o.Name == org {
+			return t.cf("target", "-o", org)
+		}
+	}
+
+	if err := t.cf("create-org", org); err != nil {
+		return e.SafeWrap(err, "failed to create the org")
+	}
+
+	return t.cf("target", "-o", org)
+}
+
+func (t *Target) ensureSpace(org string, space string) error {
+	spaces, err := t.CLI.GetSpaces()
+	if err != nil {
+		return e.SafeWrap(err, "failed to retrieve the spaces")
+	}
+
+	for _, s := range spaces {
+		if s.Name == space {
+			return nil
+		}
+	}
+
+	if err := t.cf("create-space", space, "-o", org); err != nil {
+		return e.SafeWrap(err, "failed to create the space")
+	}
+
+	return nil
+}
+
+func (t *Target) ensureUser(args Args) error {
+	// 'cf create-user' succeeds when the user already exists in UAA
+	if err := t.cf("create-user", args.User, args.Password); err != nil {
+		return e.SafeWrap(err, "failed to create the developer user")
+	}
+
+	if err := t.cf("set-org-role", args.User, args.Org, "OrgManager"); err != nil {
+		return e.SafeWrap(err, "failed to assign the org role")
+	}
+
+	if err := t.cf("set-space-role", args.User, args.Org, args.Space, "SpaceDeveloper"); err != nil {
+		return e.SafeWrap(err, "failed to assign the space role")
+	}
+
+	return nil
+}
+
+func (t *Target) cf(args ...string) error {
+	output, err := t.CLI.CliCommandWithoutTerminalOutput(args...)
+	if err != nil {
+		return fmt.Errorf("cf %s: %s: %s", args[0], err, strings.Join(output, "\n"))
+	}
+
+	return nil
+}
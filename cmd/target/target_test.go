@@ -0,0 +1,136 @@
+package target_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/target"
+	"code.cloudfoundry.org/cfdev/cmd/target/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"code.cloudfoundry.org/cli/plugin/models"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Target", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockCLI         *mocks.MockCLI
+		mockWorkspace   *mocks.MockWorkspace
+		mockProvisioner *mocks.MockProvisioner
+		mockAnalytics   *mocks.MockAnalytics
+		cmd             *target.Target
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockCLI = mocks.NewMockCLI(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		cmd = &target.Target{
+			UI:          mockUI,
+			CLI:         mockCLI,
+			Workspace:   mockWorkspace,
+			Provisioner: mockProvisioner,
+			Analytics:   mockAnalytics,
+			Config: config.Config{
+				CFDomain: "dev.cfdev.sh",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	Context("when the org and space already exist", func() {
+		It("logs in as the admin and targets them", func() {
+			gomock.InOrder(
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockWorkspace.EXPECT().CFCredentials().Return(workspace.Credentials{Username: "admin", Password: "some-password"}),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("api", "https://api.dev.cfdev.sh", "--skip-ssl-validation"),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("auth", "admin", "some-password"),
+				mockCLI.EXPECT().GetOrgs().Return([]plugin_models.GetOrgs_Model{{Name: "cfdev-org"}}, nil),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("target", "-o", "cfdev-org"),
+				mockCLI.EXPECT().GetSpaces().Return([]plugin_models.GetSpaces_Model{{Name: "cfdev-space"}}, nil),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("target", "-o", "cfdev-org", "-s", "cfdev-space"),
+				mockAnalytics.EXPECT().Event(cfanalytics.TARGET, map[string]interface{}{"developer_user": false}),
+				mockUI.EXPECT().Say(gomock.Any(), "https://api.dev.cfdev.sh", "admin", "cfdev-org", "cfdev-space"),
+			)
+
+			Expect(cmd.Execute(target.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when the org and space are missing", func() {
+		It("creates them", func() {
+			gomock.InOrder(
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockWorkspace.EXPECT().CFCredentials().Return(workspace.Credentials{Username: "admin", Password: "admin"}),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("api", "https://api.dev.cfdev.sh", "--skip-ssl-validation"),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("auth", "admin", "admin"),
+				mockCLI.EXPECT().GetOrgs().Return([]plugin_models.GetOrgs_Model{{Name: "system"}}, nil),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("create-org", "some-org"),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("target", "-o", "some-org"),
+				mockCLI.EXPECT().GetSpaces().Return(nil, nil),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("create-space", "some-space", "-o", "some-org"),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("target", "-o", "some-org", "-s", "some-space"),
+				mockAnalytics.EXPECT().Event(cfanalytics.TARGET, gomock.Any()),
+				mockUI.EXPECT().Say(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+			)
+
+			Expect(cmd.Execute(target.Args{Org: "some-org", Space: "some-space"})).To(Succeed())
+		})
+	})
+
+	Context("when a developer user is requested", func() {
+		It("creates the user, assigns its roles and logs in as it", func() {
+			gomock.InOrder(
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockWorkspace.EXPECT().CFCredentials().Return(workspace.Credentials{Username: "admin", Password: "admin"}),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("api", "https://api.dev.cfdev.sh", "--skip-ssl-validation"),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("auth", "admin", "admin"),
+				mockCLI.EXPECT().GetOrgs().Return([]plugin_models.GetOrgs_Model{{Name: "cfdev-org"}}, nil),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("target", "-o", "cfdev-org"),
+				mockCLI.EXPECT().GetSpaces().Return([]plugin_models.GetSpaces_Model{{Name: "cfdev-space"}}, nil),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("create-user", "some-dev", "some-password"),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("set-org-role", "some-dev", "cfdev-org", "OrgManager"),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("set-space-role", "some-dev", "cfdev-org", "cfdev-space", "SpaceDeveloper"),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("auth", "some-dev", "some-password"),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("target", "-o", "cfdev-org", "-s", "cfdev-space"),
+				mockAnalytics.EXPECT().Event(cfanalytics.TARGET, map[string]interface{}{"developer_user": true}),
+				mockUI.EXPECT().Say(gomock.Any(), "https://api.dev.cfdev.sh", "some-dev", "cfdev-org", "cfdev-space"),
+			)
+
+			Expect(cmd.Execute(target.Args{User: "some-dev", Password: "some-password"})).To(Succeed())
+		})
+	})
+
+	Context("when cf dev is not running", func() {
+		It("returns an error", func() {
+			mockProvisioner.EXPECT().Ping(gomock.Any()).Return(errors.New("some-error"))
+
+			Expect(cmd.Execute(target.Args{})).To(MatchError(ContainSubstring("cf dev is not running")))
+		})
+	})
+
+	Context("when authentication fails", func() {
+		It("returns an error", func() {
+			gomock.InOrder(
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockWorkspace.EXPECT().CFCredentials().Return(workspace.Credentials{Username: "admin", Password: "admin"}),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("api", gomock.Any(), gomock.Any()),
+				mockCLI.EXPECT().CliCommandWithoutTerminalOutput("auth", "admin", "admin").Return([]string{"Credentials were rejected"}, errors.New("some-error")),
+			)
+
+			err := cmd.Execute(target.Args{})
+			Expect(err).To(MatchError(ContainSubstring("failed to authenticate as the admin user")))
+			Expect(err).To(MatchError(ContainSubstring("Credentials were rejected")))
+		})
+	})
+})
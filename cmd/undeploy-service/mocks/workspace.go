@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/undeploy-service (interfaces: Workspace)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	workspace "code.cloudfoundry.org/cfdev/workspace"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWorkspace is a mock of Workspace interface
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// CustomDeployments mocks base method
+func (m *MockWorkspace) CustomDeployments() ([]workspace.CustomDeployment, error) {
+	ret := m.ctrl.Call(m, "CustomDeployments")
+	ret0, _ := ret[0].([]workspace.CustomDeployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CustomDeployments indicates an expected call of CustomDeployments
+func (mr *MockWorkspaceMockRecorder) CustomDeployments() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomDeployments", reflect.TypeOf((*MockWorkspace)(nil).CustomDeployments))
+}
+
+// Metadata mocks base method
+func (m *MockWorkspace) Metadata() (workspace.Metadata, error) {
+	ret := m.ctrl.Call(m, "Metadata")
+	ret0, _ := ret[0].(workspace.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Metadata indicates an expected call of Metadata
+func (mr *MockWorkspaceMockRecorder) Metadata() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metadata", reflect.TypeOf((*MockWorkspace)(nil).Metadata))
+}
+
+// RemoveDeployment mocks base method
+func (m *MockWorkspace) RemoveDeployment(arg0 string) error {
+	ret := m.ctrl.Call(m, "RemoveDeployment", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveDeployment indicates an expected call of RemoveDeployment
+func (mr *MockWorkspaceMockRecorder) RemoveDeployment(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDeployment", reflect.TypeOf((*MockWorkspace)(nil).RemoveDeployment), arg0)
+}
@@ -0,0 +1,129 @@
+package undeploy_service
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/undeploy-service UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/undeploy-service Workspace
+type Workspace interface {
+	Metadata() (workspace.Metadata, error)
+	CustomDeployments() ([]workspace.CustomDeployment, error)
+	RemoveDeployment(name string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/undeploy-service Provisioner
+type Provisioner interface {
+	Ping(duration time.Duration) error
+	GetWhiteListedService(string, []workspace.Service) (*workspace.Service, error)
+	UndeployService(workspace.Service) error
+	DeleteDeployment(deployment string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/undeploy-service Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+type UndeployService struct {
+	Exit        chan struct{}
+	UI          UI
+	Config      config.Config
+	Workspace   Workspace
+	Provisioner Provisioner
+	Analytics   Analytics
+}
+
+func (u *UndeployService) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "undeploy-service",
+		Short: "Remove a deployed service",
+		Long: `Command removes the service provided as a parameter, to free the memory it uses.
+
+Deployments created with 'cf dev deploy-service --manifest' can be removed by name.`,
+		RunE: u.RunE,
+	}
+}
+
+func (u *UndeployService) RunE(cmd *cobra.Command, args []string) error {
+	go func() {
+		<-u.Exit
+		os.Exit(128)
+	}()
+
+	if len(args) != 1 {
+		return errors.New("A service name need to be passed as a argument")
+	}
+
+	return u.Execute(args[0])
+}
+
+func (u *UndeployService) Execute(name string) error {
+	metadata, err := u.Workspace.Metadata()
+	if err != nil {
+		return e.SafeWrap(err, "something went wrong while reading the assets. Please execute 'cf dev start'")
+	}
+
+	if u.Provisioner.Ping(10*time.Second) != nil {
+		return fmt.Errorf("cf dev is not running. Please execute 'cf dev start'")
+	}
+
+	customDeployments, err := u.Workspace.CustomDeployments()
+	if err != nil {
+		return e.SafeWrap(err, "failed to read the custom deployments")
+	}
+
+	for _, deployment := range customDeployments {
+		if deployment.Name == name {
+			return u.undeployCustom(deployment)
+		}
+	}
+
+	service, err := u.Provisioner.GetWhiteListedService(name, metadata.Services)
+	if err != nil {
+		return e.SafeWrap(err, "Failed to find the service")
+	}
+
+	if service.Flagname == "always-include" {
+		return fmt.Errorf("%s is always deployed with CF Dev and cannot be removed", service.Name)
+	}
+
+	u.UI.Say("Removing %s...", service.Name)
+	if err := u.Provisioner.UndeployService(*service); err != nil {
+		return e.SafeWrap(err, fmt.Sprintf("Failed to remove %s", service.Name))
+	}
+
+	if service.UndeployScript == "" {
+		u.UI.Say("WARNING: %s does not provide an undeploy script, only its deployment was deleted. Its service broker may still be registered with CF.", service.Name)
+	}
+
+	u.Analytics.Event(cfanalytics.UNDEPLOY_SERVICE, map[string]interface{}{"name": name})
+	return nil
+}
+
+func (u *UndeployService) undeployCustom(deployment workspace.CustomDeployment) error {
+	u.UI.Say("Removing %s...", deployment.Name)
+
+	if err := u.Provisioner.DeleteDeployment(deployment.Name); err != nil {
+		return e.SafeWrap(err, fmt.Sprintf("Failed to remove %s", deployment.Name))
+	}
+
+	if err := u.Workspace.RemoveDeployment(deployment.Name); err != nil {
+		return e.SafeWrap(err, "Failed to forget the deployment")
+	}
+
+	u.Analytics.Event(cfanalytics.UNDEPLOY_SERVICE, map[string]interface{}{"custom_manifest": true})
+	return nil
+}
@@ -0,0 +1,13 @@
+package undeploy_service_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestUndeployService(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Undeploy Service Suite")
+}
@@ -0,0 +1,115 @@
+package undeploy_service_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/undeploy-service"
+	"code.cloudfoundry.org/cfdev/cmd/undeploy-service/mocks"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UndeployService", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockWorkspace   *mocks.MockWorkspace
+		mockProvisioner *mocks.MockProvisioner
+		mockAnalytics   *mocks.MockAnalytics
+		cmd             *undeploy_service.UndeployService
+
+		cf       = workspace.Service{Name: "Cloud Foundry", Flagname: "always-include", Deployment: "cf"}
+		mysql    = workspace.Service{Name: "Mysql", Flagname: "mysql", Deployment: "cf-mysql", UndeployScript: "undeploy-mysql"}
+		redis    = workspace.Service{Name: "Redis", Flagname: "redis", Deployment: "cf-redis"}
+		metadata = workspace.Metadata{Services: []workspace.Service{cf, mysql, redis}}
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		cmd = &undeploy_service.UndeployService{
+			UI:          mockUI,
+			Workspace:   mockWorkspace,
+			Provisioner: mockProvisioner,
+			Analytics:   mockAnalytics,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("removes the service with its undeploy script", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(metadata, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockWorkspace.EXPECT().CustomDeployments(),
+			mockProvisioner.EXPECT().GetWhiteListedService("mysql", metadata.Services).Return(&mysql, nil),
+			mockUI.EXPECT().Say("Removing %s...", "Mysql"),
+			mockProvisioner.EXPECT().UndeployService(mysql),
+			mockAnalytics.EXPECT().Event(cfanalytics.UNDEPLOY_SERVICE, map[string]interface{}{"name": "mysql"}),
+		)
+
+		Expect(cmd.Execute("mysql")).To(Succeed())
+	})
+
+	It("warns that the broker may be left behind without an undeploy script", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(metadata, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockWorkspace.EXPECT().CustomDeployments(),
+			mockProvisioner.EXPECT().GetWhiteListedService("redis", metadata.Services).Return(&redis, nil),
+			mockUI.EXPECT().Say("Removing %s...", "Redis"),
+			mockProvisioner.EXPECT().UndeployService(redis),
+			mockUI.EXPECT().Say(gomock.Any(), "Redis"),
+			mockAnalytics.EXPECT().Event(cfanalytics.UNDEPLOY_SERVICE, map[string]interface{}{"name": "redis"}),
+		)
+
+		Expect(cmd.Execute("redis")).To(Succeed())
+	})
+
+	It("removes custom deployments by name", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(metadata, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockWorkspace.EXPECT().CustomDeployments().Return([]workspace.CustomDeployment{{Name: "my-kafka"}}, nil),
+			mockUI.EXPECT().Say("Removing %s...", "my-kafka"),
+			mockProvisioner.EXPECT().DeleteDeployment("my-kafka"),
+			mockWorkspace.EXPECT().RemoveDeployment("my-kafka"),
+			mockAnalytics.EXPECT().Event(cfanalytics.UNDEPLOY_SERVICE, map[string]interface{}{"custom_manifest": true}),
+		)
+
+		Expect(cmd.Execute("my-kafka")).To(Succeed())
+	})
+
+	It("does not remove Cloud Foundry itself", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(metadata, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockWorkspace.EXPECT().CustomDeployments(),
+			mockProvisioner.EXPECT().GetWhiteListedService("always-include", metadata.Services).Return(&cf, nil),
+		)
+
+		Expect(cmd.Execute("always-include")).To(MatchError("Cloud Foundry is always deployed with CF Dev and cannot be removed"))
+	})
+
+	It("reports a failed removal", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(metadata, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockWorkspace.EXPECT().CustomDeployments(),
+			mockProvisioner.EXPECT().GetWhiteListedService("mysql", metadata.Services).Return(&mysql, nil),
+			mockUI.EXPECT().Say("Removing %s...", "Mysql"),
+			mockProvisioner.EXPECT().UndeployService(mysql).Return(errors.New("some-error")),
+		)
+
+		Expect(cmd.Execute("mysql")).To(MatchError("Failed to remove Mysql: some-error"))
+	})
+})
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/cmd/upgrade (interfaces: Workspace)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	workspace "code.cloudfoundry.org/cfdev/workspace"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWorkspace is a mock of Workspace interface
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// ApplyUpgrade mocks base method
+func (m *MockWorkspace) ApplyUpgrade() error {
+	ret := m.ctrl.Call(m, "ApplyUpgrade")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyUpgrade indicates an expected call of ApplyUpgrade
+func (mr *MockWorkspaceMockRecorder) ApplyUpgrade() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyUpgrade", reflect.TypeOf((*MockWorkspace)(nil).ApplyUpgrade))
+}
+
+// CleanupUpgrade mocks base method
+func (m *MockWorkspace) CleanupUpgrade() error {
+	ret := m.ctrl.Call(m, "CleanupUpgrade")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CleanupUpgrade indicates an expected call of CleanupUpgrade
+func (mr *MockWorkspaceMockRecorder) CleanupUpgrade() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupUpgrade", reflect.TypeOf((*MockWorkspace)(nil).CleanupUpgrade))
+}
+
+// DirectorChanged mocks base method
+func (m *MockWorkspace) DirectorChanged() (bool, error) {
+	ret := m.ctrl.Call(m, "DirectorChanged")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DirectorChanged indicates an expected call of DirectorChanged
+func (mr *MockWorkspaceMockRecorder) DirectorChanged() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DirectorChanged", reflect.TypeOf((*MockWorkspace)(nil).DirectorChanged))
+}
+
+// Metadata mocks base method
+func (m *MockWorkspace) Metadata() (workspace.Metadata, error) {
+	ret := m.ctrl.Call(m, "Metadata")
+	ret0, _ := ret[0].(workspace.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Metadata indicates an expected call of Metadata
+func (mr *MockWorkspaceMockRecorder) Metadata() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metadata", reflect.TypeOf((*MockWorkspace)(nil).Metadata))
+}
+
+// RollbackUpgrade mocks base method
+func (m *MockWorkspace) RollbackUpgrade() error {
+	ret := m.ctrl.Call(m, "RollbackUpgrade")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackUpgrade indicates an expected call of RollbackUpgrade
+func (mr *MockWorkspaceMockRecorder) RollbackUpgrade() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackUpgrade", reflect.TypeOf((*MockWorkspace)(nil).RollbackUpgrade))
+}
+
+// StageUpgrade mocks base method
+func (m *MockWorkspace) StageUpgrade(arg0 string) (workspace.Metadata, error) {
+	ret := m.ctrl.Call(m, "StageUpgrade", arg0)
+	ret0, _ := ret[0].(workspace.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StageUpgrade indicates an expected call of StageUpgrade
+func (mr *MockWorkspaceMockRecorder) StageUpgrade(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StageUpgrade", reflect.TypeOf((*MockWorkspace)(nil).StageUpgrade), arg0)
+}
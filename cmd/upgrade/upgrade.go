@@ -0,0 +1,303 @@
+package upgrade
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/upgrade UI
+type UI interface {
+	Say(message string, args ...interface{})
+	Writer() io.Writer
+}
+
+//go:generate mockgen -package mocks -destination mocks/workspace.go code.cloudfoundry.org/cfdev/cmd/upgrade Workspace
+type Workspace interface {
+	Metadata() (workspace.Metadata, error)
+	StageUpgrade(depsFile string) (workspace.Metadata, error)
+	DirectorChanged() (bool, error)
+	ApplyUpgrade() error
+	RollbackUpgrade() error
+	CleanupUpgrade() error
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/upgrade Provisioner
+type Provisioner interface {
+	Ping(duration time.Duration) error
+	Deployments() ([]string, error)
+	DeployBosh() error
+	DeleteDeployment(deployment string) error
+	DeployServices(context.Context, provision.UI, []workspace.Service, []string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/upgrade Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+const compatibilityVersion = "v5"
+
+type Args struct {
+	DepsPath   string
+	Registries []string
+}
+
+type Upgrade struct {
+	Exit        chan struct{}
+	UI          UI
+	Config      config.Config
+	Workspace   Workspace
+	Provisioner Provisioner
+	Analytics   Analytics
+}
+
+func (u *Upgrade) Cmd() *cobra.Command {
+	var (
+		args       = Args{}
+		registries string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade a running CF Dev to a newer deps file",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if registries != "" {
+				args.Registries = strings.Split(registries, ",")
+			}
+
+			if err := u.Execute(args); err != nil {
+				return e.SafeWrap(err, "cf dev upgrade")
+			}
+			return nil
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.StringVarP(&args.DepsPath, "file", "f", "", "path to .dev file containing the newer bosh & cf bits")
+	pf.StringVarP(&registries, "registries", "r", "", "docker registries that skip ssl validation - ie. host:port,host2:port2")
+	return cmd
+}
+
+func (u *Upgrade) Execute(args Args) error {
+	applied := make(chan struct{})
+
+	go func() {
+		<-u.Exit
+
+		// Execute restores the previous deployments and returns
+		// once the new assets are in place
+		select {
+		case <-applied:
+		case <-time.After(provision.CancelGracePeriod):
+			os.Exit(128)
+		}
+	}()
+
+	if args.DepsPath == "" {
+		return fmt.Errorf("a deps file needs to be passed with the '-f' flag")
+	}
+
+	depsPath, err := filepath.Abs(args.DepsPath)
+	if err != nil {
+		return e.SafeWrap(err, "determining absolute path to deps file")
+	}
+
+	if _, err := os.Stat(depsPath); os.IsNotExist(err) {
+		return fmt.Errorf("no file found at: %s", depsPath)
+	}
+
+	current, err := u.Workspace.Metadata()
+	if err != nil {
+		return e.SafeWrap(err, "something went wrong while reading the assets. Please execute 'cf dev start'")
+	}
+
+	if err := u.Provisioner.Ping(10 * time.Second); err != nil {
+		return e.SafeWrap(err, "cf dev is not running. Please execute 'cf dev start'")
+	}
+
+	deployed, err := u.Provisioner.Deployments()
+	if err != nil {
+		return e.SafeWrap(err, "failed to list the deployments")
+	}
+
+	u.UI.Say("Reading %s...", depsPath)
+	next, err := u.Workspace.StageUpgrade(depsPath)
+	if err != nil {
+		u.Workspace.CleanupUpgrade()
+		return e.SafeWrap(err, fmt.Sprintf("%s is not compatible with CF Dev. Please use a compatible file.", depsPath))
+	}
+
+	if next.Version != compatibilityVersion || current.Version != next.Version {
+		u.Workspace.CleanupUpgrade()
+		return fmt.Errorf("%s cannot be upgraded to in place. Please execute 'cf dev stop' and 'cf dev start -f %s'", depsPath, depsPath)
+	}
+
+	directorChanged, err := u.Workspace.DirectorChanged()
+	if err != nil {
+		u.Workspace.CleanupUpgrade()
+		return e.SafeWrap(err, "failed to compare the BOSH Director manifests")
+	}
+
+	plan := workspace.PlanUpgrade(current, next, deployed)
+	plan.Director = directorChanged
+	u.printPlan(plan)
+
+	services := plan.Services()
+	if len(services) == 0 && !plan.Director {
+		u.Workspace.CleanupUpgrade()
+		u.UI.Say("Nothing to upgrade.")
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-u.Exit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := u.Workspace.ApplyUpgrade(); err != nil {
+		u.Workspace.RollbackUpgrade()
+		u.Workspace.CleanupUpgrade()
+		return e.SafeWrap(err, "failed to install the new assets")
+	}
+	close(applied)
+
+	// The deployments that were changed are restored
+	// whether the upgrade failed or was cancelled
+	changed, err := u.deploy(ctx, plan, args.Registries)
+	if err != nil {
+		if ctx.Err() != nil {
+			u.UI.Say("Upgrade cancelled, restoring the previous deployments...")
+		} else {
+			u.UI.Say("Upgrade failed, restoring the previous deployments...")
+		}
+
+		if rollbackErr := u.rollback(changed, current, args.Registries); rollbackErr != nil {
+			return e.SafeWrap(fmt.Errorf("%s (rollback: %s)", err, rollbackErr), "Failed to upgrade and to restore the previous deployments")
+		}
+
+		if ctx.Err() != nil {
+			return e.SafeWrap(err, "The upgrade was cancelled, the previous deployments were restored")
+		}
+
+		return e.SafeWrap(err, "Failed to upgrade, the previous deployments were restored")
+	}
+
+	u.Workspace.CleanupUpgrade()
+	u.Analytics.Event(cfanalytics.UPGRADE, map[string]interface{}{
+		"from": plan.From,
+		"to":   plan.To,
+	})
+	u.UI.Say("Upgraded CF Dev to %s", plan.To)
+	return nil
+}
+
+// deploy redeploys the BOSH Director and the services of the plan.
+// It returns the part of the plan whose deployments it changed.
+func (u *Upgrade) deploy(ctx context.Context, plan workspace.UpgradePlan, registries []string) (workspace.UpgradePlan, error) {
+	var changed workspace.UpgradePlan
+
+	if ctx.Err() != nil {
+		return changed, ctx.Err()
+	}
+
+	if plan.Director {
+		changed.Director = true
+
+		u.UI.Say("Deploying the BOSH Director...")
+		if err := u.Provisioner.DeployBosh(); err != nil {
+			return changed, e.SafeWrap(err, "Failed to deploy the BOSH Director")
+		}
+
+		// The Director cannot be interrupted, the services are not deployed once cancelled
+		if ctx.Err() != nil {
+			return changed, ctx.Err()
+		}
+	}
+
+	changed.Changes = plan.Changes
+	return changed, u.Provisioner.DeployServices(ctx, u.UI, plan.Services(), registries)
+}
+
+// rollback restores the previous assets and redeploys the previous version
+// of the deployments that were changed. It is not cancellable, so that
+// the environment is never left half upgraded.
+func (u *Upgrade) rollback(plan workspace.UpgradePlan, previous workspace.Metadata, registries []string) error {
+	if err := u.Workspace.RollbackUpgrade(); err != nil {
+		return err
+	}
+
+	if plan.Director {
+		u.UI.Say("Restoring the BOSH Director...")
+		if err := u.Provisioner.DeployBosh(); err != nil {
+			return err
+		}
+	}
+
+	var services []workspace.Service
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case workspace.ActionUpdate:
+			for _, s := range previous.Services {
+				if s.Name == change.Service.Name {
+					services = append(services, s)
+				}
+			}
+		case workspace.ActionAdd:
+			if err := u.Provisioner.DeleteDeployment(change.Service.Deployment); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(services) > 0 {
+		if err := u.Provisioner.DeployServices(context.Background(), u.UI, services, registries); err != nil {
+			return err
+		}
+	}
+
+	return u.Workspace.CleanupUpgrade()
+}
+
+func (u *Upgrade) printPlan(plan workspace.UpgradePlan) {
+	u.UI.Say("Upgrade plan: %s -> %s", plan.From, plan.To)
+
+	if plan.Director {
+		u.UI.Say("  ~ redeploy the BOSH Director")
+	}
+
+	for _, v := range plan.Versions {
+		from := v.From
+		if from == "" {
+			from = "(new)"
+		}
+		u.UI.Say("  %s: %s -> %s", v.Name, from, v.To)
+	}
+
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case workspace.ActionAdd:
+			u.UI.Say("  + deploy %s (%s)", change.Service.Name, change.Service.Deployment)
+		case workspace.ActionUpdate:
+			u.UI.Say("  ~ redeploy %s (%s)", change.Service.Name, change.Service.Deployment)
+		case workspace.ActionDropped:
+			u.UI.Say("  ! %s is no longer provided and is left as is", change.Service.Name)
+		}
+	}
+}
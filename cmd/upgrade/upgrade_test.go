@@ -0,0 +1,209 @@
+package upgrade_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cmd/upgrade"
+	"code.cloudfoundry.org/cfdev/cmd/upgrade/mocks"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Upgrade", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockWorkspace   *mocks.MockWorkspace
+		mockProvisioner *mocks.MockProvisioner
+		mockAnalytics   *mocks.MockAnalytics
+		cmd             *upgrade.Upgrade
+		tmpDir          string
+		depsPath        string
+
+		cf      = workspace.Service{Name: "cf", Flagname: "always-include", Deployment: "cf", Script: "deploy-cf"}
+		mysql   = workspace.Service{Name: "mysql", Flagname: "mysql", Deployment: "cf-mysql", Script: "deploy-mysql"}
+		current = workspace.Metadata{
+			Version:         "v5",
+			ArtifactVersion: "1.0",
+			Services:        []workspace.Service{cf},
+			Versions:        []workspace.Version{{Name: "capi", Value: "1.0"}},
+		}
+		next = workspace.Metadata{
+			Version:         "v5",
+			ArtifactVersion: "2.0",
+			Services:        []workspace.Service{cf, mysql},
+			Versions:        []workspace.Version{{Name: "capi", Value: "2.0"}},
+		}
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockWorkspace = mocks.NewMockWorkspace(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-upgrade-cmd-")
+		Expect(err).NotTo(HaveOccurred())
+
+		depsPath = filepath.Join(tmpDir, "cfdev-deps.tgz")
+		ioutil.WriteFile(depsPath, []byte("some-deps"), 0600)
+
+		cmd = &upgrade.Upgrade{
+			UI:          mockUI,
+			Workspace:   mockWorkspace,
+			Provisioner: mockProvisioner,
+			Analytics:   mockAnalytics,
+		}
+
+		mockUI.EXPECT().Say(gomock.Any(), gomock.Any()).AnyTimes()
+		mockUI.EXPECT().Say(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		mockUI.EXPECT().Say(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("redeploys the changed services", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(current, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockProvisioner.EXPECT().Deployments().Return([]string{"cf"}, nil),
+			mockWorkspace.EXPECT().StageUpgrade(depsPath).Return(next, nil),
+			mockWorkspace.EXPECT().DirectorChanged(),
+			mockWorkspace.EXPECT().ApplyUpgrade(),
+			mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, []workspace.Service{cf}, []string{"some-registry:5000"}),
+			mockWorkspace.EXPECT().CleanupUpgrade(),
+			mockAnalytics.EXPECT().Event(cfanalytics.UPGRADE, map[string]interface{}{"from": "1.0", "to": "2.0"}),
+		)
+
+		Expect(cmd.Execute(upgrade.Args{DepsPath: depsPath, Registries: []string{"some-registry:5000"}})).To(Succeed())
+	})
+
+	It("does nothing when no deployment changes", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(current, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockProvisioner.EXPECT().Deployments().Return([]string{"cf"}, nil),
+			mockWorkspace.EXPECT().StageUpgrade(depsPath).Return(current, nil),
+			mockWorkspace.EXPECT().DirectorChanged(),
+			mockWorkspace.EXPECT().CleanupUpgrade(),
+		)
+
+		Expect(cmd.Execute(upgrade.Args{DepsPath: depsPath})).To(Succeed())
+	})
+
+	It("redeploys the BOSH Director when its manifests changed", func() {
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(current, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockProvisioner.EXPECT().Deployments().Return([]string{"cf"}, nil),
+			mockWorkspace.EXPECT().StageUpgrade(depsPath).Return(current, nil),
+			mockWorkspace.EXPECT().DirectorChanged().Return(true, nil),
+			mockWorkspace.EXPECT().ApplyUpgrade(),
+			mockProvisioner.EXPECT().DeployBosh(),
+			mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, nil, nil),
+			mockWorkspace.EXPECT().CleanupUpgrade(),
+			mockAnalytics.EXPECT().Event(cfanalytics.UPGRADE, map[string]interface{}{"from": "1.0", "to": "1.0"}),
+		)
+
+		Expect(cmd.Execute(upgrade.Args{DepsPath: depsPath})).To(Succeed())
+	})
+
+	It("refuses assets with a different compatibility version", func() {
+		incompatible := next
+		incompatible.Version = "v6"
+
+		gomock.InOrder(
+			mockWorkspace.EXPECT().Metadata().Return(current, nil),
+			mockProvisioner.EXPECT().Ping(gomock.Any()),
+			mockProvisioner.EXPECT().Deployments().Return([]string{"cf"}, nil),
+			mockWorkspace.EXPECT().StageUpgrade(depsPath).Return(incompatible, nil),
+			mockWorkspace.EXPECT().CleanupUpgrade(),
+		)
+
+		Expect(cmd.Execute(upgrade.Args{DepsPath: depsPath})).To(MatchError(ContainSubstring("cannot be upgraded to in place")))
+	})
+
+	Context("when the deployment fails", func() {
+		It("restores the previous assets and deployments", func() {
+			registries := []string{"some-registry:5000"}
+
+			gomock.InOrder(
+				mockWorkspace.EXPECT().Metadata().Return(current, nil),
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockProvisioner.EXPECT().Deployments().Return([]string{"cf"}, nil),
+				mockWorkspace.EXPECT().StageUpgrade(depsPath).Return(next, nil),
+				mockWorkspace.EXPECT().DirectorChanged(),
+				mockWorkspace.EXPECT().ApplyUpgrade(),
+				mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, []workspace.Service{cf}, registries).Return(errors.New("some-error")),
+				mockWorkspace.EXPECT().RollbackUpgrade(),
+				mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, []workspace.Service{cf}, registries),
+				mockWorkspace.EXPECT().CleanupUpgrade(),
+			)
+
+			err := cmd.Execute(upgrade.Args{DepsPath: depsPath, Registries: registries})
+			Expect(err).To(MatchError(ContainSubstring("the previous deployments were restored")))
+		})
+
+		It("restores the previous BOSH Director without redeploying the services", func() {
+			gomock.InOrder(
+				mockWorkspace.EXPECT().Metadata().Return(current, nil),
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockProvisioner.EXPECT().Deployments().Return([]string{"cf"}, nil),
+				mockWorkspace.EXPECT().StageUpgrade(depsPath).Return(next, nil),
+				mockWorkspace.EXPECT().DirectorChanged().Return(true, nil),
+				mockWorkspace.EXPECT().ApplyUpgrade(),
+				mockProvisioner.EXPECT().DeployBosh().Return(errors.New("some-error")),
+				mockWorkspace.EXPECT().RollbackUpgrade(),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockWorkspace.EXPECT().CleanupUpgrade(),
+			)
+
+			err := cmd.Execute(upgrade.Args{DepsPath: depsPath})
+			Expect(err).To(MatchError(ContainSubstring("Failed to deploy the BOSH Director")))
+		})
+	})
+
+	Context("when the deployment is cancelled", func() {
+		It("restores the previous assets and deployments", func() {
+			exit := make(chan struct{})
+			cmd.Exit = exit
+
+			gomock.InOrder(
+				mockWorkspace.EXPECT().Metadata().Return(current, nil),
+				mockProvisioner.EXPECT().Ping(gomock.Any()),
+				mockProvisioner.EXPECT().Deployments().Return([]string{"cf"}, nil),
+				mockWorkspace.EXPECT().StageUpgrade(depsPath).Return(next, nil),
+				mockWorkspace.EXPECT().DirectorChanged(),
+				mockWorkspace.EXPECT().ApplyUpgrade(),
+				mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, []workspace.Service{cf}, nil).DoAndReturn(
+					func(ctx context.Context, _ provision.UI, _ []workspace.Service, _ []string) error {
+						close(exit)
+						<-ctx.Done()
+						return ctx.Err()
+					}),
+				mockWorkspace.EXPECT().RollbackUpgrade(),
+				mockProvisioner.EXPECT().DeployServices(gomock.Any(), mockUI, []workspace.Service{cf}, nil).DoAndReturn(
+					func(ctx context.Context, _ provision.UI, _ []workspace.Service, _ []string) error {
+						return ctx.Err()
+					}),
+				mockWorkspace.EXPECT().CleanupUpgrade(),
+			)
+
+			err := cmd.Execute(upgrade.Args{DepsPath: depsPath})
+			Expect(err).To(MatchError(ContainSubstring("The upgrade was cancelled, the previous deployments were restored")))
+		})
+	})
+})
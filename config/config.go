@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
+	"time"
 
 	"code.cloudfoundry.org/cfdev/resource"
 	"runtime"
@@ -34,6 +35,17 @@ var (
 	buildVersion string
 )
 
+// RootlessProxy is the SOCKS proxy to the networks
+// of CF Dev when the VM runs without root privileges.
+const RootlessProxy = "127.0.0.1:1080"
+
+// HostKeyFile is the file in the state directory
+// that pins the SSH host key of the VM.
+const HostKeyFile = "vm_host_key"
+
+// DefaultDiskSize is the size of the disk of the VM in GB, unless chosen with 'cf dev start --disk'.
+const DefaultDiskSize = 120
+
 type Config struct {
 	BoshDirectorIP         string
 	CFRouterIP             string
@@ -55,6 +67,9 @@ type Config struct {
 	ServicesDir            string
 	DaemonDir              string
 	CFDomain               string
+	ProbeHost              string
+	Rootless               bool
+	ShutdownTimeout        time.Duration
 }
 
 func NewConfig() (Config, error) {
@@ -91,9 +106,47 @@ func NewConfig() (Config, error) {
 		BuildVersion:           buildVersion,
 		AnalyticsKey:           analytixKey,
 		CFDomain:               "dev.cfdev.sh",
+		ProbeHost:              probeHost(),
+		Rootless:               rootless(cfdevHome),
+		ShutdownTimeout:        shutdownTimeout(),
 	}, nil
 }
 
+// rootless tells whether the VM runs without root privileges on Linux.
+// It is chosen with CFDEV_ROOTLESS, and kept for the commands run
+// against an environment started in that mode.
+func rootless(cfdevHome string) bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+
+	if value := os.Getenv("CFDEV_ROOTLESS"); value != "" {
+		enabled, _ := strconv.ParseBool(value)
+		return enabled
+	}
+
+	_, err := os.Stat(filepath.Join(cfdevHome, "state", "linuxkit", "rootless"))
+	return err == nil
+}
+
+// shutdownTimeout is how long the guest is given to shut down
+// before its VM is killed. It is set with CFDEV_SHUTDOWN_TIMEOUT,
+// as a duration such as 90s or 2m.
+func shutdownTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(os.Getenv("CFDEV_SHUTDOWN_TIMEOUT")); err == nil && timeout >= 0 {
+		return timeout
+	}
+
+	return time.Minute
+}
+
+// probeHost is the host the VM must be able to reach before the
+// BOSH Director is deployed, when no proxy is configured. It is
+// only set with CFDEV_PROBE_HOST, as CF Dev also starts offline.
+func probeHost() string {
+	return os.Getenv("CFDEV_PROBE_HOST")
+}
+
 func aToUint64(a string) uint64 {
 	i, err := strconv.ParseUint(a, 10, 64)
 	if err != nil {
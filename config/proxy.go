@@ -52,3 +52,31 @@ func IsBehindProxy() bool {
 		os.Getenv("HTTPS_PROXY") != "" ||
 		os.Getenv("https_proxy") != ""
 }
+
+// CFProxy is the proxy the HTTP clients of CF Dev reach its
+// networks through, which is only needed in rootless mode.
+func (c *Config) CFProxy() string {
+	if !c.Rootless {
+		return ""
+	}
+
+	return "socks5://" + RootlessProxy
+}
+
+// ProxyEnvs send the HTTP clients run by CF Dev, such as the cf CLI,
+// through the proxy, including for the domain and the networks of CF Dev.
+func ProxyEnvs(proxy string) []string {
+	if proxy == "" {
+		return nil
+	}
+
+	noProxy := "localhost,127.0.0.1"
+	return []string{
+		"http_proxy=" + proxy,
+		"HTTP_PROXY=" + proxy,
+		"https_proxy=" + proxy,
+		"HTTPS_PROXY=" + proxy,
+		"no_proxy=" + noProxy,
+		"NO_PROXY=" + noProxy,
+	}
+}
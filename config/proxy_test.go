@@ -64,3 +64,14 @@ var _ = Describe("BuildProxyConfig", func() {
 		})
 	})
 })
+
+var _ = Describe("CFProxy", func() {
+	It("sends the HTTP clients through the SOCKS proxy in rootless mode only", func() {
+		cfg := config.Config{Rootless: true}
+		Expect(config.ProxyEnvs(cfg.CFProxy())).To(ContainElement("https_proxy=socks5://127.0.0.1:1080"))
+		Expect(config.ProxyEnvs(cfg.CFProxy())).To(ContainElement("NO_PROXY=localhost,127.0.0.1"))
+
+		cfg.Rootless = false
+		Expect(config.ProxyEnvs(cfg.CFProxy())).To(BeEmpty())
+	})
+})
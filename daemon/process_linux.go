@@ -0,0 +1,159 @@
+package daemon
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ProcessRunner runs daemons as background processes of the current
+// user, for the hosts where CF Dev cannot install system services.
+type ProcessRunner struct {
+	dir         string
+	StopTimeout time.Duration
+}
+
+func NewProcessRunner(cfg config.Config) *ProcessRunner {
+	return &ProcessRunner{
+		dir:         cfg.DaemonDir,
+		StopTimeout: 20 * time.Second,
+	}
+}
+
+func (p *ProcessRunner) AddDaemon(spec DaemonSpec) error {
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(p.specPath(spec.Label), data, 0600)
+}
+
+func (p *ProcessRunner) RemoveDaemon(label string) error {
+	if err := os.Remove(p.specPath(label)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (p *ProcessRunner) Start(label string) error {
+	data, err := ioutil.ReadFile(p.specPath(label))
+	if err != nil {
+		return fmt.Errorf("failed to start '%s': %s", label, err)
+	}
+
+	var spec DaemonSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+
+	logFile, err := os.OpenFile(spec.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(spec.Program, spec.ProgramArguments...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.Env = os.Environ()
+	for key, value := range spec.EnvironmentVariables {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	// The daemon outlives the cf CLI, and must not
+	// receive the signals sent to its process group
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start '%s': %s", label, err)
+	}
+
+	err = ioutil.WriteFile(p.pidPath(label), []byte(strconv.Itoa(cmd.Process.Pid)), 0600)
+	if err != nil {
+		return err
+	}
+
+	return cmd.Process.Release()
+}
+
+func (p *ProcessRunner) Stop(label string) error {
+	pid, running := p.pid(label)
+	if !running {
+		os.Remove(p.pidPath(label))
+		return nil
+	}
+
+	syscall.Kill(pid, syscall.SIGTERM)
+
+	deadline := time.Now().Add(p.StopTimeout)
+	for time.Now().Before(deadline) {
+		if !alive(pid) {
+			return os.Remove(p.pidPath(label))
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if err := syscall.Kill(pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return fmt.Errorf("failed to stop '%s': %s", label, err)
+	}
+
+	return os.Remove(p.pidPath(label))
+}
+
+func (p *ProcessRunner) IsRunning(label string) (bool, error) {
+	_, running := p.pid(label)
+	return running, nil
+}
+
+func (p *ProcessRunner) pid(label string) (int, bool) {
+	data, err := ioutil.ReadFile(p.pidPath(label))
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+
+	return pid, alive(pid)
+}
+
+// alive tells whether the process exists and is not a zombie
+// waiting to be reaped by the process that started it.
+func alive(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+
+	fields := strings.Fields(string(data[strings.LastIndex(string(data), ")")+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
+
+func (p *ProcessRunner) specPath(label string) string {
+	return filepath.Join(p.dir, label+".yml")
+}
+
+func (p *ProcessRunner) pidPath(label string) string {
+	return filepath.Join(p.dir, label+".pid")
+}
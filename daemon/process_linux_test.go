@@ -0,0 +1,66 @@
+package daemon_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProcessRunner", func() {
+	var (
+		tmpDir string
+		runner *daemon.ProcessRunner
+		spec   daemon.DaemonSpec
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-process-runner-")
+		Expect(err).NotTo(HaveOccurred())
+
+		runner = daemon.NewProcessRunner(config.Config{DaemonDir: filepath.Join(tmpDir, "daemons")})
+		runner.StopTimeout = 5 * time.Second
+
+		spec = daemon.DaemonSpec{
+			Label:                "org.cloudfoundry.cfdev.test",
+			Program:              "/bin/sh",
+			ProgramArguments:     []string{"-c", "echo started $GREETING; exec sleep 60"},
+			EnvironmentVariables: map[string]string{"GREETING": "hello"},
+			LogPath:              filepath.Join(tmpDir, "test.log"),
+		}
+	})
+
+	AfterEach(func() {
+		runner.Stop(spec.Label)
+		os.RemoveAll(tmpDir)
+	})
+
+	It("runs the daemon in the background until it is stopped", func() {
+		Expect(runner.AddDaemon(spec)).To(Succeed())
+		Expect(runner.IsRunning(spec.Label)).To(BeFalse())
+
+		Expect(runner.Start(spec.Label)).To(Succeed())
+		Expect(runner.IsRunning(spec.Label)).To(BeTrue())
+		Eventually(func() (string, error) {
+			data, err := ioutil.ReadFile(spec.LogPath)
+			return string(data), err
+		}).Should(Equal("started hello\n"))
+
+		Expect(runner.Stop(spec.Label)).To(Succeed())
+		Expect(runner.IsRunning(spec.Label)).To(BeFalse())
+
+		Expect(runner.RemoveDaemon(spec.Label)).To(Succeed())
+		Expect(runner.Start(spec.Label)).To(MatchError(ContainSubstring("failed to start 'org.cloudfoundry.cfdev.test'")))
+	})
+
+	It("does nothing when stopping or removing an unknown daemon", func() {
+		Expect(runner.Stop("unknown")).To(Succeed())
+		Expect(runner.RemoveDaemon("unknown")).To(Succeed())
+	})
+})
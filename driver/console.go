@@ -0,0 +1,34 @@
+package driver
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// ConsoleSocket is the unix socket the serial console of the VM is served on.
+func ConsoleSocket(cfg config.Config) string {
+	return filepath.Join(cfg.StateLinuxkit, "console.sock")
+}
+
+// ConsoleLog is the file the serial console of the VM is logged to.
+func ConsoleLog(cfg config.Config) string {
+	return filepath.Join(cfg.LogDir, "console.log")
+}
+
+// ConsoleTail returns the last lines logged by the serial console
+// of the VM, or an empty string when there are none.
+func ConsoleTail(cfg config.Config, lines int) string {
+	data, err := ioutil.ReadFile(ConsoleLog(cfg))
+	if err != nil {
+		return ""
+	}
+
+	all := strings.Split(strings.TrimRight(strings.Replace(string(data), "\r", "", -1), "\n"), "\n")
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+
+	return strings.TrimSpace(strings.Join(all, "\n"))
+}
@@ -1,12 +1,22 @@
 package driver
 
-import "code.cloudfoundry.org/cfdev/daemon"
+import (
+	"code.cloudfoundry.org/cfdev/daemon"
+	"context"
+	"net"
+)
 
 const (
 	VMName          = "cfdev"
 	VpnKitLabel     = "org.cloudfoundry.cfdev.vpnkit"
 	LinuxKitLabel   = "org.cloudfoundry.cfdev.linuxkit"
 	ContainerSubnet = "10.144.0.0/16"
+	KVMBridgeIP     = "192.168.107.1"
+	KVMGuestIP      = "192.168.107.2"
+
+	// The addresses of QEMU user networking, used by the rootless KVM driver
+	RootlessGuestIP      = "10.0.2.15"
+	RootlessNameserverIP = "10.0.2.3"
 )
 
 type UI interface {
@@ -24,7 +34,34 @@ type DaemonRunner interface {
 type Driver interface {
 	CheckRequirements() error
 	Prestart() error
-	Start(cpus int, memory int, efiPath string) error
+	Start(cpus int, memory int, disk int, efiPath string) error
 	Stop() error
 	IsRunning() (bool, error)
-}
\ No newline at end of file
+	Address(ctx context.Context) (net.IP, error)
+}
+
+// DiskUsage is the size of the disk of the VM, and the space it takes on the host.
+type DiskUsage struct {
+	Path      string
+	Size      uint64
+	Allocated uint64
+}
+
+// DiskManager is implemented by the drivers whose
+// VM disk can be inspected and compacted from the host.
+type DiskManager interface {
+	DiskUsage() (DiskUsage, error)
+	CompactDisk() error
+}
+
+// HypervisorSelector is implemented by the drivers that can run
+// the VM with more than one hypervisor, chosen with cf dev start --driver.
+type HypervisorSelector interface {
+	SelectHypervisor(name string) error
+}
+
+// PortUser is implemented by the drivers that listen on ports of the host
+// for the VM, so that the ports in use are reported before it starts.
+type PortUser interface {
+	HostPorts() []string
+}
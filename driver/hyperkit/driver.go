@@ -7,7 +7,9 @@ import (
 	e "code.cloudfoundry.org/cfdev/errors"
 	"code.cloudfoundry.org/cfdev/pkg/cfdevd/client"
 	"code.cloudfoundry.org/cfdev/runner"
+	"context"
 	"fmt"
+	"net"
 	"path"
 	"path/filepath"
 	"strings"
@@ -62,9 +64,9 @@ func (d *Hyperkit) Prestart() error {
 	return nil
 }
 
-func (d *Hyperkit) Start(cpus int, memory int, efiPath string) error {
+func (d *Hyperkit) Start(cpus int, memory int, disk int, efiPath string) error {
 	d.UI.Say("Creating the VM...")
-	err := d.DaemonRunner.AddDaemon(d.daemonSpec(cpus, memory, efiPath))
+	err := d.DaemonRunner.AddDaemon(d.daemonSpec(cpus, memory, disk, efiPath))
 	if err != nil {
 		return e.SafeWrap(err, "creating the vm")
 	}
@@ -136,7 +138,22 @@ func (d *Hyperkit) IsRunning() (bool, error) {
 	return d.DaemonRunner.IsRunning(driver.LinuxKitLabel)
 }
 
-func (d *Hyperkit) daemonSpec(cpus, mem int, efiPath string) daemon.DaemonSpec {
+// Address returns the loopback address, as
+// VPNKit forwards the ports of the VM to the loopback interface.
+func (d *Hyperkit) Address(ctx context.Context) (net.IP, error) {
+	return net.IPv4(127, 0, 0, 1), nil
+}
+
+// HostPorts are the ports of the CF router on its address on the host.
+func (d *Hyperkit) HostPorts() []string {
+	return []string{
+		net.JoinHostPort(d.Config.CFRouterIP, "80"),
+		net.JoinHostPort(d.Config.CFRouterIP, "443"),
+		net.JoinHostPort(d.Config.CFRouterIP, "2222"),
+	}
+}
+
+func (d *Hyperkit) daemonSpec(cpus, mem, disk int, efiPath string) daemon.DaemonSpec {
 	var (
 		linuxkit       = filepath.Join(d.Config.BinaryDir, "linuxkit")
 		hyperkit       = filepath.Join(d.Config.BinaryDir, "hyperkit")
@@ -146,7 +163,7 @@ func (d *Hyperkit) daemonSpec(cpus, mem int, efiPath string) daemon.DaemonSpec {
 		vpnkitPortSock = filepath.Join(d.Config.VpnKitStateDir, "vpnkit_port.sock")
 		diskArgs       = []string{
 			"type=qcow",
-			"size=120G",
+			fmt.Sprintf("size=%dG", disk),
 			"trim=true",
 			fmt.Sprintf("qcow-tool=%s", qcowtool),
 			"qcow-onflush=os",
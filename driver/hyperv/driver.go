@@ -4,6 +4,8 @@ import (
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/driver"
 	e "code.cloudfoundry.org/cfdev/errors"
+	"context"
+	"net"
 )
 
 //go:generate mockgen -package mocks -destination mocks/runner.go code.cloudfoundry.org/cfdev/driver/hyperv Runner
@@ -55,9 +57,9 @@ func (d *HyperV) Prestart() error {
 	return nil
 }
 
-func (d *HyperV) Start(cpus int, memory int, efiPath string) error {
+func (d *HyperV) Start(cpus int, memory int, disk int, efiPath string) error {
 	d.UI.Say("Creating the VM...")
-	vmGUID, err := d.CreateVM(driver.VMName, cpus, memory, efiPath)
+	vmGUID, err := d.CreateVM(driver.VMName, cpus, memory, disk, efiPath)
 	if err != nil {
 		return e.SafeWrap(err, "creating the vm")
 	}
@@ -126,3 +128,18 @@ func (d *HyperV) Stop() error {
 func (d *HyperV) IsRunning() (bool, error) {
 	return d.IsVMRunning(driver.VMName)
 }
+
+// Address returns the loopback address, as
+// VPNKit forwards the ports of the VM to the loopback interface.
+func (d *HyperV) Address(ctx context.Context) (net.IP, error) {
+	return net.IPv4(127, 0, 0, 1), nil
+}
+
+// HostPorts are the ports of the CF router on its address on the host.
+func (d *HyperV) HostPorts() []string {
+	return []string{
+		net.JoinHostPort(d.Config.CFRouterIP, "80"),
+		net.JoinHostPort(d.Config.CFRouterIP, "443"),
+		net.JoinHostPort(d.Config.CFRouterIP, "2222"),
+	}
+}
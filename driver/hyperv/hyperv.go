@@ -6,7 +6,7 @@ import (
 	"strings"
 )
 
-func (d *HyperV) CreateVM(name string, cpus int, memory int, efiPath string) (string, error) {
+func (d *HyperV) CreateVM(name string, cpus int, memory int, disk int, efiPath string) (string, error) {
 	var (
 		cfDevVHD    = filepath.Join(d.Config.StateDir, "disk.vhdx")
 	)
@@ -42,6 +42,13 @@ func (d *HyperV) CreateVM(name string, cpus int, memory int, efiPath string) (st
 		fmt.Printf("failed to remove network adapter: %s", err)
 	}
 
+	// The disk only ever grows, Hyper-V cannot shrink it below the space in use
+	command = fmt.Sprintf(`if ((Hyper-V\Get-VHD -Path "%[1]s").Size -lt %[2]dGB) { Hyper-V\Resize-VHD -Path "%[1]s" -SizeBytes %[2]dGB }`, cfDevVHD, disk)
+	_, err = d.Powershell.Output(command)
+	if err != nil {
+		return "", fmt.Errorf("resizing vhd %s to %dGB: %s", cfDevVHD, disk, err)
+	}
+
 	command = fmt.Sprintf("Hyper-V\\Add-VMHardDiskDrive -VMName %s "+
 		`-Path "%s"`, name, cfDevVHD)
 	_, err = d.Powershell.Output(command)
@@ -0,0 +1,72 @@
+package kvm
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Address returns the address the DHCP server of the bridge leased to
+// the VM. It is cached for the lifetime of the VM: Start and Stop remove
+// the cache along with the leases, so that it never outlives the VM.
+func (d *KVM) Address(ctx context.Context) (net.IP, error) {
+	if data, err := ioutil.ReadFile(d.addressPath()); err == nil {
+		if ip := net.ParseIP(strings.TrimSpace(string(data))); ip != nil {
+			return ip, nil
+		}
+	}
+
+	for {
+		if ip := d.leasedAddress(); ip != nil {
+			return ip, ioutil.WriteFile(d.addressPath(), []byte(ip.String()), 0644)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("the VM did not get an address from the DHCP server: %s", ctx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// Address returns the loopback address, as the
+// ports of the VM are forwarded to the loopback interface.
+func (d *Rootless) Address(ctx context.Context) (net.IP, error) {
+	return net.IPv4(127, 0, 0, 1), nil
+}
+
+// leasedAddress reads the lease of the MAC address of the VM
+// from the dnsmasq lease file: <expiry> <mac> <ip> <hostname> <client id>
+func (d *KVM) leasedAddress() net.IP {
+	data, err := ioutil.ReadFile(d.leasesPath())
+	if err != nil {
+		return nil
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && strings.EqualFold(fields[1], guestMAC) {
+			return net.ParseIP(fields[2])
+		}
+	}
+
+	return nil
+}
+
+func (d *KVM) forgetAddress() {
+	os.Remove(d.addressPath())
+	os.Remove(d.leasesPath())
+}
+
+func (d *KVM) addressPath() string {
+	return filepath.Join(d.Config.StateLinuxkit, "ip")
+}
+
+func (d *KVM) leasesPath() string {
+	return filepath.Join(d.Config.StateLinuxkit, "dnsmasq.leases")
+}
@@ -0,0 +1,94 @@
+package kvm_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/driver/kvm"
+	"code.cloudfoundry.org/cfdev/driver/kvm/mocks"
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Address", func() {
+	var (
+		mockController   *gomock.Controller
+		mockDaemonRunner *mocks.MockDaemonRunner
+		tmpDir           string
+		cfg              config.Config
+		d                *kvm.KVM
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockDaemonRunner = mocks.NewMockDaemonRunner(mockController)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-kvm-address-")
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg = config.Config{StateLinuxkit: filepath.Join(tmpDir, "state", "linuxkit")}
+		Expect(os.MkdirAll(cfg.StateLinuxkit, 0755)).To(Succeed())
+
+		d = &kvm.KVM{
+			UI:           fakeUI{},
+			Config:       cfg,
+			DaemonRunner: mockDaemonRunner,
+			SudoShell:    mocks.NewMockRunner(mockController),
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(tmpDir)
+	})
+
+	writeLeases := func(contents string) {
+		Expect(ioutil.WriteFile(filepath.Join(cfg.StateLinuxkit, "dnsmasq.leases"), []byte(contents), 0644)).To(Succeed())
+	}
+
+	It("waits for the DHCP server to lease an address to the VM, and caches it", func() {
+		go func() {
+			time.Sleep(time.Second)
+			writeLeases("0 02:aa:bb:cc:dd:ee 192.168.107.9 other *\n0 02:cf:de:00:00:02 192.168.107.2 cfdev 01:02:cf:de:00:00:02\n")
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		ip, err := d.Address(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ip.Equal(net.ParseIP("192.168.107.2"))).To(BeTrue())
+
+		cached, err := ioutil.ReadFile(filepath.Join(cfg.StateLinuxkit, "ip"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(cached)).To(Equal("192.168.107.2"))
+	})
+
+	It("fails when the VM gets no address in time", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := d.Address(ctx)
+		Expect(err).To(MatchError(ContainSubstring("the VM did not get an address from the DHCP server")))
+	})
+
+	It("forgets the address of the VM when it stops", func() {
+		writeLeases("0 02:cf:de:00:00:02 192.168.107.2 cfdev *\n")
+		Expect(ioutil.WriteFile(filepath.Join(cfg.StateLinuxkit, "ip"), []byte("192.168.107.2"), 0644)).To(Succeed())
+
+		mockDaemonRunner.EXPECT().Stop(gomock.Any()).AnyTimes()
+		mockDaemonRunner.EXPECT().RemoveDaemon(gomock.Any()).AnyTimes()
+		d.SudoShell.(*mocks.MockRunner).EXPECT().Run(gomock.Any()).AnyTimes()
+
+		Expect(d.Stop()).To(Succeed())
+		Expect(filepath.Join(cfg.StateLinuxkit, "ip")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(cfg.StateLinuxkit, "dnsmasq.leases")).NotTo(BeAnExistingFile())
+	})
+})
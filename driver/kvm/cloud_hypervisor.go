@@ -0,0 +1,112 @@
+package kvm
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/driver"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// The hypervisors the KVM driver runs the VM with
+const (
+	QEMU            = "qemu"
+	CloudHypervisor = "cloud-hypervisor"
+)
+
+const (
+	cloudHypervisorBinary   = "cloud-hypervisor"
+	cloudHypervisorFirmware = "CLOUDHV.fd"
+)
+
+// cloudHypervisorSpec runs the VM with Cloud Hypervisor, which boots it
+// faster than QEMU and with less memory overhead. It serves no console
+// socket, the serial console of the VM is only logged. Its API is
+// served on a unix socket, to shut the VM down.
+func (h Host) cloudHypervisorSpec(cfg config.Config, cpus int, mem int, efiPath string, net string) (daemon.DaemonSpec, error) {
+	cloudHypervisor, err := h.LookPath(cloudHypervisorBinary)
+	if err != nil {
+		return daemon.DaemonSpec{}, err
+	}
+
+	firmware, err := h.cloudHypervisorFirmware(cfg.CFDevHome)
+	if err != nil {
+		return daemon.DaemonSpec{}, err
+	}
+
+	return daemon.DaemonSpec{
+		Label:   driver.LinuxKitLabel,
+		Program: cloudHypervisor,
+		ProgramArguments: []string{
+			"--api-socket", "path=" + apiSocket(cfg),
+			"--firmware", firmware,
+			"--cpus", fmt.Sprintf("boot=%d", cpus),
+			"--memory", fmt.Sprintf("size=%dM", mem),
+			"--disk", "path=" + efiPath + ",readonly=on", "path=" + diskPath(cfg),
+			"--net", net,
+			"--rng", "src=/dev/urandom",
+			"--serial", "file=" + driver.ConsoleLog(cfg),
+			"--console", "off",
+		},
+		LogPath: path.Join(cfg.LogDir, "cloud-hypervisor.log"),
+	}, nil
+}
+
+// cloudHypervisorFirmware finds the UEFI firmware Cloud Hypervisor boots
+// the EFI ISO with, in the CF Dev home first and then where packages install it.
+func (h Host) cloudHypervisorFirmware(cfdevHome string) (string, error) {
+	for _, dir := range []string{cfdevHome, "/usr/share/cloud-hypervisor", "/usr/local/share/cloud-hypervisor"} {
+		if info, err := os.Stat(h.path(dir, cloudHypervisorFirmware)); err == nil && !info.IsDir() {
+			return filepath.Join(dir, cloudHypervisorFirmware), nil
+		}
+	}
+
+	return "", fmt.Errorf("the %s firmware of Cloud Hypervisor was not found", cloudHypervisorFirmware)
+}
+
+// SelectHypervisor chooses the hypervisor the next start runs the VM with.
+func (d *KVM) SelectHypervisor(name string) error {
+	switch name {
+	case QEMU, CloudHypervisor:
+		d.Hypervisor = name
+		return nil
+	default:
+		return fmt.Errorf("unknown driver '%s', expected %s or %s", name, QEMU, CloudHypervisor)
+	}
+}
+
+// SelectHypervisor only accepts QEMU, as the tap device
+// of Cloud Hypervisor needs root privileges.
+func (d *Rootless) SelectHypervisor(name string) error {
+	switch name {
+	case QEMU:
+		return nil
+	case CloudHypervisor:
+		return fmt.Errorf("the %s driver needs root privileges for its network, it cannot run with CFDEV_ROOTLESS", CloudHypervisor)
+	default:
+		return fmt.Errorf("unknown driver '%s', expected %s or %s", name, QEMU, CloudHypervisor)
+	}
+}
+
+// hypervisor returns the hypervisor an environment was started with,
+// so that the commands run against it keep using it.
+func hypervisor(cfg config.Config) string {
+	data, err := ioutil.ReadFile(hypervisorPath(cfg))
+	if err != nil {
+		return QEMU
+	}
+
+	if name := strings.TrimSpace(string(data)); name == CloudHypervisor {
+		return name
+	}
+
+	return QEMU
+}
+
+func hypervisorPath(cfg config.Config) string {
+	return filepath.Join(cfg.StateLinuxkit, "hypervisor")
+}
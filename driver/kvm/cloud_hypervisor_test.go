@@ -0,0 +1,122 @@
+package kvm_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/driver"
+	"code.cloudfoundry.org/cfdev/driver/kvm"
+	"code.cloudfoundry.org/cfdev/driver/kvm/mocks"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cloud Hypervisor", func() {
+	var (
+		mockController   *gomock.Controller
+		mockRunner       *mocks.MockRunner
+		mockDaemonRunner *mocks.MockDaemonRunner
+		root             string
+		cfg              config.Config
+		d                *kvm.KVM
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockRunner = mocks.NewMockRunner(mockController)
+		mockDaemonRunner = mocks.NewMockDaemonRunner(mockController)
+
+		var err error
+		root, err = ioutil.TempDir("", "cfdev-kvm-cloud-hypervisor-")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(root, "proc", "sys", "net", "ipv4"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(root, "proc", "sys", "net", "ipv4", "ip_forward"), []byte("1\n"), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(root, "usr", "sbin"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(root, "usr", "sbin", "dnsmasq"), nil, 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(root, "home", ".cfdev"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(root, "home", ".cfdev", "CLOUDHV.fd"), nil, 0644)).To(Succeed())
+
+		cfg = config.Config{
+			CFDevHome:     "/home/.cfdev",
+			StateLinuxkit: filepath.Join(root, "state", "linuxkit"),
+			LogDir:        filepath.Join(root, "log"),
+			BinaryDir:     filepath.Join(root, "bin"),
+		}
+
+		d = &kvm.KVM{
+			UI:           fakeUI{},
+			Config:       cfg,
+			DaemonRunner: mockDaemonRunner,
+			SudoShell:    mockRunner,
+			Host: kvm.Host{
+				Root: root,
+				LookPath: func(file string) (string, error) {
+					if file == "qemu-img" || file == "cloud-hypervisor" {
+						return "/usr/bin/" + file, nil
+					}
+					return "", fmt.Errorf("%s not found", file)
+				},
+				Output: func(name string, arg ...string) ([]byte, error) {
+					return nil, nil
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(root)
+	})
+
+	It("runs the VM with cloud-hypervisor on the network of the KVM driver", func() {
+		Expect(d.SelectHypervisor("cloud-hypervisor")).To(Succeed())
+
+		mockRunner.EXPECT().Run(gomock.Any()).AnyTimes()
+		mockDaemonRunner.EXPECT().AddDaemon(gomock.Any()).Do(func(spec daemon.DaemonSpec) {
+			Expect(spec.Label).To(Equal("org.cloudfoundry.cfdev.dhcp"))
+		})
+		mockDaemonRunner.EXPECT().Start("org.cloudfoundry.cfdev.dhcp")
+		mockDaemonRunner.EXPECT().AddDaemon(gomock.Any()).Do(func(spec daemon.DaemonSpec) {
+			Expect(spec.Label).To(Equal(driver.LinuxKitLabel))
+			Expect(spec.Program).To(Equal("/usr/bin/cloud-hypervisor"))
+			Expect(spec.ProgramArguments).To(Equal([]string{
+				"--api-socket", "path=" + filepath.Join(cfg.StateLinuxkit, "cloud-hypervisor.sock"),
+				"--firmware", "/home/.cfdev/CLOUDHV.fd",
+				"--cpus", "boot=2",
+				"--memory", "size=4096M",
+				"--disk", "path=/some/cfdev-efi.iso,readonly=on", "path=" + filepath.Join(cfg.StateLinuxkit, "disk.qcow2"),
+				"--net", "tap=cfdevtap0,mac=02:cf:de:00:00:02",
+				"--rng", "src=/dev/urandom",
+				"--serial", "file=" + filepath.Join(cfg.LogDir, "console.log"),
+				"--console", "off",
+			}))
+		})
+		mockDaemonRunner.EXPECT().Start(driver.LinuxKitLabel)
+
+		Expect(d.Start(2, 4096, 120, "/some/cfdev-efi.iso")).To(Succeed())
+		Expect(ioutil.ReadFile(filepath.Join(cfg.StateLinuxkit, "hypervisor"))).To(Equal([]byte("cloud-hypervisor")))
+	})
+
+	It("rejects unknown drivers", func() {
+		Expect(d.SelectHypervisor("firecracker")).To(MatchError("unknown driver 'firecracker', expected qemu or cloud-hypervisor"))
+	})
+
+	It("keeps the environment on the hypervisor it was started with", func() {
+		Expect(os.MkdirAll(cfg.StateLinuxkit, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(cfg.StateLinuxkit, "hypervisor"), []byte("cloud-hypervisor"), 0600)).To(Succeed())
+
+		Expect(kvm.New(cfg, mockDaemonRunner, fakeUI{}).(*kvm.KVM).Hypervisor).To(Equal("cloud-hypervisor"))
+	})
+
+	It("cannot run cloud-hypervisor without root privileges", func() {
+		rootless := &kvm.Rootless{}
+		Expect(rootless.SelectHypervisor("qemu")).To(Succeed())
+		Expect(rootless.SelectHypervisor("cloud-hypervisor")).To(MatchError(ContainSubstring("needs root privileges")))
+	})
+})
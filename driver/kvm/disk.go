@@ -0,0 +1,84 @@
+package kvm
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var qcowMagic = []byte{'Q', 'F', 'I', 0xfb}
+
+func (d *KVM) DiskUsage() (driver.DiskUsage, error) {
+	return qcowUsage(diskPath(d.Config))
+}
+
+func (d *KVM) CompactDisk() error {
+	if running, err := d.IsRunning(); err != nil || running {
+		return errors.New("the VM must be stopped to compact its disk")
+	}
+
+	return d.Host.compactDisk(d.Config)
+}
+
+func (d *Rootless) DiskUsage() (driver.DiskUsage, error) {
+	return qcowUsage(diskPath(d.Config))
+}
+
+func (d *Rootless) CompactDisk() error {
+	if running, err := d.IsRunning(); err != nil || running {
+		return errors.New("the VM must be stopped to compact its disk")
+	}
+
+	return d.Host.compactDisk(d.Config)
+}
+
+// compactDisk copies the disk of the VM without the clusters that are
+// not allocated or only hold zeros, and replaces it with the copy.
+func (h Host) compactDisk(cfg config.Config) error {
+	var (
+		disk      = diskPath(cfg)
+		compacted = disk + ".compact"
+	)
+
+	if _, err := os.Stat(disk); err != nil {
+		return err
+	}
+
+	err := h.qemuImg("compacting the disk of the VM", "convert", "-O", "qcow2", disk, compacted)
+	if err != nil {
+		os.Remove(compacted)
+		return err
+	}
+
+	return os.Rename(compacted, disk)
+}
+
+// qcowUsage reads the virtual size of a qcow2 image from its header,
+// which can be read while QEMU runs, unlike with qemu-img info.
+func qcowUsage(path string) (driver.DiskUsage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return driver.DiskUsage{}, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 32)
+	if _, err := io.ReadFull(f, header); err != nil || string(header[:4]) != string(qcowMagic) {
+		return driver.DiskUsage{}, fmt.Errorf("%s is not a qcow2 image", path)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return driver.DiskUsage{}, err
+	}
+
+	return driver.DiskUsage{
+		Path:      path,
+		Size:      binary.BigEndian.Uint64(header[24:32]),
+		Allocated: allocatedSize(info),
+	}, nil
+}
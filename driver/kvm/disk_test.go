@@ -0,0 +1,130 @@
+package kvm_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/driver"
+	"code.cloudfoundry.org/cfdev/driver/kvm"
+	"code.cloudfoundry.org/cfdev/driver/kvm/mocks"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var errStop = errors.New("stop after creating the VM")
+
+var _ = Describe("Disk", func() {
+	var (
+		mockController   *gomock.Controller
+		mockDaemonRunner *mocks.MockDaemonRunner
+		tmpDir           string
+		diskPath         string
+		commands         [][]string
+		d                *kvm.Rootless
+	)
+
+	writeQcow := func(path string, size uint64) {
+		header := make([]byte, 512)
+		copy(header, []byte{'Q', 'F', 'I', 0xfb})
+		binary.BigEndian.PutUint64(header[24:32], size)
+		Expect(ioutil.WriteFile(path, header, 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockDaemonRunner = mocks.NewMockDaemonRunner(mockController)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-kvm-disk-")
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg := config.Config{
+			StateLinuxkit: filepath.Join(tmpDir, "state", "linuxkit"),
+			LogDir:        filepath.Join(tmpDir, "log"),
+		}
+		Expect(os.MkdirAll(cfg.StateLinuxkit, 0755)).To(Succeed())
+		diskPath = filepath.Join(cfg.StateLinuxkit, "disk.qcow2")
+
+		commands = nil
+		d = &kvm.Rootless{
+			UI:           fakeUI{},
+			Config:       cfg,
+			DaemonRunner: mockDaemonRunner,
+			Host: kvm.Host{
+				LookPath: func(file string) (string, error) { return "/usr/bin/" + file, nil },
+				Output: func(name string, arg ...string) ([]byte, error) {
+					commands = append(commands, append([]string{name}, arg...))
+					if len(arg) > 0 && arg[0] == "convert" {
+						writeQcow(arg[len(arg)-1], 120<<30)
+					}
+					return nil, nil
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("reads the size of the disk from its header", func() {
+		writeQcow(diskPath, 120<<30)
+
+		usage, err := d.DiskUsage()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage.Path).To(Equal(diskPath))
+		Expect(usage.Size).To(Equal(uint64(120 << 30)))
+		Expect(usage.Allocated).To(BeNumerically(">", 0))
+	})
+
+	It("fails when there is no disk", func() {
+		_, err := d.DiskUsage()
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("compacts the disk of a stopped VM into a copy that replaces it", func() {
+		writeQcow(diskPath, 120<<30)
+		mockDaemonRunner.EXPECT().IsRunning(driver.LinuxKitLabel).Return(false, nil)
+
+		Expect(d.CompactDisk()).To(Succeed())
+		Expect(commands).To(Equal([][]string{
+			{"/usr/bin/qemu-img", "convert", "-O", "qcow2", diskPath, diskPath + ".compact"},
+		}))
+		Expect(diskPath).To(BeAnExistingFile())
+		Expect(diskPath + ".compact").NotTo(BeAnExistingFile())
+	})
+
+	It("refuses to compact the disk of a running VM", func() {
+		mockDaemonRunner.EXPECT().IsRunning(driver.LinuxKitLabel).Return(true, nil)
+
+		Expect(d.CompactDisk()).To(MatchError("the VM must be stopped to compact its disk"))
+		Expect(commands).To(BeEmpty())
+	})
+
+	It("grows an existing disk when the VM starts with a larger one", func() {
+		writeQcow(diskPath, 120<<30)
+		mockDaemonRunner.EXPECT().AddDaemon(gomock.Any()).Do(func(spec daemon.DaemonSpec) {
+			Expect(spec.ProgramArguments).To(ContainElement(ContainSubstring("discard=unmap")))
+		}).Return(errStop)
+
+		Expect(d.Start(2, 4096, 200, "/some/cfdev-efi.iso")).To(MatchError(errStop))
+		Expect(commands).To(Equal([][]string{
+			{"/usr/bin/qemu-img", "resize", diskPath, "200G"},
+		}))
+	})
+
+	It("never shrinks an existing disk", func() {
+		writeQcow(diskPath, 120<<30)
+		mockDaemonRunner.EXPECT().AddDaemon(gomock.Any()).Return(errStop)
+
+		Expect(d.Start(2, 4096, 80, "/some/cfdev-efi.iso")).To(MatchError(errStop))
+		Expect(commands).To(BeEmpty())
+	})
+})
@@ -0,0 +1,18 @@
+// +build !windows
+
+package kvm
+
+import (
+	"os"
+	"syscall"
+)
+
+// allocatedSize is the space a sparse file takes on its file system.
+func allocatedSize(info os.FileInfo) uint64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return uint64(info.Size())
+	}
+
+	return uint64(stat.Blocks) * 512
+}
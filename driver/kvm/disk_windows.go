@@ -0,0 +1,7 @@
+package kvm
+
+import "os"
+
+func allocatedSize(info os.FileInfo) uint64 {
+	return uint64(info.Size())
+}
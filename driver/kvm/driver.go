@@ -6,21 +6,32 @@ import (
 	"code.cloudfoundry.org/cfdev/driver"
 	"code.cloudfoundry.org/cfdev/runner"
 	"fmt"
-	"path"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 )
 
 var (
 	tapDevice  = "cfdevtap0"
-	bridgeName = "virbr0"
+	bridgeName = "cfdev0"
 )
 
+//go:generate mockgen -package mocks -destination mocks/runner.go code.cloudfoundry.org/cfdev/driver/kvm Runner
+type Runner interface {
+	Run(args ...string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/daemonrunner.go code.cloudfoundry.org/cfdev/driver DaemonRunner
+
 type KVM struct {
 	UI           driver.UI
 	Config       config.Config
 	DaemonRunner driver.DaemonRunner
-	SudoShell    *runner.Sudo
+	SudoShell    Runner
+	Host         Host
+	Hypervisor   string
 }
 
 func New(
@@ -33,12 +44,17 @@ func New(
 		Config:       cfg,
 		DaemonRunner: daemonRunner,
 		SudoShell:    &runner.Sudo{},
+		Host:         NewHost(),
+		Hypervisor:   hypervisor(cfg),
 	}
 }
 
 func (d *KVM) CheckRequirements() error {
-	// TODO:
-	return nil
+	if d.hypervisor() == CloudHypervisor {
+		return d.Host.CheckCloudHypervisorRequirements(d.Config.CFDevHome)
+	}
+
+	return d.Host.CheckRequirements()
 }
 
 func (d *KVM) Prestart() error {
@@ -46,11 +62,33 @@ func (d *KVM) Prestart() error {
 	return nil
 }
 
-func (d *KVM) Start(cpus int, memory int, efiPath string) error {
-	d.setupNetworking(tapDevice, bridgeName)
+func (d *KVM) Start(cpus int, memory int, disk int, efiPath string) error {
+	d.forgetAddress()
+
+	d.UI.Say("Creating the VM network...")
+	err := d.setupNetworking(tapDevice, bridgeName)
+	if err != nil {
+		return err
+	}
 
 	d.UI.Say("Creating the VM...")
-	err := d.DaemonRunner.AddDaemon(d.daemonSpec(cpus, memory, tapDevice, efiPath))
+	err = d.Host.prepareVM(d.Config, d.UI, disk)
+	if err != nil {
+		return err
+	}
+
+	// Keeps the commands run against this environment on the same hypervisor
+	err = ioutil.WriteFile(hypervisorPath(d.Config), []byte(d.hypervisor()), 0600)
+	if err != nil {
+		return err
+	}
+
+	spec, err := d.vmSpec(cpus, memory, efiPath)
+	if err != nil {
+		return err
+	}
+
+	err = d.DaemonRunner.AddDaemon(spec)
 	if err != nil {
 		return err
 	}
@@ -61,21 +99,44 @@ func (d *KVM) Start(cpus int, memory int, efiPath string) error {
 		return err
 	}
 
-	d.UI.Say("Fetching VM Address...")
-	ip, err := d.fetchIP()
+	err = d.shareSockets()
 	if err != nil {
 		return err
 	}
 
-	d.setupRoutes(ip)
-	return nil
+	// The DHCP server only ever leases this address to the VM,
+	// the route does not need to wait until the VM got it
+	return d.setupRoutes(driver.KVMGuestIP)
+}
+
+// vmSpec runs the VM with the selected hypervisor,
+// attached to the tap device of the VM network.
+func (d *KVM) vmSpec(cpus int, memory int, efiPath string) (daemon.DaemonSpec, error) {
+	if d.hypervisor() == CloudHypervisor {
+		return d.Host.cloudHypervisorSpec(d.Config, cpus, memory, efiPath,
+			fmt.Sprintf("tap=%s,mac=%s", tapDevice, guestMAC))
+	}
+
+	return d.Host.qemuSpec(d.Config, cpus, memory, efiPath,
+		"-netdev", fmt.Sprintf("tap,id=net0,ifname=%s,script=no,downscript=no", tapDevice),
+		"-device", "virtio-net-pci,netdev=net0,mac="+guestMAC,
+	)
+}
+
+func (d *KVM) hypervisor() string {
+	if d.Hypervisor == "" {
+		return QEMU
+	}
+
+	return d.Hypervisor
 }
 
 func (d *KVM) Stop() error {
+	d.Host.shutdownVM(d.Config, d.UI, d.hypervisor(), d.sshArgs())
 	d.DaemonRunner.Stop(driver.LinuxKitLabel)
 	d.DaemonRunner.RemoveDaemon(driver.LinuxKitLabel)
-	d.teardownRoutes()
 	d.teardownNetworking(tapDevice)
+	d.forgetAddress()
 	return nil
 }
 
@@ -83,48 +144,56 @@ func (d *KVM) IsRunning() (bool, error) {
 	return d.DaemonRunner.IsRunning(driver.LinuxKitLabel)
 }
 
-func (d *KVM) fetchIP() (string, error) {
-	var (
-		ticker  = time.NewTicker(time.Second)
-		timeout = time.After(2 * time.Minute)
-		err     error
-	)
+// shareSockets hands the console and control sockets, which the
+// hypervisor creates as root, over to the user so that cf dev console
+// and cf dev stop do not need sudo.
+func (d *KVM) shareSockets() error {
+	if d.Host.Uid == 0 {
+		return nil
+	}
+
+	sockets := []string{driver.ConsoleSocket(d.Config), monitorSocket(d.Config)}
+	if d.hypervisor() == CloudHypervisor {
+		sockets = []string{apiSocket(d.Config)}
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for _, socket := range sockets {
+		for {
+			if _, err := os.Stat(socket); err == nil {
+				if err := d.SudoShell.Run("chown", strconv.Itoa(d.Host.Uid), socket); err != nil {
+					return fmt.Errorf("sharing the sockets of the VM: %s", err)
+				}
+
+				break
+			}
 
-	for {
-		select {
-		case <-ticker.C:
-			var ip string
-			ip, err = driver.IP(d.Config)
-			if err == nil {
-				return ip, nil
+			// Ping reports why the VM did not start from the console log
+			if time.Now().After(deadline) {
+				return nil
 			}
-		case <-timeout:
-			return "", err
+
+			time.Sleep(250 * time.Millisecond)
 		}
 	}
-}
 
-func (d *KVM) daemonSpec(cpus int, mem int, tapDevice, efiPath string) daemon.DaemonSpec {
-	var (
-		linuxkit = filepath.Join(d.Config.BinaryDir, "linuxkit")
-		ovmf     = filepath.Join(d.Config.BinaryDir, "OVMF.fd")
-		diskPath = filepath.Join(d.Config.StateLinuxkit, "disk.qcow2")
-	)
+	return nil
+}
 
-	return daemon.DaemonSpec{
-		Label:   driver.LinuxKitLabel,
-		Program: linuxkit,
-		ProgramArguments: []string{
-			"run", "qemu",
-			"-cpus", fmt.Sprintf("%d", cpus),
-			"-mem", fmt.Sprintf("%d", mem),
-			"-disk", fmt.Sprintf("size=120G,format=qcow2,file=%s", diskPath),
-			"-fw", ovmf,
-			"-state", d.Config.StateLinuxkit,
-			"-networking", fmt.Sprintf("tap,%s", tapDevice),
-			"-iso", "-uefi",
-			efiPath,
-		},
-		LogPath: path.Join(d.Config.LogDir, "linuxkit.log"),
+// sshArgs reach the VM over its network, to power it off
+// when its hypervisor cannot be asked to. Only the host key
+// pinned when the VM was provisioned is trusted.
+func (d *KVM) sshArgs() []string {
+	// Without a pinned key the connection fails, as any other would
+	writeKnownHosts(d.Config, "["+driver.KVMGuestIP+"]:"+sshPort)
+
+	return []string{
+		"-i", filepath.Join(d.Config.StateDir, "id_rsa"),
+		"-p", sshPort,
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=yes",
+		"-o", "UserKnownHostsFile=" + knownHostsPath(d.Config),
+		"-o", "ConnectTimeout=5",
+		"root@" + driver.KVMGuestIP,
 	}
 }
@@ -0,0 +1,13 @@
+package kvm_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestKVM(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "KVM Suite")
+}
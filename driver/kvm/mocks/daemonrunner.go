@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/driver (interfaces: DaemonRunner)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	daemon "code.cloudfoundry.org/cfdev/daemon"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockDaemonRunner is a mock of DaemonRunner interface
+type MockDaemonRunner struct {
+	ctrl     *gomock.Controller
+	recorder *MockDaemonRunnerMockRecorder
+}
+
+// MockDaemonRunnerMockRecorder is the mock recorder for MockDaemonRunner
+type MockDaemonRunnerMockRecorder struct {
+	mock *MockDaemonRunner
+}
+
+// NewMockDaemonRunner creates a new mock instance
+func NewMockDaemonRunner(ctrl *gomock.Controller) *MockDaemonRunner {
+	mock := &MockDaemonRunner{ctrl: ctrl}
+	mock.recorder = &MockDaemonRunnerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDaemonRunner) EXPECT() *MockDaemonRunnerMockRecorder {
+	return m.recorder
+}
+
+// AddDaemon mocks base method
+func (m *MockDaemonRunner) AddDaemon(arg0 daemon.DaemonSpec) error {
+	ret := m.ctrl.Call(m, "AddDaemon", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDaemon indicates an expected call of AddDaemon
+func (mr *MockDaemonRunnerMockRecorder) AddDaemon(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDaemon", reflect.TypeOf((*MockDaemonRunner)(nil).AddDaemon), arg0)
+}
+
+// IsRunning mocks base method
+func (m *MockDaemonRunner) IsRunning(arg0 string) (bool, error) {
+	ret := m.ctrl.Call(m, "IsRunning", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRunning indicates an expected call of IsRunning
+func (mr *MockDaemonRunnerMockRecorder) IsRunning(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRunning", reflect.TypeOf((*MockDaemonRunner)(nil).IsRunning), arg0)
+}
+
+// RemoveDaemon mocks base method
+func (m *MockDaemonRunner) RemoveDaemon(arg0 string) error {
+	ret := m.ctrl.Call(m, "RemoveDaemon", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveDaemon indicates an expected call of RemoveDaemon
+func (mr *MockDaemonRunnerMockRecorder) RemoveDaemon(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDaemon", reflect.TypeOf((*MockDaemonRunner)(nil).RemoveDaemon), arg0)
+}
+
+// Start mocks base method
+func (m *MockDaemonRunner) Start(arg0 string) error {
+	ret := m.ctrl.Call(m, "Start", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start
+func (mr *MockDaemonRunnerMockRecorder) Start(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockDaemonRunner)(nil).Start), arg0)
+}
+
+// Stop mocks base method
+func (m *MockDaemonRunner) Stop(arg0 string) error {
+	ret := m.ctrl.Call(m, "Stop", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop
+func (mr *MockDaemonRunnerMockRecorder) Stop(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockDaemonRunner)(nil).Stop), arg0)
+}
@@ -1,35 +1,242 @@
 package kvm
 
 import (
+	"code.cloudfoundry.org/cfdev/daemon"
 	"code.cloudfoundry.org/cfdev/driver"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
 )
 
-// consider moving to more native go implementation
-// rather than shelling out
-func (d *KVM) setupNetworking(tapDevice, bridge string) {
-	d.SudoShell.Run("ip", "tuntap", "add", "dev", tapDevice, "mode", "tap")
-	d.SudoShell.Run("ip", "link", "set", tapDevice, "master", bridge)
-	d.SudoShell.Run("ip", "link", "set", "dev", bridge, "up")
-	d.SudoShell.Run("ip", "link", "set", "dev", tapDevice, "up")
+const (
+	dhcpLabel     = "org.cloudfoundry.cfdev.dhcp"
+	guestMAC      = "02:cf:de:00:00:02"
+	networkPrefix = "/24"
+	networkSubnet = "192.168.107.0" + networkPrefix
+)
+
+// resource is something the driver created on the host. Resources are
+// recorded as soon as they are created, so that Stop removes them
+// even after a failed or interrupted start.
+type resource struct {
+	Kind string   `yaml:"kind"`
+	Name string   `yaml:"name"`
+	Undo []string `yaml:"undo,omitempty"`
+}
+
+// setupNetworking creates the bridge of the VM with its own DHCP server,
+// so that the VM always gets the same address, and lets it reach the
+// outside world through NAT.
+func (d *KVM) setupNetworking(tapDevice, bridge string) error {
+	if err := os.MkdirAll(d.Config.StateLinuxkit, 0755); err != nil {
+		return err
+	}
+
+	if !linkExists(bridge) {
+		if err := d.SudoShell.Run("ip", "link", "add", "name", bridge, "type", "bridge"); err != nil {
+			return fmt.Errorf("creating the %s bridge: %s", bridge, err)
+		}
+	}
+
+	err := d.track(resource{Kind: "bridge", Name: bridge, Undo: []string{"ip", "link", "del", "dev", bridge}})
+	if err != nil {
+		return err
+	}
+
+	if err := d.SudoShell.Run("ip", "addr", "replace", driver.KVMBridgeIP+networkPrefix, "dev", bridge); err != nil {
+		return fmt.Errorf("assigning an address to the %s bridge: %s", bridge, err)
+	}
+
+	if !linkExists(tapDevice) {
+		if err := d.SudoShell.Run("ip", "tuntap", "add", "dev", tapDevice, "mode", "tap"); err != nil {
+			return fmt.Errorf("creating the %s tap device: %s", tapDevice, err)
+		}
+	}
+
+	err = d.track(resource{Kind: "tap", Name: tapDevice, Undo: []string{"ip", "link", "del", "dev", tapDevice}})
+	if err != nil {
+		return err
+	}
+
+	for _, args := range [][]string{
+		{"ip", "link", "set", tapDevice, "master", bridge},
+		{"ip", "link", "set", "dev", bridge, "up"},
+		{"ip", "link", "set", "dev", tapDevice, "up"},
+	} {
+		if err := d.SudoShell.Run(args...); err != nil {
+			return fmt.Errorf("configuring the %s bridge: %s", bridge, err)
+		}
+	}
+
+	if err := d.setupNAT(bridge); err != nil {
+		return err
+	}
+
+	return d.startDHCP(bridge)
+}
+
+func (d *KVM) setupNAT(bridge string) error {
+	data, err := ioutil.ReadFile(d.Host.path("/proc/sys/net/ipv4/ip_forward"))
+	if err != nil {
+		return err
+	}
+
+	if previous := strings.TrimSpace(string(data)); previous != "1" {
+		if err := d.SudoShell.Run("sysctl", "-w", "net.ipv4.ip_forward=1"); err != nil {
+			return fmt.Errorf("enabling IP forwarding: %s", err)
+		}
+
+		err := d.track(resource{Kind: "sysctl", Name: "net.ipv4.ip_forward", Undo: []string{"sysctl", "-w", "net.ipv4.ip_forward=" + previous}})
+		if err != nil {
+			return err
+		}
+	}
+
+	rules := [][]string{
+		{"-t", "nat", "POSTROUTING", "-s", networkSubnet, "!", "-d", networkSubnet, "-j", "MASQUERADE"},
+		{"-t", "filter", "FORWARD", "-i", bridge, "-j", "ACCEPT"},
+		{"-t", "filter", "FORWARD", "-o", bridge, "-j", "ACCEPT"},
+	}
+
+	for _, rule := range rules {
+		var (
+			table = rule[:2]
+			chain = rule[2]
+			spec  = rule[3:]
+		)
+
+		if err := d.SudoShell.Run(append(append(append([]string{"iptables"}, table...), "-I", chain), spec...)...); err != nil {
+			return fmt.Errorf("adding the iptables rule '%s': %s", strings.Join(rule, " "), err)
+		}
+
+		err := d.track(resource{Kind: "iptables", Name: strings.Join(rule, " "), Undo: append(append(append([]string{"iptables"}, table...), "-D", chain), spec...)})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func (d *KVM) setupRoutes(ip string) {
-	d.SudoShell.Run("ip", "route", "add", driver.ContainerSubnet, "via", ip)
+// startDHCP runs a DHCP server on the bridge that only leases the
+// guest address, to the MAC address of the VM.
+func (d *KVM) startDHCP(bridge string) error {
+	dnsmasq, err := d.Host.findTool("dnsmasq")
+	if err != nil {
+		return err
+	}
+
+	err = d.DaemonRunner.AddDaemon(daemon.DaemonSpec{
+		Label:   dhcpLabel,
+		Program: dnsmasq,
+		ProgramArguments: []string{
+			"--keep-in-foreground",
+			"--conf-file=/dev/null",
+			"--pid-file",
+			"--log-facility=-",
+			"--interface=" + bridge,
+			"--bind-interfaces",
+			"--except-interface=lo",
+			"--listen-address=" + driver.KVMBridgeIP,
+			"--dhcp-authoritative",
+			fmt.Sprintf("--dhcp-range=%s,%s,255.255.255.0,infinite", driver.KVMGuestIP, driver.KVMGuestIP),
+			fmt.Sprintf("--dhcp-host=%s,%s", guestMAC, driver.KVMGuestIP),
+			"--dhcp-leasefile=" + filepath.Join(d.Config.StateLinuxkit, "dnsmasq.leases"),
+		},
+		LogPath: path.Join(d.Config.LogDir, "dhcp.log"),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = d.track(resource{Kind: "daemon", Name: dhcpLabel})
+	if err != nil {
+		return err
+	}
+
+	return d.DaemonRunner.Start(dhcpLabel)
 }
 
-func (d *KVM) teardownRoutes() {
-	d.SudoShell.Run("ip", "route", "flush", driver.ContainerSubnet)
+func (d *KVM) setupRoutes(ip string) error {
+	if err := d.SudoShell.Run("ip", "route", "replace", driver.ContainerSubnet, "via", ip); err != nil {
+		return fmt.Errorf("adding a route to the containers: %s", err)
+	}
+
+	return d.track(resource{Kind: "route", Name: driver.ContainerSubnet, Undo: []string{"ip", "route", "del", driver.ContainerSubnet}})
 }
 
+// teardownNetworking removes everything setupNetworking and
+// setupRoutes created, in reverse order.
 func (d *KVM) teardownNetworking(tapDevice string) {
-	if d.tapDeviceExists(tapDevice) {
-		d.SudoShell.Run("ip", "link", "set", "dev", tapDevice, "down")
-		d.SudoShell.Run("ip", "link", "del", "dev", tapDevice)
+	resources, err := d.resources()
+	if err != nil || len(resources) == 0 {
+		// Environments started by previous versions of CF Dev
+		// only created a tap device and a route
+		d.SudoShell.Run("ip", "route", "flush", driver.ContainerSubnet)
+		if linkExists(tapDevice) {
+			d.SudoShell.Run("ip", "link", "del", "dev", tapDevice)
+		}
+		return
 	}
+
+	for i := len(resources) - 1; i >= 0; i-- {
+		r := resources[i]
+		switch {
+		case r.Kind == "daemon":
+			d.DaemonRunner.Stop(r.Name)
+			d.DaemonRunner.RemoveDaemon(r.Name)
+		case (r.Kind == "bridge" || r.Kind == "tap") && !linkExists(r.Name):
+		default:
+			d.SudoShell.Run(r.Undo...)
+		}
+	}
+
+	os.Remove(d.resourcesPath())
+}
+
+func (d *KVM) track(r resource) error {
+	resources, err := d.resources()
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range resources {
+		if existing.Kind == r.Kind && existing.Name == r.Name {
+			return nil
+		}
+	}
+
+	data, err := yaml.Marshal(append(resources, r))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(d.resourcesPath(), data, 0600)
+}
+
+func (d *KVM) resources() ([]resource, error) {
+	data, err := ioutil.ReadFile(d.resourcesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var resources []resource
+	err = yaml.Unmarshal(data, &resources)
+	return resources, err
+}
+
+func (d *KVM) resourcesPath() string {
+	return filepath.Join(d.Config.StateLinuxkit, "network.yml")
 }
 
-func (d *KVM) tapDeviceExists(tapDevice string) bool {
-	err := exec.Command("ip", "link", "show", tapDevice).Run()
+func linkExists(name string) bool {
+	err := exec.Command("ip", "link", "show", name).Run()
 	return err == nil
 }
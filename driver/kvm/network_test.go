@@ -0,0 +1,126 @@
+package kvm_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/driver"
+	"code.cloudfoundry.org/cfdev/driver/kvm"
+	"code.cloudfoundry.org/cfdev/driver/kvm/mocks"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeUI struct{}
+
+func (fakeUI) Say(message string, args ...interface{}) {}
+
+var _ = Describe("Network", func() {
+	var (
+		mockController   *gomock.Controller
+		mockRunner       *mocks.MockRunner
+		mockDaemonRunner *mocks.MockDaemonRunner
+		root             string
+		cfg              config.Config
+		d                *kvm.KVM
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockRunner = mocks.NewMockRunner(mockController)
+		mockDaemonRunner = mocks.NewMockDaemonRunner(mockController)
+
+		var err error
+		root, err = ioutil.TempDir("", "cfdev-kvm-network-")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(root, "proc", "sys", "net", "ipv4"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(root, "proc", "sys", "net", "ipv4", "ip_forward"), []byte("0\n"), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(root, "usr", "sbin"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(root, "usr", "sbin", "dnsmasq"), nil, 0755)).To(Succeed())
+
+		cfg = config.Config{
+			StateLinuxkit: filepath.Join(root, "state", "linuxkit"),
+			LogDir:        filepath.Join(root, "log"),
+			BinaryDir:     filepath.Join(root, "bin"),
+		}
+
+		d = &kvm.KVM{
+			UI:           fakeUI{},
+			Config:       cfg,
+			DaemonRunner: mockDaemonRunner,
+			SudoShell:    mockRunner,
+			Host: kvm.Host{
+				Root: root,
+				LookPath: func(file string) (string, error) {
+					if strings.HasPrefix(file, "qemu") {
+						return "/usr/bin/" + file, nil
+					}
+					return "", fmt.Errorf("%s not found", file)
+				},
+				Output: func(name string, arg ...string) ([]byte, error) {
+					Expect(name).To(Equal("/usr/bin/qemu-img"))
+					Expect(arg).To(Equal([]string{"create", "-f", "qcow2", filepath.Join(cfg.StateLinuxkit, "disk.qcow2"), "120G"}))
+					return nil, nil
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(root)
+	})
+
+	It("creates its own network and removes all of it on stop", func() {
+		gomock.InOrder(
+			mockRunner.EXPECT().Run("ip", "link", "add", "name", "cfdev0", "type", "bridge"),
+			mockRunner.EXPECT().Run("ip", "addr", "replace", "192.168.107.1/24", "dev", "cfdev0"),
+			mockRunner.EXPECT().Run("ip", "tuntap", "add", "dev", "cfdevtap0", "mode", "tap"),
+			mockRunner.EXPECT().Run("ip", "link", "set", "cfdevtap0", "master", "cfdev0"),
+			mockRunner.EXPECT().Run("ip", "link", "set", "dev", "cfdev0", "up"),
+			mockRunner.EXPECT().Run("ip", "link", "set", "dev", "cfdevtap0", "up"),
+			mockRunner.EXPECT().Run("sysctl", "-w", "net.ipv4.ip_forward=1"),
+			mockRunner.EXPECT().Run("iptables", "-t", "nat", "-I", "POSTROUTING", "-s", "192.168.107.0/24", "!", "-d", "192.168.107.0/24", "-j", "MASQUERADE"),
+			mockRunner.EXPECT().Run("iptables", "-t", "filter", "-I", "FORWARD", "-i", "cfdev0", "-j", "ACCEPT"),
+			mockRunner.EXPECT().Run("iptables", "-t", "filter", "-I", "FORWARD", "-o", "cfdev0", "-j", "ACCEPT"),
+			mockDaemonRunner.EXPECT().AddDaemon(gomock.Any()).Do(func(spec daemon.DaemonSpec) {
+				Expect(spec.Label).To(Equal("org.cloudfoundry.cfdev.dhcp"))
+				Expect(spec.Program).To(Equal("/usr/sbin/dnsmasq"))
+				Expect(spec.ProgramArguments).To(ContainElement("--dhcp-host=02:cf:de:00:00:02,192.168.107.2"))
+			}),
+			mockDaemonRunner.EXPECT().Start("org.cloudfoundry.cfdev.dhcp"),
+			mockDaemonRunner.EXPECT().AddDaemon(gomock.Any()).Do(func(spec daemon.DaemonSpec) {
+				Expect(spec.Label).To(Equal(driver.LinuxKitLabel))
+				Expect(spec.Program).To(Equal("/usr/bin/qemu-system-x86_64"))
+				Expect(spec.ProgramArguments).To(ContainElement("tap,id=net0,ifname=cfdevtap0,script=no,downscript=no"))
+				Expect(spec.ProgramArguments).To(ContainElement("virtio-net-pci,netdev=net0,mac=02:cf:de:00:00:02"))
+			}),
+			mockDaemonRunner.EXPECT().Start(driver.LinuxKitLabel),
+			mockRunner.EXPECT().Run("ip", "route", "replace", "10.144.0.0/16", "via", "192.168.107.2"),
+		)
+
+		Expect(d.Start(2, 4096, 120, "/some/cfdev-efi.iso")).To(Succeed())
+
+		gomock.InOrder(
+			mockDaemonRunner.EXPECT().Stop(driver.LinuxKitLabel),
+			mockDaemonRunner.EXPECT().RemoveDaemon(driver.LinuxKitLabel),
+			mockRunner.EXPECT().Run("ip", "route", "del", "10.144.0.0/16"),
+			mockDaemonRunner.EXPECT().Stop("org.cloudfoundry.cfdev.dhcp"),
+			mockDaemonRunner.EXPECT().RemoveDaemon("org.cloudfoundry.cfdev.dhcp"),
+			mockRunner.EXPECT().Run("iptables", "-t", "filter", "-D", "FORWARD", "-o", "cfdev0", "-j", "ACCEPT"),
+			mockRunner.EXPECT().Run("iptables", "-t", "filter", "-D", "FORWARD", "-i", "cfdev0", "-j", "ACCEPT"),
+			mockRunner.EXPECT().Run("iptables", "-t", "nat", "-D", "POSTROUTING", "-s", "192.168.107.0/24", "!", "-d", "192.168.107.0/24", "-j", "MASQUERADE"),
+			mockRunner.EXPECT().Run("sysctl", "-w", "net.ipv4.ip_forward=0"),
+		)
+
+		Expect(d.Stop()).To(Succeed())
+		Expect(filepath.Join(cfg.StateLinuxkit, "network.yml")).NotTo(BeAnExistingFile())
+	})
+})
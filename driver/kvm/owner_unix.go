@@ -0,0 +1,17 @@
+// +build !windows
+
+package kvm
+
+import (
+	"os"
+	"syscall"
+)
+
+func fileOwner(info os.FileInfo) (int, int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return int(stat.Uid), int(stat.Gid), true
+}
@@ -0,0 +1,7 @@
+package kvm
+
+import "os"
+
+func fileOwner(info os.FileInfo) (int, int, bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,123 @@
+package kvm
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/driver"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// prepareVM creates the disk of the VM the first time it starts, along
+// with forgetting the host keys of the previous guest, grows
+// it when a larger size is requested, and removes the console and
+// control sockets left behind by a previous run.
+func (h Host) prepareVM(cfg config.Config, ui driver.UI, size int) error {
+	if err := os.MkdirAll(cfg.StateLinuxkit, 0755); err != nil {
+		return err
+	}
+
+	for _, socket := range []string{driver.ConsoleSocket(cfg), monitorSocket(cfg), apiSocket(cfg)} {
+		if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	disk := diskPath(cfg)
+	if _, err := os.Stat(disk); os.IsNotExist(err) {
+		if err := forgetHostKeys(cfg); err != nil {
+			return err
+		}
+
+		return h.qemuImg("creating the disk of the VM", "create", "-f", "qcow2", disk, fmt.Sprintf("%dG", size))
+	}
+
+	usage, err := qcowUsage(disk)
+	if err != nil {
+		return err
+	}
+
+	switch current := usage.Size >> 30; {
+	case current < uint64(size):
+		ui.Say("Growing the disk of the VM from %d GB to %d GB...", current, size)
+		return h.qemuImg("growing the disk of the VM", "resize", disk, fmt.Sprintf("%dG", size))
+	case current > uint64(size):
+		ui.Say("The disk of the VM is %d GB, it cannot shrink to %d GB", current, size)
+	}
+
+	return nil
+}
+
+// forgetHostKeys drops the SSH host keys trusted for the VM, as
+// a new disk boots a guest that generates new ones.
+func forgetHostKeys(cfg config.Config) error {
+	for _, path := range []string{filepath.Join(cfg.StateDir, config.HostKeyFile), knownHostsPath(cfg)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h Host) qemuImg(action string, args ...string) error {
+	qemuImg, err := h.LookPath(qemuImgBinary)
+	if err != nil {
+		return err
+	}
+
+	output, err := h.Output(qemuImg, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %s", action, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// qemuSpec runs the VM with QEMU. Its serial console is served on a
+// unix socket for cf dev console, and always logged. Its monitor is
+// served on another one, to shut the VM down.
+func (h Host) qemuSpec(cfg config.Config, cpus int, mem int, efiPath string, networking ...string) (daemon.DaemonSpec, error) {
+	qemu, err := h.LookPath(qemuBinary)
+	if err != nil {
+		return daemon.DaemonSpec{}, err
+	}
+
+	args := []string{
+		"-name", driver.VMName,
+		"-machine", "q35,accel=kvm",
+		"-cpu", "host",
+		"-smp", strconv.Itoa(cpus),
+		"-m", strconv.Itoa(mem),
+		"-bios", filepath.Join(cfg.BinaryDir, "OVMF.fd"),
+		"-drive", fmt.Sprintf("file=%s,format=qcow2,if=virtio,discard=unmap", qemuEscape(diskPath(cfg))),
+		"-cdrom", efiPath,
+		"-boot", "d",
+		"-device", "virtio-rng-pci",
+		"-display", "none",
+		"-monitor", fmt.Sprintf("unix:%s,server,nowait", qemuEscape(monitorSocket(cfg))),
+		"-chardev", fmt.Sprintf("socket,id=console,path=%s,server,nowait,logfile=%s",
+			qemuEscape(driver.ConsoleSocket(cfg)), qemuEscape(driver.ConsoleLog(cfg))),
+		"-serial", "chardev:console",
+	}
+
+	return daemon.DaemonSpec{
+		Label:            driver.LinuxKitLabel,
+		Program:          qemu,
+		ProgramArguments: append(args, networking...),
+		LogPath:          path.Join(cfg.LogDir, "qemu.log"),
+	}, nil
+}
+
+func diskPath(cfg config.Config) string {
+	return filepath.Join(cfg.StateLinuxkit, "disk.qcow2")
+}
+
+// qemuEscape escapes the commas of a value in a QEMU option list.
+func qemuEscape(value string) string {
+	return strings.Replace(value, ",", ",,", -1)
+}
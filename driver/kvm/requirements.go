@@ -0,0 +1,374 @@
+package kvm
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	safeerr "code.cloudfoundry.org/cfdev/errors"
+)
+
+const (
+	qemuBinary    = "qemu-system-x86_64"
+	qemuImgBinary = "qemu-img"
+)
+
+var (
+	minQemuVersion = [2]int{2, 6}
+	qemuVersion    = regexp.MustCompile(`version (\d+)\.(\d+)`)
+)
+
+// Host is the machine the KVM driver runs on. Its files are read
+// relative to Root, so that the requirement checks can run against
+// fake /proc, /sys, /dev and /etc trees.
+type Host struct {
+	Root     string
+	Uid      int
+	Gids     []int
+	LookPath func(file string) (string, error)
+	Output   func(name string, arg ...string) ([]byte, error)
+}
+
+func NewHost() Host {
+	gids, _ := os.Getgroups()
+
+	return Host{
+		Root:     "/",
+		Uid:      os.Getuid(),
+		Gids:     append(gids, os.Getgid()),
+		LookPath: exec.LookPath,
+		Output: func(name string, arg ...string) ([]byte, error) {
+			return exec.Command(name, arg...).Output()
+		},
+	}
+}
+
+// CheckRequirements reports every requirement of the KVM driver
+// the host does not meet, along with how to fix it on its distribution.
+func (h Host) CheckRequirements() error {
+	return h.check(
+		h.checkVirtualization,
+		h.checkQemu,
+		h.checkNetworkTools,
+		h.checkTap,
+	)
+}
+
+// CheckRootlessRequirements is CheckRequirements for the rootless KVM
+// driver, which creates no network devices but needs an SSH client.
+func (h Host) CheckRootlessRequirements() error {
+	return h.check(
+		h.checkVirtualization,
+		h.checkQemu,
+		h.checkSSH,
+	)
+}
+
+// CheckCloudHypervisorRequirements is CheckRequirements for the VM run
+// with Cloud Hypervisor, which still needs qemu-img to create its disk.
+func (h Host) CheckCloudHypervisorRequirements(cfdevHome string) error {
+	return h.check(
+		h.checkVirtualization,
+		h.checkCloudHypervisor,
+		func(string) (string, string) { return h.checkCloudHypervisorFirmware(cfdevHome) },
+		h.checkQemuImg,
+		h.checkNetworkTools,
+		h.checkTap,
+	)
+}
+
+func (h Host) check(checks ...func(distro string) (string, string)) error {
+	var (
+		distro   = h.distro()
+		failures []string
+	)
+
+	for _, check := range checks {
+		if problem, hint := check(distro); problem != "" {
+			failures = append(failures, fmt.Sprintf("- %s\n  %s", problem, hint))
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return safeerr.SafeWrap(errors.New("\n"+strings.Join(failures, "\n")), "Host requirements not met")
+}
+
+func (h Host) checkVirtualization(distro string) (string, string) {
+	flag := h.virtualizationFlag()
+	if flag == "" {
+		return "the CPU does not support hardware virtualization, or it is disabled",
+			"Enable Intel VT-x or AMD-V in the BIOS/UEFI settings. In a virtual machine, enable nested virtualization."
+	}
+
+	devicePath := h.path("/dev/kvm")
+	info, err := os.Stat(devicePath)
+	if err != nil {
+		module := "kvm_intel"
+		if flag == "svm" {
+			module = "kvm_amd"
+		}
+
+		return "/dev/kvm does not exist, the kvm module is not loaded", "Run: sudo modprobe " + module
+	}
+
+	if !h.canReadWrite(info) {
+		group := "kvm"
+		if _, gid, ok := fileOwner(info); ok {
+			if g, err := user.LookupGroupId(strconv.Itoa(gid)); err == nil {
+				group = g.Name
+			}
+		}
+
+		return "/dev/kvm is not readable and writable by the current user",
+			fmt.Sprintf("Run: sudo usermod -aG %s $USER, then log out and back in.", group)
+	}
+
+	return "", ""
+}
+
+func (h Host) checkQemu(distro string) (string, string) {
+	hint := installHint(distro, "qemu")
+
+	if _, err := h.LookPath(qemuBinary); err != nil {
+		return qemuBinary + " was not found", hint
+	}
+
+	if _, err := h.LookPath(qemuImgBinary); err != nil {
+		return qemuImgBinary + " was not found", hint
+	}
+
+	output, err := h.Output(qemuBinary, "--version")
+	if err != nil {
+		return fmt.Sprintf("unable to get the version of %s: %s", qemuBinary, err), hint
+	}
+
+	matches := qemuVersion.FindSubmatch(output)
+	if matches == nil {
+		return fmt.Sprintf("unable to get the version of %s from '%s'", qemuBinary, strings.TrimSpace(string(output))), hint
+	}
+
+	major, _ := strconv.Atoi(string(matches[1]))
+	minor, _ := strconv.Atoi(string(matches[2]))
+	if major < minQemuVersion[0] || (major == minQemuVersion[0] && minor < minQemuVersion[1]) {
+		return fmt.Sprintf("QEMU %d.%d is installed, CF Dev requires QEMU %d.%d or later", major, minor, minQemuVersion[0], minQemuVersion[1]),
+			"Upgrade QEMU. " + hint
+	}
+
+	return "", ""
+}
+
+func (h Host) checkCloudHypervisor(distro string) (string, string) {
+	if _, err := h.LookPath(cloudHypervisorBinary); err != nil {
+		return cloudHypervisorBinary + " was not found",
+			"Download it from https://github.com/cloud-hypervisor/cloud-hypervisor/releases into a directory of the PATH, or start CF Dev with --driver qemu."
+	}
+
+	return "", ""
+}
+
+func (h Host) checkCloudHypervisorFirmware(cfdevHome string) (string, string) {
+	if _, err := h.cloudHypervisorFirmware(cfdevHome); err != nil {
+		return err.Error(),
+			fmt.Sprintf("Download %s from https://github.com/cloud-hypervisor/edk2/releases to %s.",
+				cloudHypervisorFirmware, filepath.Join(cfdevHome, cloudHypervisorFirmware))
+	}
+
+	return "", ""
+}
+
+func (h Host) checkQemuImg(distro string) (string, string) {
+	if _, err := h.LookPath(qemuImgBinary); err != nil {
+		return qemuImgBinary + " was not found, it is needed to create the disk of the VM", installHint(distro, "qemu")
+	}
+
+	return "", ""
+}
+
+func (h Host) checkNetworkTools(distro string) (string, string) {
+	for _, tool := range []string{"ip", "iptables", "sysctl", "dnsmasq"} {
+		if _, err := h.findTool(tool); err != nil {
+			return err.Error() + ", it is needed to create the network of the VM", installHint(distro, "ip, iptables, sysctl and dnsmasq")
+		}
+	}
+
+	return "", ""
+}
+
+func (h Host) checkSSH(distro string) (string, string) {
+	if _, err := h.LookPath("ssh"); err != nil {
+		return "ssh was not found, it is needed to reach the VM without root privileges", installHint(distro, "ssh")
+	}
+
+	return "", ""
+}
+
+func (h Host) checkTap(distro string) (string, string) {
+	if _, err := os.Stat(h.path("/dev/net/tun")); err != nil {
+		return "/dev/net/tun does not exist, tap devices cannot be created", "Run: sudo modprobe tun"
+	}
+
+	if h.Uid == 0 {
+		return "", ""
+	}
+
+	if _, err := h.LookPath("sudo"); err != nil {
+		return "sudo was not found, tap devices cannot be created without root privileges",
+			installHint(distro, "sudo")
+	}
+
+	return "", ""
+}
+
+// findTool looks for a system tool in the PATH, then in the
+// sbin directories that are usually not in the PATH of users.
+func (h Host) findTool(name string) (string, error) {
+	if path, err := h.LookPath(name); err == nil {
+		return path, nil
+	}
+
+	for _, dir := range []string{"/usr/local/sbin", "/usr/sbin", "/sbin"} {
+		if info, err := os.Stat(h.path(dir, name)); err == nil && !info.IsDir() {
+			return filepath.Join(dir, name), nil
+		}
+	}
+
+	return "", fmt.Errorf("%s was not found", name)
+}
+
+// virtualizationFlag returns the CPU flag of Intel VT-x (vmx)
+// or AMD-V (svm), if the CPU has one.
+func (h Host) virtualizationFlag() string {
+	data, err := ioutil.ReadFile(h.path("/proc/cpuinfo"))
+	if err != nil {
+		return ""
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "flags") {
+			continue
+		}
+
+		for _, flag := range strings.Fields(line) {
+			if flag == "vmx" || flag == "svm" {
+				return flag
+			}
+		}
+	}
+
+	return ""
+}
+
+func (h Host) canReadWrite(info os.FileInfo) bool {
+	mode := info.Mode().Perm()
+	if h.Uid == 0 || mode&0006 == 0006 {
+		return true
+	}
+
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		return true
+	}
+
+	if uid == h.Uid {
+		return mode&0600 == 0600
+	}
+
+	for _, g := range h.Gids {
+		if g == gid {
+			return mode&0060 == 0060
+		}
+	}
+
+	return false
+}
+
+// distro returns the family of the Linux distribution of the host:
+// debian, fedora, arch, suse, or an empty string when unknown.
+func (h Host) distro() string {
+	data, err := ioutil.ReadFile(h.path("/etc/os-release"))
+	if err != nil {
+		return ""
+	}
+
+	var ids []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "ID=") || strings.HasPrefix(line, "ID_LIKE=") {
+			value := strings.Trim(line[strings.Index(line, "=")+1:], `"'`)
+			ids = append(ids, strings.Fields(value)...)
+		}
+	}
+
+	for _, id := range ids {
+		switch id {
+		case "debian", "ubuntu":
+			return "debian"
+		case "fedora", "rhel", "centos":
+			return "fedora"
+		case "arch":
+			return "arch"
+		case "suse", "opensuse", "sles":
+			return "suse"
+		}
+	}
+
+	return ""
+}
+
+func (h Host) path(elem ...string) string {
+	return filepath.Join(append([]string{h.Root}, elem...)...)
+}
+
+var packages = map[string]map[string]string{
+	"qemu": {
+		"debian": "apt-get install qemu-system-x86 qemu-utils",
+		"fedora": "dnf install qemu-system-x86 qemu-img",
+		"arch":   "pacman -S qemu",
+		"suse":   "zypper install qemu-x86 qemu-tools",
+	},
+	"ip, iptables, sysctl and dnsmasq": {
+		"debian": "apt-get install iproute2 iptables procps dnsmasq-base",
+		"fedora": "dnf install iproute iptables procps-ng dnsmasq",
+		"arch":   "pacman -S iproute2 iptables procps-ng dnsmasq",
+		"suse":   "zypper install iproute2 iptables procps dnsmasq",
+	},
+	"ssh": {
+		"debian": "apt-get install openssh-client",
+		"fedora": "dnf install openssh-clients",
+		"arch":   "pacman -S openssh",
+		"suse":   "zypper install openssh-clients",
+	},
+	"sudo": {
+		"debian": "apt-get install sudo",
+		"fedora": "dnf install sudo",
+		"arch":   "pacman -S sudo",
+		"suse":   "zypper install sudo",
+	},
+}
+
+// installHint tells how to install a package on the given distribution.
+func installHint(distro string, pkg string) string {
+	command, ok := packages[pkg][distro]
+
+	switch {
+	case !ok:
+		return fmt.Sprintf("Install %s with the package manager of your distribution.", pkg)
+	case pkg == "sudo":
+		return "As root, run: " + command
+	default:
+		return "Run: sudo " + command
+	}
+}
@@ -0,0 +1,172 @@
+package kvm_test
+
+import (
+	"code.cloudfoundry.org/cfdev/driver/kvm"
+	"code.cloudfoundry.org/cfdev/errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Host", func() {
+	var (
+		root     string
+		host     kvm.Host
+		binaries map[string]bool
+		version  string
+	)
+
+	write := func(path string, contents string, mode os.FileMode) {
+		path = filepath.Join(root, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), mode)).To(Succeed())
+		Expect(os.Chmod(path, mode)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		root, err = ioutil.TempDir("", "cfdev-kvm-host-")
+		Expect(err).NotTo(HaveOccurred())
+
+		write("/etc/os-release", "NAME=\"Ubuntu\"\nID=ubuntu\nID_LIKE=debian\n", 0644)
+		write("/proc/cpuinfo", "processor\t: 0\nflags\t\t: fpu vme vmx sse\n", 0444)
+		write("/dev/kvm", "", 0666)
+		write("/dev/net/tun", "", 0666)
+		write("/usr/sbin/dnsmasq", "", 0755)
+
+		binaries = map[string]bool{"qemu-system-x86_64": true, "qemu-img": true, "sudo": true, "ip": true, "iptables": true, "sysctl": true, "ssh": true}
+		version = "QEMU emulator version 2.11.1(Debian 1:2.11+dfsg-1ubuntu7)\n"
+
+		host = kvm.Host{
+			Root: root,
+			Uid:  1000,
+			Gids: []int{1000},
+			LookPath: func(file string) (string, error) {
+				if binaries[file] {
+					return "/usr/bin/" + file, nil
+				}
+				return "", fmt.Errorf("%s not found", file)
+			},
+			Output: func(name string, arg ...string) ([]byte, error) {
+				Expect(name).To(Equal("qemu-system-x86_64"))
+				Expect(arg).To(Equal([]string{"--version"}))
+				return []byte(version), nil
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(root)
+	})
+
+	check := func() error {
+		return host.CheckRequirements()
+	}
+
+	It("succeeds when every requirement is met", func() {
+		Expect(check()).To(Succeed())
+	})
+
+	It("reports a CPU without virtualization flags", func() {
+		write("/proc/cpuinfo", "processor\t: 0\nflags\t\t: fpu vme sse\n", 0444)
+
+		err := check()
+		Expect(err).To(MatchError(ContainSubstring("the CPU does not support hardware virtualization")))
+		Expect(errors.SafeError(err)).To(Equal("Host requirements not met"))
+	})
+
+	It("suggests loading the kvm module matching the CPU", func() {
+		write("/proc/cpuinfo", "processor\t: 0\nflags\t\t: fpu svm sse\n", 0444)
+		Expect(os.Remove(filepath.Join(root, "dev", "kvm"))).To(Succeed())
+
+		Expect(check()).To(MatchError(ContainSubstring("Run: sudo modprobe kvm_amd")))
+	})
+
+	It("reports a /dev/kvm the user cannot use", func() {
+		write("/dev/kvm", "", 0660)
+
+		Expect(check()).To(MatchError(ContainSubstring("/dev/kvm is not readable and writable by the current user")))
+	})
+
+	It("accepts a /dev/kvm shared with a group of the user", func() {
+		write("/dev/kvm", "", 0660)
+		host.Gids = []int{1000, 0}
+
+		Expect(check()).To(Succeed())
+	})
+
+	It("reports a missing qemu with the command of the distribution", func() {
+		delete(binaries, "qemu-system-x86_64")
+
+		err := check()
+		Expect(err).To(MatchError(ContainSubstring("qemu-system-x86_64 was not found")))
+		Expect(err).To(MatchError(ContainSubstring("Run: sudo apt-get install qemu-system-x86 qemu-utils")))
+	})
+
+	It("reports an outdated qemu", func() {
+		write("/etc/os-release", "NAME=\"Fedora\"\nID=fedora\n", 0644)
+		version = "QEMU emulator version 2.5.1, Copyright (c) 2003-2008 Fabrice Bellard\n"
+
+		err := check()
+		Expect(err).To(MatchError(ContainSubstring("QEMU 2.5 is installed, CF Dev requires QEMU 2.6 or later")))
+		Expect(err).To(MatchError(ContainSubstring("sudo dnf install qemu-system-x86 qemu-img")))
+	})
+
+	It("finds the network tools outside of the PATH", func() {
+		delete(binaries, "iptables")
+		write("/sbin/iptables", "", 0755)
+
+		Expect(check()).To(Succeed())
+	})
+
+	It("reports missing network tools", func() {
+		write("/etc/os-release", "ID=gentoo\n", 0644)
+		Expect(os.Remove(filepath.Join(root, "usr", "sbin", "dnsmasq"))).To(Succeed())
+
+		err := check()
+		Expect(err).To(MatchError(ContainSubstring("dnsmasq was not found, it is needed to create the network of the VM")))
+		Expect(err).To(MatchError(ContainSubstring("Install ip, iptables, sysctl and dnsmasq with the package manager of your distribution.")))
+	})
+
+	It("reports when tap devices cannot be created", func() {
+		delete(binaries, "sudo")
+
+		Expect(check()).To(MatchError(ContainSubstring("sudo was not found")))
+	})
+
+	It("needs ssh rather than network tools without root privileges", func() {
+		delete(binaries, "iptables")
+		delete(binaries, "sudo")
+		Expect(host.CheckRootlessRequirements()).To(Succeed())
+
+		delete(binaries, "ssh")
+		Expect(host.CheckRootlessRequirements()).To(MatchError(ContainSubstring("ssh was not found")))
+	})
+
+	It("needs cloud-hypervisor and its firmware rather than qemu with the cloud-hypervisor driver", func() {
+		delete(binaries, "qemu-system-x86_64")
+
+		err := host.CheckCloudHypervisorRequirements("/home/user/.cfdev")
+		Expect(err).To(MatchError(ContainSubstring("cloud-hypervisor was not found")))
+		Expect(err).To(MatchError(ContainSubstring("the CLOUDHV.fd firmware of Cloud Hypervisor was not found")))
+		Expect(err).To(MatchError(ContainSubstring("/home/user/.cfdev/CLOUDHV.fd")))
+		Expect(err).NotTo(MatchError(ContainSubstring("qemu-system-x86_64")))
+
+		binaries["cloud-hypervisor"] = true
+		write("/usr/share/cloud-hypervisor/CLOUDHV.fd", "", 0644)
+		Expect(host.CheckCloudHypervisorRequirements("/home/user/.cfdev")).To(Succeed())
+	})
+
+	It("reports every failed check", func() {
+		delete(binaries, "qemu-img")
+		delete(binaries, "sudo")
+
+		err := check()
+		Expect(err).To(MatchError(ContainSubstring("qemu-img was not found")))
+		Expect(err).To(MatchError(ContainSubstring("sudo was not found")))
+	})
+})
@@ -0,0 +1,219 @@
+package kvm
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/driver"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	proxyLabel = "org.cloudfoundry.cfdev.proxy"
+	sshPort    = "9992"
+)
+
+// Rootless runs the VM as the current user, with QEMU user networking.
+// The VM is reached through an SSH tunnel that forwards the ports of
+// the BOSH Director and of CF to the loopback interface, and runs a
+// SOCKS proxy to the rest of the container network.
+type Rootless struct {
+	UI           driver.UI
+	Config       config.Config
+	DaemonRunner driver.DaemonRunner
+	Host         Host
+	SSHTimeout   time.Duration
+}
+
+func NewRootless(
+	cfg config.Config,
+	daemonRunner driver.DaemonRunner,
+	ui driver.UI,
+) driver.Driver {
+	return &Rootless{
+		UI:           ui,
+		Config:       cfg,
+		DaemonRunner: daemonRunner,
+		Host:         NewHost(),
+		SSHTimeout:   5 * time.Minute,
+	}
+}
+
+func (d *Rootless) CheckRequirements() error {
+	return d.Host.CheckRootlessRequirements()
+}
+
+func (d *Rootless) Prestart() error {
+	// no-op
+	return nil
+}
+
+func (d *Rootless) Start(cpus int, memory int, disk int, efiPath string) error {
+	err := os.MkdirAll(d.Config.StateLinuxkit, 0755)
+	if err != nil {
+		return err
+	}
+
+	// Keeps the commands run against this environment in rootless mode
+	err = ioutil.WriteFile(filepath.Join(d.Config.StateLinuxkit, "rootless"), nil, 0600)
+	if err != nil {
+		return err
+	}
+
+	d.UI.Say("Creating the VM...")
+	err = d.Host.prepareVM(d.Config, d.UI, disk)
+	if err != nil {
+		return err
+	}
+
+	spec, err := d.Host.qemuSpec(d.Config, cpus, memory, efiPath,
+		"-netdev", fmt.Sprintf("user,id=net0,hostfwd=tcp:127.0.0.1:%s-:%s", sshPort, sshPort),
+		"-device", "virtio-net-pci,netdev=net0",
+	)
+	if err != nil {
+		return err
+	}
+
+	err = d.DaemonRunner.AddDaemon(spec)
+	if err != nil {
+		return err
+	}
+
+	d.UI.Say("Starting the VM...")
+	err = d.DaemonRunner.Start(driver.LinuxKitLabel)
+	if err != nil {
+		return err
+	}
+
+	ssh, err := d.Host.findTool("ssh")
+	if err != nil {
+		return err
+	}
+
+	// ssh refuses private keys other users can read
+	err = os.Chmod(filepath.Join(d.Config.StateDir, "id_rsa"), 0600)
+	if err != nil {
+		return err
+	}
+
+	d.UI.Say("Waiting for the VM to accept SSH connections...")
+	err = d.waitForSSH(ssh)
+	if err != nil {
+		return err
+	}
+
+	d.UI.Say("Starting the proxy to the VM...")
+	err = d.DaemonRunner.AddDaemon(daemon.DaemonSpec{
+		Label:            proxyLabel,
+		Program:          ssh,
+		ProgramArguments: append(d.sshArgs(d.forwards()...), "-N"),
+		LogPath:          path.Join(d.Config.LogDir, "proxy.log"),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = d.DaemonRunner.Start(proxyLabel)
+	if err != nil {
+		return err
+	}
+
+	d.UI.Say("CF Dev runs without root privileges: its networks are reachable through the SOCKS proxy socks5://%s", config.RootlessProxy)
+	d.UI.Say("The CF router is forwarded to 127.0.0.1:10080 (http) and 127.0.0.1:10443 (https)")
+	return nil
+}
+
+func (d *Rootless) Stop() error {
+	d.Host.shutdownVM(d.Config, d.UI, QEMU, d.sshArgs("-o", "ConnectTimeout=5"))
+	d.DaemonRunner.Stop(proxyLabel)
+	d.DaemonRunner.RemoveDaemon(proxyLabel)
+	d.DaemonRunner.Stop(driver.LinuxKitLabel)
+	d.DaemonRunner.RemoveDaemon(driver.LinuxKitLabel)
+	return nil
+}
+
+func (d *Rootless) IsRunning() (bool, error) {
+	return d.DaemonRunner.IsRunning(driver.LinuxKitLabel)
+}
+
+// waitForSSH waits until the VM booted far enough to accept SSH
+// connections, as QEMU accepts the connections to the forwarded
+// ports long before that.
+func (d *Rootless) waitForSSH(ssh string) error {
+	var (
+		deadline = time.Now().Add(d.SSHTimeout)
+		args     = append(d.sshArgs("-o", "ConnectTimeout=5"), "true")
+		err      error
+	)
+
+	for time.Now().Before(deadline) {
+		if _, err = d.Host.Output(ssh, args...); err == nil {
+			return nil
+		}
+
+		time.Sleep(2 * time.Second)
+	}
+
+	return fmt.Errorf("timed out waiting for the VM to accept SSH connections: %s", err)
+}
+
+func (d *Rootless) sshArgs(options ...string) []string {
+	args := []string{
+		"-i", filepath.Join(d.Config.StateDir, "id_rsa"),
+		"-p", sshPort,
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=accept-new",
+		"-o", "UserKnownHostsFile=" + knownHostsPath(d.Config),
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=15",
+	}
+
+	return append(append(args, options...), "root@127.0.0.1")
+}
+
+// forwards are the ports of the VM, the BOSH Director and the
+// CF router forwarded to the loopback interface of the host.
+// The privileged ports of the router are forwarded to unprivileged ones.
+func (d *Rootless) forwards() []string {
+	var (
+		director = d.Config.BoshDirectorIP
+		router   = d.Config.CFRouterIP
+		forwards = []string{
+			"9999:127.0.0.1:9999",
+			"25555:" + director + ":25555",
+			"8443:" + director + ":8443",
+			"8844:" + director + ":8844",
+			"10080:" + router + ":80",
+			"10443:" + router + ":443",
+			"2222:" + router + ":2222",
+		}
+		args = []string{"-D", config.RootlessProxy}
+	)
+
+	for _, forward := range forwards {
+		args = append(args, "-L", "127.0.0.1:"+forward)
+	}
+
+	return args
+}
+
+// HostPorts are the ports QEMU and the SSH tunnel listen on.
+func (d *Rootless) HostPorts() []string {
+	ports := []string{"127.0.0.1:" + sshPort}
+
+	forwards := d.forwards()
+	for i := 1; i < len(forwards); i += 2 {
+		if forwards[i-1] == "-D" {
+			ports = append(ports, forwards[i])
+		} else {
+			ports = append(ports, strings.Join(strings.Split(forwards[i], ":")[:2], ":"))
+		}
+	}
+
+	return ports
+}
@@ -0,0 +1,135 @@
+package kvm_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/driver"
+	"code.cloudfoundry.org/cfdev/driver/kvm"
+	"code.cloudfoundry.org/cfdev/driver/kvm/mocks"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rootless", func() {
+	var (
+		mockController   *gomock.Controller
+		mockDaemonRunner *mocks.MockDaemonRunner
+		tmpDir           string
+		cfg              config.Config
+		sshAttempts      int
+		d                *kvm.Rootless
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockDaemonRunner = mocks.NewMockDaemonRunner(mockController)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-kvm-rootless-")
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg = config.Config{
+			BoshDirectorIP: "10.144.0.2",
+			CFRouterIP:     "10.144.0.34",
+			StateDir:       filepath.Join(tmpDir, "state"),
+			StateLinuxkit:  filepath.Join(tmpDir, "state", "linuxkit"),
+			LogDir:         filepath.Join(tmpDir, "log"),
+			BinaryDir:      filepath.Join(tmpDir, "bin"),
+		}
+		Expect(os.MkdirAll(cfg.StateDir, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(cfg.StateDir, "id_rsa"), []byte("key"), 0644)).To(Succeed())
+
+		sshAttempts = 0
+		d = &kvm.Rootless{
+			UI:           fakeUI{},
+			Config:       cfg,
+			DaemonRunner: mockDaemonRunner,
+			SSHTimeout:   time.Minute,
+			Host: kvm.Host{
+				LookPath: func(file string) (string, error) { return "/usr/bin/" + file, nil },
+				Output: func(name string, arg ...string) ([]byte, error) {
+					if name == "/usr/bin/qemu-img" {
+						return nil, nil
+					}
+
+					Expect(name).To(Equal("/usr/bin/ssh"))
+					Expect(arg[len(arg)-2:]).To(Equal([]string{"root@127.0.0.1", "true"}))
+
+					sshAttempts++
+					if sshAttempts < 2 {
+						return nil, errors.New("connection reset")
+					}
+					return nil, nil
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("starts the VM with user networking and a proxy to its networks", func() {
+		gomock.InOrder(
+			mockDaemonRunner.EXPECT().AddDaemon(gomock.Any()).Do(func(spec daemon.DaemonSpec) {
+				Expect(spec.Label).To(Equal(driver.LinuxKitLabel))
+				Expect(spec.ProgramArguments).To(ContainElement("user,id=net0,hostfwd=tcp:127.0.0.1:9992-:9992"))
+				Expect(spec.ProgramArguments).NotTo(ContainElement(ContainSubstring("tap")))
+				Expect(spec.ProgramArguments).To(ContainElement(fmt.Sprintf("socket,id=console,path=%s,server,nowait,logfile=%s",
+					filepath.Join(cfg.StateLinuxkit, "console.sock"), filepath.Join(cfg.LogDir, "console.log"))))
+				Expect(spec.ProgramArguments).To(ContainElement("chardev:console"))
+			}),
+			mockDaemonRunner.EXPECT().Start(driver.LinuxKitLabel),
+			mockDaemonRunner.EXPECT().AddDaemon(gomock.Any()).Do(func(spec daemon.DaemonSpec) {
+				Expect(spec.Label).To(Equal("org.cloudfoundry.cfdev.proxy"))
+				Expect(spec.Program).To(Equal("/usr/bin/ssh"))
+				Expect(spec.ProgramArguments).To(ContainElement("127.0.0.1:1080"))
+				Expect(spec.ProgramArguments).To(ContainElement("127.0.0.1:25555:10.144.0.2:25555"))
+				Expect(spec.ProgramArguments).To(ContainElement("127.0.0.1:10443:10.144.0.34:443"))
+				Expect(spec.ProgramArguments[len(spec.ProgramArguments)-2:]).To(Equal([]string{"root@127.0.0.1", "-N"}))
+			}),
+			mockDaemonRunner.EXPECT().Start("org.cloudfoundry.cfdev.proxy"),
+		)
+
+		Expect(os.MkdirAll(cfg.StateLinuxkit, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(cfg.StateLinuxkit, "known_hosts"), []byte("[127.0.0.1]:9992 ssh-ed25519 old"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(cfg.StateDir, "vm_host_key"), []byte("ssh-ed25519 old"), 0600)).To(Succeed())
+
+		Expect(d.Start(2, 4096, 120, "/some/cfdev-efi.iso")).To(Succeed())
+		Expect(sshAttempts).To(Equal(2))
+		Expect(filepath.Join(cfg.StateLinuxkit, "known_hosts")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(cfg.StateDir, "vm_host_key")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(cfg.StateLinuxkit, "rootless")).To(BeAnExistingFile())
+
+		info, err := os.Stat(filepath.Join(cfg.StateDir, "id_rsa"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("stops the proxy and the VM", func() {
+		gomock.InOrder(
+			mockDaemonRunner.EXPECT().Stop("org.cloudfoundry.cfdev.proxy"),
+			mockDaemonRunner.EXPECT().RemoveDaemon("org.cloudfoundry.cfdev.proxy"),
+			mockDaemonRunner.EXPECT().Stop(driver.LinuxKitLabel),
+			mockDaemonRunner.EXPECT().RemoveDaemon(driver.LinuxKitLabel),
+		)
+
+		Expect(d.Stop()).To(Succeed())
+	})
+
+	It("reports the host ports of the SSH forward and the proxy", func() {
+		Expect(d.HostPorts()).To(ContainElement("127.0.0.1:9992"))
+		Expect(d.HostPorts()).To(ContainElement("127.0.0.1:1080"))
+		Expect(d.HostPorts()).To(ContainElement("127.0.0.1:25555"))
+		Expect(d.HostPorts()).To(ContainElement("127.0.0.1:10443"))
+	})
+})
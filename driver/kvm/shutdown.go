@@ -0,0 +1,189 @@
+package kvm
+
+import (
+	"bytes"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/driver"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shutdownVM asks the guest to power off, with an ACPI power button
+// press through the hypervisor or else with poweroff over SSH, and waits
+// up to the shutdown timeout for the VM to exit, so that the guest
+// flushes its disks before the VM is killed. A guest that ignores the
+// power button is powered off over SSH after a quarter of the timeout.
+func (h Host) shutdownVM(cfg config.Config, ui driver.UI, hypervisor string, sshArgs []string) {
+	if !h.vmRunning(cfg) {
+		return
+	}
+
+	var (
+		start    = time.Now()
+		deadline = start.Add(cfg.ShutdownTimeout)
+	)
+
+	if err := powerButton(cfg, hypervisor); err == nil {
+		ui.Say("Shutting down the VM with an ACPI power button press...")
+		if h.waitForExit(cfg, start.Add(cfg.ShutdownTimeout/4)) {
+			ui.Say("The VM shut down after %s.", time.Since(start).Round(time.Second))
+			return
+		}
+
+		// The guest may be shutting down already, it is still
+		// waited for when poweroff cannot be run
+		ui.Say("The VM is still running, powering it off over SSH...")
+		h.sshPoweroff(sshArgs)
+	} else {
+		if sshErr := h.sshPoweroff(sshArgs); sshErr != nil {
+			ui.Say("The VM could not be asked to shut down (%s; %s), stopping it forcefully...", err, sshErr)
+			return
+		}
+
+		ui.Say("Shutting down the VM with poweroff over SSH...")
+	}
+
+	if h.waitForExit(cfg, deadline) {
+		ui.Say("The VM shut down after %s.", time.Since(start).Round(time.Second))
+		return
+	}
+
+	ui.Say("The VM did not shut down within %s, stopping it forcefully...", cfg.ShutdownTimeout)
+}
+
+// waitForExit tells whether the VM exited before the deadline.
+func (h Host) waitForExit(cfg config.Config, deadline time.Time) bool {
+	for ; time.Now().Before(deadline); time.Sleep(250 * time.Millisecond) {
+		if !h.vmRunning(cfg) {
+			return true
+		}
+	}
+
+	return !h.vmRunning(cfg)
+}
+
+// powerButton presses the ACPI power button of the VM
+// through the control socket of its hypervisor.
+func powerButton(cfg config.Config, hypervisor string) error {
+	if hypervisor == CloudHypervisor {
+		client := &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", apiSocket(cfg))
+				},
+			},
+		}
+
+		req, _ := http.NewRequest(http.MethodPut, "http://localhost/api/v1/vm.power-button", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("pressing the power button: %s", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode/100 != 2 {
+			body, _ := ioutil.ReadAll(resp.Body)
+			return fmt.Errorf("pressing the power button: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+
+		return nil
+	}
+
+	conn, err := net.DialTimeout("unix", monitorSocket(cfg), 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("pressing the power button: %s", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("system_powerdown\n")); err != nil {
+		return fmt.Errorf("pressing the power button: %s", err)
+	}
+
+	// The monitor prompts again once it ran the command
+	var (
+		output []byte
+		buf    = make([]byte, 1024)
+	)
+	for bytes.Count(output, []byte("(qemu)")) < 2 {
+		n, err := conn.Read(buf)
+		output = append(output, buf[:n]...)
+		if err != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (h Host) sshPoweroff(sshArgs []string) error {
+	ssh, err := h.findTool("ssh")
+	if err != nil {
+		return err
+	}
+
+	output, err := h.Output(ssh, append(sshArgs, "poweroff")...)
+	if err != nil {
+		return fmt.Errorf("running poweroff over SSH: %s: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// vmRunning tells whether a hypervisor still runs the VM,
+// from the processes that have the disk of the VM open.
+func (h Host) vmRunning(cfg config.Config) bool {
+	entries, err := ioutil.ReadDir(h.path("/proc"))
+	if err != nil {
+		return false
+	}
+
+	disk := []byte(diskPath(cfg))
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil || !entry.IsDir() {
+			continue
+		}
+
+		cmdline, err := ioutil.ReadFile(h.path("/proc", entry.Name(), "cmdline"))
+		if err == nil && bytes.Contains(cmdline, disk) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// monitorSocket is the unix socket of the QEMU monitor.
+func monitorSocket(cfg config.Config) string {
+	return filepath.Join(cfg.StateLinuxkit, "monitor.sock")
+}
+
+// knownHostsPath is the known_hosts file
+// of the SSH connections to the VM.
+func knownHostsPath(cfg config.Config) string {
+	return filepath.Join(cfg.StateLinuxkit, "known_hosts")
+}
+
+// writeKnownHosts trusts the host key pinned for
+// the VM for SSH connections to the given host.
+func writeKnownHosts(cfg config.Config, host string) error {
+	key, err := ioutil.ReadFile(filepath.Join(cfg.StateDir, config.HostKeyFile))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(knownHostsPath(cfg), []byte(host+" "+string(key)), 0600)
+}
+
+// apiSocket is the unix socket of the Cloud Hypervisor API.
+func apiSocket(cfg config.Config) string {
+	return filepath.Join(cfg.StateLinuxkit, "cloud-hypervisor.sock")
+}
@@ -0,0 +1,248 @@
+package kvm_test
+
+import (
+	"bufio"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/driver/kvm"
+	"code.cloudfoundry.org/cfdev/driver/kvm/mocks"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type recordingUI struct {
+	messages *[]string
+}
+
+func (u recordingUI) Say(message string, args ...interface{}) {
+	*u.messages = append(*u.messages, fmt.Sprintf(message, args...))
+}
+
+var _ = Describe("Shutdown", func() {
+	var (
+		mockController   *gomock.Controller
+		mockDaemonRunner *mocks.MockDaemonRunner
+		root             string
+		cfg              config.Config
+		messages         []string
+		poweroff         func() error
+		d                *kvm.Rootless
+	)
+
+	vmProcess := filepath.Join("proc", "4242")
+
+	exit := func() {
+		Expect(os.RemoveAll(filepath.Join(root, vmProcess))).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockDaemonRunner = mocks.NewMockDaemonRunner(mockController)
+		mockDaemonRunner.EXPECT().Stop(gomock.Any()).AnyTimes()
+		mockDaemonRunner.EXPECT().RemoveDaemon(gomock.Any()).AnyTimes()
+
+		var err error
+		root, err = ioutil.TempDir("", "cfdev-kvm-shutdown-")
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg = config.Config{
+			StateDir:        filepath.Join(root, "state"),
+			StateLinuxkit:   filepath.Join(root, "state", "linuxkit"),
+			LogDir:          filepath.Join(root, "log"),
+			ShutdownTimeout: 10 * time.Second,
+		}
+		Expect(os.MkdirAll(cfg.StateLinuxkit, 0755)).To(Succeed())
+
+		cmdline := "qemu-system-x86_64\x00-drive\x00file=" + filepath.Join(cfg.StateLinuxkit, "disk.qcow2") + ",format=qcow2\x00"
+		Expect(os.MkdirAll(filepath.Join(root, vmProcess), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(root, vmProcess, "cmdline"), []byte(cmdline), 0444)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(root, "proc", "1"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(root, "proc", "1", "cmdline"), []byte("/sbin/init\x00"), 0444)).To(Succeed())
+
+		messages = nil
+		poweroff = func() error { return errors.New("connection refused") }
+		d = &kvm.Rootless{
+			UI:           recordingUI{&messages},
+			Config:       cfg,
+			DaemonRunner: mockDaemonRunner,
+			Host: kvm.Host{
+				Root: root,
+				LookPath: func(file string) (string, error) {
+					return "/usr/bin/" + file, nil
+				},
+				Output: func(name string, arg ...string) ([]byte, error) {
+					Expect(name).To(Equal("/usr/bin/ssh"))
+					Expect(arg[len(arg)-2:]).To(Equal([]string{"root@127.0.0.1", "poweroff"}))
+					return nil, poweroff()
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(root)
+	})
+
+	// serveMonitor answers a system_powerdown command on the QEMU monitor
+	// socket, and calls powerdown once the command was run.
+	serveMonitor := func(powerdown func()) net.Listener {
+		listener, err := net.Listen("unix", filepath.Join(cfg.StateLinuxkit, "monitor.sock"))
+		Expect(err).NotTo(HaveOccurred())
+
+		go func() {
+			defer GinkgoRecover()
+
+			conn, err := listener.Accept()
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			fmt.Fprint(conn, "QEMU 2.11.1 monitor - type 'help' for more information\r\n(qemu) ")
+			command, err := bufio.NewReader(conn).ReadString('\n')
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command).To(Equal("system_powerdown\n"))
+			fmt.Fprint(conn, "system_powerdown\r\n(qemu) ")
+
+			powerdown()
+		}()
+
+		return listener
+	}
+
+	It("presses the power button through the QEMU monitor and waits for the VM to exit", func() {
+		listener := serveMonitor(func() {
+			time.Sleep(300 * time.Millisecond)
+			exit()
+		})
+		defer listener.Close()
+
+		Expect(d.Stop()).To(Succeed())
+		Expect(messages).To(ContainElement("Shutting down the VM with an ACPI power button press..."))
+		Expect(messages).To(ContainElement(HavePrefix("The VM shut down after")))
+		Expect(messages).NotTo(ContainElement("The VM is still running, powering it off over SSH..."))
+	})
+
+	It("powers the VM off over SSH when the guest ignores the power button", func() {
+		cfg.ShutdownTimeout = 2 * time.Second
+		d.Config = cfg
+		poweroff = func() error {
+			exit()
+			return nil
+		}
+
+		listener := serveMonitor(func() {})
+		defer listener.Close()
+
+		Expect(d.Stop()).To(Succeed())
+		Expect(messages).To(ConsistOf(
+			"Shutting down the VM with an ACPI power button press...",
+			"The VM is still running, powering it off over SSH...",
+			HavePrefix("The VM shut down after"),
+		))
+	})
+
+	It("powers the VM off over SSH when the monitor cannot be reached", func() {
+		poweroff = func() error {
+			exit()
+			return nil
+		}
+
+		Expect(d.Stop()).To(Succeed())
+		Expect(messages).To(ContainElement("Shutting down the VM with poweroff over SSH..."))
+		Expect(messages).To(ContainElement(HavePrefix("The VM shut down after")))
+	})
+
+	It("stops the VM forcefully when it does not shut down in time", func() {
+		cfg.ShutdownTimeout = 500 * time.Millisecond
+		d.Config = cfg
+		poweroff = func() error { return nil }
+
+		Expect(d.Stop()).To(Succeed())
+		Expect(messages).To(ContainElement("The VM did not shut down within 500ms, stopping it forcefully..."))
+	})
+
+	It("stops the VM forcefully when it cannot be asked to shut down", func() {
+		Expect(d.Stop()).To(Succeed())
+		Expect(messages).To(ConsistOf(MatchRegexp(`^The VM could not be asked to shut down \(.*monitor.sock.*; running poweroff over SSH: connection refused: \), stopping it forcefully...$`)))
+	})
+
+	It("does nothing when the VM does not run", func() {
+		exit()
+
+		Expect(d.Stop()).To(Succeed())
+		Expect(messages).To(BeEmpty())
+	})
+
+	It("only trusts the pinned host key when it powers the VM off over SSH", func() {
+		Expect(ioutil.WriteFile(filepath.Join(cfg.StateDir, "vm_host_key"), []byte("ssh-ed25519 AAAAC3Nz\n"), 0600)).To(Succeed())
+
+		mockRunner := mocks.NewMockRunner(mockController)
+		mockRunner.EXPECT().Run(gomock.Any()).AnyTimes()
+
+		host := d.Host
+		host.Output = func(name string, arg ...string) ([]byte, error) {
+			Expect(name).To(Equal("/usr/bin/ssh"))
+			Expect(arg).To(ContainElement("StrictHostKeyChecking=yes"))
+			Expect(arg).To(ContainElement("UserKnownHostsFile=" + filepath.Join(cfg.StateLinuxkit, "known_hosts")))
+			Expect(arg[len(arg)-2:]).To(Equal([]string{"root@192.168.107.2", "poweroff"}))
+
+			exit()
+			return nil, nil
+		}
+
+		k := &kvm.KVM{
+			UI:           recordingUI{&messages},
+			Config:       cfg,
+			DaemonRunner: mockDaemonRunner,
+			SudoShell:    mockRunner,
+			Host:         host,
+		}
+
+		Expect(k.Stop()).To(Succeed())
+		Expect(messages).To(ContainElement("Shutting down the VM with poweroff over SSH..."))
+
+		knownHosts, err := ioutil.ReadFile(filepath.Join(cfg.StateLinuxkit, "known_hosts"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(knownHosts)).To(Equal("[192.168.107.2]:9992 ssh-ed25519 AAAAC3Nz\n"))
+	})
+
+	It("presses the power button through the Cloud Hypervisor API", func() {
+		listener, err := net.Listen("unix", filepath.Join(cfg.StateLinuxkit, "cloud-hypervisor.sock"))
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		go http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			Expect(r.Method).To(Equal(http.MethodPut))
+			Expect(r.URL.Path).To(Equal("/api/v1/vm.power-button"))
+			w.WriteHeader(http.StatusNoContent)
+			exit()
+		}))
+
+		mockRunner := mocks.NewMockRunner(mockController)
+		mockRunner.EXPECT().Run(gomock.Any()).AnyTimes()
+
+		k := &kvm.KVM{
+			UI:           recordingUI{&messages},
+			Config:       cfg,
+			DaemonRunner: mockDaemonRunner,
+			SudoShell:    mockRunner,
+			Host:         d.Host,
+			Hypervisor:   kvm.CloudHypervisor,
+		}
+
+		Expect(k.Stop()).To(Succeed())
+		Expect(messages).To(ContainElement("Shutting down the VM with an ACPI power button press..."))
+		Expect(messages).To(ContainElement(HavePrefix("The VM shut down after")))
+	})
+})
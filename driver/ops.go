@@ -0,0 +1,12 @@
+// +build !linux
+
+package driver
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"github.com/cppforlife/go-patch/patch"
+)
+
+func NetworkOps(cfg config.Config, name string) (patch.Ops, error) {
+	return nil, nil
+}
@@ -0,0 +1,44 @@
+package driver
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/manifest"
+	"github.com/cppforlife/go-patch/patch"
+)
+
+const (
+	vpnkitNameserverIP = "192.168.65.1"
+	vpnkitHostIP       = "192.168.65.2"
+	vpnkitInternalIP   = "192.168.65.3"
+)
+
+// NetworkOps returns the ops moving the given BOSH manifest from the
+// vpnkit network the assets are built for to the KVM one, where the
+// DHCP server of the bridge is also the nameserver.
+func NetworkOps(cfg config.Config, name string) (patch.Ops, error) {
+	var (
+		guestIP      = KVMGuestIP
+		nameserverIP = KVMBridgeIP
+	)
+
+	// The BOSH Director reaches the VM on its address
+	// on the QEMU user network, not on the forwarded ports
+	if cfg.Rootless {
+		guestIP = RootlessGuestIP
+		nameserverIP = RootlessNameserverIP
+	}
+
+	switch name {
+	case manifest.Director:
+		return patch.Ops{
+			manifest.ReplaceValue{From: vpnkitInternalIP + ":9999", To: guestIP + ":9999", Required: true},
+			manifest.ReplaceValue{From: vpnkitNameserverIP, To: nameserverIP},
+		}, nil
+	case manifest.CloudConfig:
+		return patch.Ops{manifest.ReplaceValue{From: vpnkitNameserverIP, To: nameserverIP}}, nil
+	case manifest.DNSRuntime, manifest.OpsManDNSRuntime:
+		return patch.Ops{manifest.ReplaceValue{From: vpnkitHostIP, To: nameserverIP}}, nil
+	default:
+		return nil, nil
+	}
+}
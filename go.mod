@@ -1,7 +1,7 @@
 module code.cloudfoundry.org/cfdev
 
 require (
-	code.cloudfoundry.org/bytefmt v0.0.0-20180108190415-b31f603f5e1e // indirect
+	code.cloudfoundry.org/bytefmt v0.0.0-20180108190415-b31f603f5e1e
 	code.cloudfoundry.org/cli v6.43.0+incompatible
 	code.cloudfoundry.org/gofileutils v0.0.0-20170111115228-4d0c80011a0f // indirect
 	code.cloudfoundry.org/ykk v0.0.0-20170424192843-e4df4ce2fd4d // indirect
@@ -21,7 +21,7 @@ require (
 	github.com/cloudfoundry/gosigar v1.1.0
 	github.com/cloudfoundry/noaa v2.1.0+incompatible // indirect
 	github.com/cloudfoundry/sonde-go v0.0.0-20171206171820-b33733203bb4 // indirect
-	github.com/cppforlife/go-patch v0.0.0-20171006213518-250da0e0e68c // indirect
+	github.com/cppforlife/go-patch v0.0.0-20171006213518-250da0e0e68c
 	github.com/cyphar/filepath-securejoin v0.2.2 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/denisbrodbeck/machineid v1.0.0
@@ -27,6 +27,7 @@ type Command interface {
 }
 
 type Plugin struct {
+	CLI       *cmd.CliConnection
 	UI        terminal.UI
 	Analytics *cfanalytics.Analytics
 	Root      *cobra.Command
@@ -80,11 +81,13 @@ func main() {
 	defer analyticsClient.Close()
 
 	v := conf.CliVersion
+	cli := &cmd.CliConnection{}
 
 	cfdev := &Plugin{
+		CLI:       cli,
 		UI:        ui,
 		Analytics: analyticsClient,
-		Root:      cmd.NewRoot(exitChan, ui, conf, analyticsClient, analyticsToggle),
+		Root:      cmd.NewRoot(exitChan, ui, conf, analyticsClient, analyticsToggle, cli),
 		Version:   plugin.VersionType{Major: v.Major, Minor: v.Minor, Build: v.Build},
 	}
 
@@ -136,6 +139,7 @@ func (p *Plugin) Run(connection plugin.CliConnection, args []string) {
 		}
 	}
 
+	p.CLI.CliConnection = connection
 	p.Root.SetArgs(args)
 	if err := p.Root.Execute(); err != nil {
 		p.UI.Failed(err.Error())
@@ -0,0 +1,111 @@
+package manifest
+
+import (
+	"fmt"
+	"github.com/cppforlife/go-patch/patch"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"strings"
+)
+
+// The BOSH manifests and configs of CF Dev that can be patched.
+const (
+	Director         = "director"
+	CloudConfig      = "cloud-config"
+	DNSRuntime       = "dns"
+	OpsManDNSRuntime = "ops-manager-dns-runtime"
+)
+
+// Apply patches a YAML document with the given ops.
+// The document is returned untouched when there are no ops.
+func Apply(contents []byte, ops patch.Ops) ([]byte, error) {
+	if len(ops) == 0 {
+		return contents, nil
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return nil, err
+	}
+
+	doc, err := ops.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(doc)
+}
+
+// ReadOpsFiles parses BOSH style ops files. Errors raised while
+// applying their ops name the ops file they come from.
+func ReadOpsFiles(paths ...string) (patch.Ops, error) {
+	var ops patch.Ops
+
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var definitions []patch.OpDefinition
+		if err := yaml.Unmarshal(data, &definitions); err != nil {
+			return nil, fmt.Errorf("invalid ops file %s: %s", path, err)
+		}
+
+		fileOps, err := patch.NewOpsFromDefinitions(definitions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ops file %s: %s", path, err)
+		}
+
+		ops = append(ops, patch.DescriptiveOp{Op: fileOps, ErrorMsg: "ops file " + path})
+	}
+
+	return ops, nil
+}
+
+// ReplaceValue is an op replacing every value of a document equal to From,
+// or in the 'From:port' form, with To. Keys and values merely containing
+// From are left alone.
+type ReplaceValue struct {
+	From     string
+	To       string
+	Required bool
+}
+
+func (op ReplaceValue) Apply(doc interface{}) (interface{}, error) {
+	doc, replaced := op.replace(doc)
+	if op.Required && !replaced {
+		return nil, fmt.Errorf("expected to find the value '%s' to replace", op.From)
+	}
+
+	return doc, nil
+}
+
+func (op ReplaceValue) replace(obj interface{}) (interface{}, bool) {
+	var replaced bool
+
+	switch typedObj := obj.(type) {
+	case map[interface{}]interface{}:
+		for key, value := range typedObj {
+			newValue, ok := op.replace(value)
+			typedObj[key] = newValue
+			replaced = replaced || ok
+		}
+	case []interface{}:
+		for i, value := range typedObj {
+			newValue, ok := op.replace(value)
+			typedObj[i] = newValue
+			replaced = replaced || ok
+		}
+	case string:
+		if typedObj == op.From {
+			return op.To, true
+		}
+
+		if strings.HasPrefix(typedObj, op.From+":") {
+			return op.To + strings.TrimPrefix(typedObj, op.From), true
+		}
+	}
+
+	return obj, replaced
+}
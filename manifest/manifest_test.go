@@ -0,0 +1,122 @@
+package manifest_test
+
+import (
+	"code.cloudfoundry.org/cfdev/manifest"
+	"github.com/cppforlife/go-patch/patch"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manifest", func() {
+	var (
+		tmpDir   string
+		contents = []byte(`---
+name: bosh
+networks:
+- name: default
+  subnets:
+  - dns: [192.168.65.1]
+    range: 10.245.0.0/16
+properties:
+  garden:
+    address: 192.168.65.3:9999
+  comment: the nameserver used to be 192.168.65.1
+  other: 192.168.65.10
+`)
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-manifest-")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	unmarshal := func(data []byte) map[interface{}]interface{} {
+		var doc map[interface{}]interface{}
+		Expect(yaml.Unmarshal(data, &doc)).To(Succeed())
+		return doc
+	}
+
+	It("returns the document untouched when there are no ops", func() {
+		Expect(manifest.Apply(contents, nil)).To(Equal(contents))
+	})
+
+	It("replaces whole values and host:port values only", func() {
+		patched, err := manifest.Apply(contents, patch.Ops{
+			manifest.ReplaceValue{From: "192.168.65.1", To: "192.168.122.1"},
+			manifest.ReplaceValue{From: "192.168.65.3", To: "192.168.122.50", Required: true},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		doc := unmarshal(patched)
+		subnet := doc["networks"].([]interface{})[0].(map[interface{}]interface{})["subnets"].([]interface{})[0].(map[interface{}]interface{})
+		Expect(subnet["dns"]).To(Equal([]interface{}{"192.168.122.1"}))
+
+		properties := doc["properties"].(map[interface{}]interface{})
+		Expect(properties["garden"]).To(Equal(map[interface{}]interface{}{"address": "192.168.122.50:9999"}))
+		Expect(properties["comment"]).To(Equal("the nameserver used to be 192.168.65.1"))
+		Expect(properties["other"]).To(Equal("192.168.65.10"))
+	})
+
+	It("fails when a required value is missing", func() {
+		_, err := manifest.Apply(contents, patch.Ops{
+			manifest.ReplaceValue{From: "10.0.0.1", To: "10.0.0.2", Required: true},
+		})
+		Expect(err).To(MatchError(ContainSubstring("expected to find the value '10.0.0.1'")))
+	})
+
+	It("applies ops files", func() {
+		opsPath := filepath.Join(tmpDir, "ops.yml")
+		Expect(ioutil.WriteFile(opsPath, []byte(`
+- type: replace
+  path: /properties/garden/address
+  value: 10.0.0.5:9999
+- type: remove
+  path: /properties/comment
+`), 0600)).To(Succeed())
+
+		ops, err := manifest.ReadOpsFiles(opsPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		patched, err := manifest.Apply(contents, ops)
+		Expect(err).NotTo(HaveOccurred())
+
+		properties := unmarshal(patched)["properties"].(map[interface{}]interface{})
+		Expect(properties["garden"]).To(Equal(map[interface{}]interface{}{"address": "10.0.0.5:9999"}))
+		Expect(properties).NotTo(HaveKey("comment"))
+	})
+
+	It("names the ops file that cannot be applied", func() {
+		opsPath := filepath.Join(tmpDir, "ops.yml")
+		Expect(ioutil.WriteFile(opsPath, []byte(`
+- type: remove
+  path: /missing
+`), 0600)).To(Succeed())
+
+		ops, err := manifest.ReadOpsFiles(opsPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = manifest.Apply(contents, ops)
+		Expect(err).To(MatchError(ContainSubstring("ops file " + opsPath)))
+	})
+
+	It("rejects invalid ops files", func() {
+		opsPath := filepath.Join(tmpDir, "ops.yml")
+		Expect(ioutil.WriteFile(opsPath, []byte(`
+- type: upsert
+  path: /name
+`), 0600)).To(Succeed())
+
+		_, err := manifest.ReadOpsFiles(opsPath)
+		Expect(err).To(MatchError(ContainSubstring("invalid ops file " + opsPath)))
+	})
+})
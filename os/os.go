@@ -1,24 +1,74 @@
 package os
 
-import "github.com/cloudfoundry/gosigar"
+import (
+	"github.com/cloudfoundry/gosigar"
+	"os"
+	"path/filepath"
+	"runtime"
+)
 
 const bytesInMegabyte = 1048576
 
+// Stats are the resources of the host. The memory and
+// the free disk space of each directory are in MB.
 type Stats struct {
 	AvailableMemory uint64
 	TotalMemory     uint64
+	CPUs            int
+	Load            float64
+	FreeDisk        map[string]uint64
 }
 
 type OS struct{}
 
-func (o *OS) Stats() (Stats, error) {
+// Stats reports the memory, the logical CPUs and the load of the host,
+// and the space free on the disks of the given directories. The load
+// average of the last minute is 0 where it is unknown, as on Windows.
+func (o *OS) Stats(dirs ...string) (Stats, error) {
 	mem := &sigar.Mem{}
 	if err := mem.Get(); err != nil {
 		return Stats{}, err
 	}
 
-	return Stats{
+	stats := Stats{
 		AvailableMemory: mem.ActualFree / bytesInMegabyte,
 		TotalMemory:     mem.Total / bytesInMegabyte,
-	}, nil
+		CPUs:            runtime.NumCPU(),
+		FreeDisk:        map[string]uint64{},
+	}
+
+	load := sigar.LoadAverage{}
+	if err := load.Get(); err == nil {
+		stats.Load = load.One
+	}
+
+	for _, dir := range dirs {
+		free, err := freeDisk(dir)
+		if err != nil {
+			return stats, err
+		}
+
+		stats.FreeDisk[dir] = free
+	}
+
+	return stats, nil
+}
+
+// freeDisk is the space free for the user on the disk of the
+// directory, or of its closest parent when it does not exist yet.
+func freeDisk(dir string) (uint64, error) {
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	usage := sigar.FileSystemUsage{}
+	if err := usage.Get(dir); err != nil {
+		return 0, err
+	}
+
+	return usage.Avail * 1024 / bytesInMegabyte, nil
 }
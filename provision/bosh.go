@@ -1,7 +1,10 @@
 package provision
 
 import (
+	"code.cloudfoundry.org/cfdev/bosh"
 	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 )
 
@@ -9,6 +12,7 @@ const (
 	Preparing     = "preparing"
 	Deploying     = "deploying"
 	RunningErrand = "running-errand"
+	Failed        = "failed"
 )
 
 //go:generate mockgen -package mocks -destination mocks/runner.go code.cloudfoundry.org/cfdev/provision BoshRunner
@@ -16,6 +20,14 @@ type BoshRunner interface {
 	Output(args ...string) ([]byte, error)
 }
 
+//go:generate mockgen -package mocks -destination mocks/director.go code.cloudfoundry.org/cfdev/provision Director
+type Director interface {
+	Tasks(deployment string) ([]bosh.Task, error)
+	TaskEvents(id int) ([]bosh.Event, error)
+	TaskOutput(id int, outputType string) ([]byte, error)
+	CancelTask(id int) error
+}
+
 type Instance struct {
 	ID           string `json:"instance"`
 	Process      string `json:"process"`
@@ -23,14 +35,20 @@ type Instance struct {
 }
 
 type Bosh struct {
-	Runner BoshRunner
+	Runner   BoshRunner
+	Director Director
+
+	lastTasks map[string]int
 }
 
 type VMProgress struct {
 	State    string
+	Stage    string
 	Total    int
 	Done     int
 	Duration time.Duration
+	TaskID   int
+	Error    string
 }
 
 func NewBosh(runner BoshRunner) *Bosh {
@@ -39,14 +57,62 @@ func NewBosh(runner BoshRunner) *Bosh {
 	}
 }
 
+// TrackTasks remembers the latest task of the deployment, so that
+// progress is only reported for the tasks that are started afterwards.
+func (b *Bosh) TrackTasks(deploymentName string) {
+	if b.Director == nil {
+		return
+	}
+
+	tasks, err := b.Director.Tasks(deploymentName)
+	if err != nil {
+		return
+	}
+
+	if b.lastTasks == nil {
+		b.lastTasks = map[string]int{}
+	}
+
+	b.lastTasks[deploymentName] = 0
+	if len(tasks) > 0 {
+		b.lastTasks[deploymentName] = tasks[0].ID
+	}
+}
+
 func (b *Bosh) GetVMProgress(start time.Time, deploymentName string, isErrand bool) VMProgress {
+	if p, ok := b.getTaskProgress(start, deploymentName, isErrand); ok {
+		return p
+	}
+
 	if isErrand {
 		return VMProgress{State: RunningErrand, Duration: time.Now().Sub(start)}
 	}
 
+	instances, err := b.instances(deploymentName)
+	if err != nil || len(instances) == 0 {
+		return VMProgress{State: Preparing, Duration: time.Now().Sub(start)}
+	}
+
+	numDone, total := parseResults(instances)
+	return VMProgress{State: Deploying, Total: total, Done: numDone, Duration: time.Now().Sub(start)}
+}
+
+// Healthy tells whether the deployment has instances
+// and every one of them runs all of its processes.
+func (b *Bosh) Healthy(deploymentName string) bool {
+	instances, err := b.instances(deploymentName)
+	if err != nil || len(instances) == 0 {
+		return false
+	}
+
+	numDone, total := parseResults(instances)
+	return numDone == total
+}
+
+func (b *Bosh) instances(deploymentName string) ([]Instance, error) {
 	output, err := b.Runner.Output("--tty", "-d", deploymentName, "instances", "--ps", "--json")
 	if err != nil {
-		return VMProgress{State: Preparing, Duration: time.Now().Sub(start)}
+		return nil, err
 	}
 
 	var result struct {
@@ -56,12 +122,154 @@ func (b *Bosh) GetVMProgress(start time.Time, deploymentName string, isErrand bo
 	}
 
 	err = json.Unmarshal(output, &result)
-	if err != nil || len(result.Tables) == 0 || len(result.Tables[0].Instances) == 0 {
-		return VMProgress{State: Preparing, Duration: time.Now().Sub(start)}
+	if err != nil || len(result.Tables) == 0 {
+		return nil, err
 	}
 
-	numDone, total := parseResults(result.Tables[0].Instances)
-	return VMProgress{State: Deploying, Total: total, Done: numDone, Duration: time.Now().Sub(start)}
+	return result.Tables[0].Instances, nil
+}
+
+// getTaskProgress reports the progress of the latest task of the deployment
+// from the events of the Director. It is not able to do so before
+// the deployment has started a task or when the Director is unreachable.
+func (b *Bosh) getTaskProgress(start time.Time, deploymentName string, isErrand bool) (VMProgress, bool) {
+	lastTask, tracked := b.lastTasks[deploymentName]
+	if b.Director == nil || !tracked {
+		return VMProgress{}, false
+	}
+
+	tasks, err := b.Director.Tasks(deploymentName)
+	if err != nil || len(tasks) == 0 || tasks[0].ID <= lastTask {
+		return VMProgress{}, false
+	}
+
+	var (
+		task        = tasks[0]
+		events, _   = b.Director.TaskEvents(task.ID)
+		taskSummary = bosh.ProgressOf(events)
+		progress    = VMProgress{
+			State:    Deploying,
+			Stage:    taskSummary.Stage,
+			Total:    taskSummary.Total,
+			Done:     taskSummary.Done,
+			Duration: time.Now().Sub(start),
+			TaskID:   task.ID,
+		}
+	)
+
+	switch {
+	case task.Failed():
+		progress.State = Failed
+		progress.Error = task.Result
+		if len(taskSummary.Errors) > 0 {
+			progress.Error = strings.Join(taskSummary.Errors, "; ")
+		}
+	case isErrand:
+		progress.State = RunningErrand
+	}
+
+	return progress, true
+}
+
+// FailedTask returns the ID and the error events of the latest
+// failed task started by the deployment since TrackTasks.
+func (b *Bosh) FailedTask(deploymentName string) (int, []string, bool) {
+	lastTask, tracked := b.lastTasks[deploymentName]
+	if b.Director == nil || !tracked {
+		return 0, nil, false
+	}
+
+	tasks, err := b.Director.Tasks(deploymentName)
+	if err != nil {
+		return 0, nil, false
+	}
+
+	for _, task := range tasks {
+		if task.ID <= lastTask || !task.Failed() {
+			continue
+		}
+
+		events, _ := b.Director.TaskEvents(task.ID)
+
+		taskErrors := bosh.ProgressOf(events).Errors
+		if len(taskErrors) == 0 && task.Result != "" {
+			taskErrors = []string{task.Result}
+		}
+
+		return task.ID, taskErrors, true
+	}
+
+	return 0, nil, false
+}
+
+func (b *Bosh) TaskDebugOutput(id int) ([]byte, error) {
+	if b.Director == nil {
+		return nil, errors.New("the BOSH Director cannot be reached")
+	}
+
+	return b.Director.TaskOutput(id, "debug")
+}
+
+// CancelTasks cancels the running tasks of the deployment
+// that were started since TrackTasks.
+func (b *Bosh) CancelTasks(deploymentName string) error {
+	if b.Director == nil {
+		return b.cancelTasksWithCLI(deploymentName)
+	}
+
+	tasks, err := b.Director.Tasks(deploymentName)
+	if err != nil {
+		return err
+	}
+
+	lastTask := b.lastTasks[deploymentName]
+	for _, task := range tasks {
+		if task.ID > lastTask && cancellable(task.State) {
+			if err := b.Director.CancelTask(task.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *Bosh) cancelTasksWithCLI(deploymentName string) error {
+	output, err := b.Runner.Output("--json", "-d", deploymentName, "tasks")
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Tables []struct {
+			Rows []struct {
+				ID    string `json:"id"`
+				State string `json:"state"`
+			} `json:"Rows"`
+		} `json:"Tables"`
+	}
+
+	if err := json.Unmarshal(output, &result); err != nil {
+		return err
+	}
+
+	for _, table := range result.Tables {
+		for _, row := range table.Rows {
+			if !cancellable(row.State) {
+				continue
+			}
+
+			if _, err := b.Runner.Output("-n", "cancel-task", row.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func cancellable(state string) bool {
+	return state == bosh.TaskQueued || state == bosh.TaskProcessing
 }
 
 func parseResults(instances []Instance) (int, int) {
@@ -1,12 +1,15 @@
 package provision_test
 
 import (
+	"code.cloudfoundry.org/cfdev/bosh"
 	"code.cloudfoundry.org/cfdev/provision"
 	"code.cloudfoundry.org/cfdev/provision/mocks"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	"strings"
 	"time"
 )
 
@@ -255,4 +258,164 @@ var _ = Describe("Bosh", func() {
 			})
 		})
 	})
+
+	Describe("GetVMProgress with a Director", func() {
+		var (
+			b              provision.Bosh
+			mockController *gomock.Controller
+			mockRunner     *mocks.MockBoshRunner
+			mockDirector   *mocks.MockDirector
+		)
+
+		BeforeEach(func() {
+			mockController = gomock.NewController(GinkgoT())
+			mockRunner = mocks.NewMockBoshRunner(mockController)
+			mockDirector = mocks.NewMockDirector(mockController)
+
+			b = provision.Bosh{
+				Runner:   mockRunner,
+				Director: mockDirector,
+			}
+
+			mockDirector.EXPECT().Tasks("some-deployment").Return([]bosh.Task{{ID: 3, State: bosh.TaskDone}}, nil)
+			b.TrackTasks("some-deployment")
+		})
+
+		AfterEach(func() {
+			mockController.Finish()
+		})
+
+		It("reports the stage of the task started by the deployment", func() {
+			mockDirector.EXPECT().Tasks("some-deployment").Return([]bosh.Task{{ID: 4, State: bosh.TaskProcessing}}, nil)
+			mockDirector.EXPECT().TaskEvents(4).Return([]bosh.Event{
+				{Stage: "Compiling packages", Total: 2, Task: "golang/1", Index: 1, State: "finished"},
+			}, nil)
+
+			result := b.GetVMProgress(time.Now(), "some-deployment", false)
+
+			Expect(result.State).To(Equal(provision.Deploying))
+			Expect(result.Stage).To(Equal("Compiling packages"))
+			Expect(result.Done).To(Equal(1))
+			Expect(result.Total).To(Equal(2))
+			Expect(result.TaskID).To(Equal(4))
+		})
+
+		It("reports a failed task right away", func() {
+			mockDirector.EXPECT().Tasks("some-deployment").Return([]bosh.Task{{ID: 4, State: bosh.TaskError, Result: "some-result"}}, nil)
+			mockDirector.EXPECT().TaskEvents(4).Return([]bosh.Event{
+				{Error: &bosh.EventError{Message: "some-error"}},
+			}, nil)
+
+			result := b.GetVMProgress(time.Now(), "some-deployment", false)
+
+			Expect(result.State).To(Equal(provision.Failed))
+			Expect(result.Error).To(Equal("some-error"))
+		})
+
+		It("finds the failed task of the deployment", func() {
+			mockDirector.EXPECT().Tasks("some-deployment").Return([]bosh.Task{
+				{ID: 5, State: bosh.TaskDone},
+				{ID: 4, State: bosh.TaskError, Result: "some-result"},
+				{ID: 3, State: bosh.TaskError},
+			}, nil)
+			mockDirector.EXPECT().TaskEvents(4).Return(nil, nil)
+
+			id, taskErrors, failed := b.FailedTask("some-deployment")
+
+			Expect(failed).To(BeTrue())
+			Expect(id).To(Equal(4))
+			Expect(taskErrors).To(Equal([]string{"some-result"}))
+		})
+
+		It("cancels the running tasks started by the deployment", func() {
+			mockDirector.EXPECT().Tasks("some-deployment").Return([]bosh.Task{
+				{ID: 6, State: bosh.TaskProcessing},
+				{ID: 5, State: bosh.TaskCancelling},
+				{ID: 4, State: bosh.TaskDone},
+				{ID: 3, State: bosh.TaskProcessing},
+			}, nil)
+			mockDirector.EXPECT().CancelTask(6)
+
+			Expect(b.CancelTasks("some-deployment")).To(Succeed())
+		})
+
+		It("falls back to the BOSH CLI until the deployment has started a task", func() {
+			mockDirector.EXPECT().Tasks("some-deployment").Return([]bosh.Task{{ID: 3, State: bosh.TaskDone}}, nil)
+			mockRunner.EXPECT().Output(gomock.Any()).Return(nil, errors.New(""))
+
+			result := b.GetVMProgress(time.Now(), "some-deployment", false)
+
+			Expect(result.State).To(Equal(provision.Preparing))
+		})
+	})
+
+	Describe("CancelTasks without a Director", func() {
+		It("cancels the running tasks with the BOSH CLI", func() {
+			mockController := gomock.NewController(GinkgoT())
+			defer mockController.Finish()
+
+			mockRunner := mocks.NewMockBoshRunner(mockController)
+			b := provision.NewBosh(mockRunner)
+
+			gomock.InOrder(
+				mockRunner.EXPECT().Output("--json", "-d", "some-deployment", "tasks").Return([]byte(`{
+					"Tables": [{"Rows": [
+						{"id": "8", "state": "queued"},
+						{"id": "7", "state": "processing"},
+						{"id": "6", "state": "cancelling"}
+					]}]
+				}`), nil),
+				mockRunner.EXPECT().Output("-n", "cancel-task", "8"),
+				mockRunner.EXPECT().Output("-n", "cancel-task", "7"),
+			)
+
+			Expect(b.CancelTasks("some-deployment")).To(Succeed())
+		})
+	})
+
+	Describe("Healthy", func() {
+		var (
+			mockController *gomock.Controller
+			mockRunner     *mocks.MockBoshRunner
+			b              *provision.Bosh
+		)
+
+		BeforeEach(func() {
+			mockController = gomock.NewController(GinkgoT())
+			mockRunner = mocks.NewMockBoshRunner(mockController)
+			b = provision.NewBosh(mockRunner)
+		})
+
+		AfterEach(func() {
+			mockController.Finish()
+		})
+
+		instances := func(states ...string) []byte {
+			var rows []string
+			for i, state := range states {
+				rows = append(rows, fmt.Sprintf(`{"instance": "vm/%d", "process": "process", "process_state": "%s"}`, i, state))
+			}
+			return []byte(`{"Tables": [{"Rows": [` + strings.Join(rows, ",") + `]}]}`)
+		}
+
+		It("is healthy when every instance runs all of its processes", func() {
+			mockRunner.EXPECT().Output("--tty", "-d", "cf", "instances", "--ps", "--json").Return(instances("running", "running"), nil)
+
+			Expect(b.Healthy("cf")).To(BeTrue())
+		})
+
+		It("is not healthy when a process does not run", func() {
+			mockRunner.EXPECT().Output("--tty", "-d", "cf", "instances", "--ps", "--json").Return(instances("running", "failing"), nil)
+
+			Expect(b.Healthy("cf")).To(BeFalse())
+		})
+
+		It("is not healthy without instances, or when they cannot be listed", func() {
+			mockRunner.EXPECT().Output("--tty", "-d", "cf", "instances", "--ps", "--json").Return([]byte(`{"Tables": []}`), nil)
+			Expect(b.Healthy("cf")).To(BeFalse())
+
+			mockRunner.EXPECT().Output("--tty", "-d", "cf", "instances", "--ps", "--json").Return(nil, errors.New("no deployment"))
+			Expect(b.Healthy("cf")).To(BeFalse())
+		})
+	})
 })
@@ -0,0 +1,85 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deployment cancellation", func() {
+	var (
+		tmpDir  string
+		c       *provision.Controller
+		service workspace.Service
+	)
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("the service scripts are powershell scripts on windows")
+		}
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-cancellation-")
+		Expect(err).NotTo(HaveOccurred())
+
+		c = provision.NewController(config.Config{
+			BinaryDir:   tmpDir,
+			LogDir:      tmpDir,
+			StateDir:    tmpDir,
+			ServicesDir: tmpDir,
+			StateBosh:   filepath.Join(tmpDir, "bosh"),
+		}, nil)
+
+		// The script spawns a process that outlives it unless
+		// the whole process group is killed
+		script := "#!/bin/sh\n(sleep 1; touch " + filepath.Join(tmpDir, "still-running") + ") &\nsleep 30\n"
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "deploy-mysql"), []byte(script), 0755)).To(Succeed())
+
+		service = workspace.Service{Name: "Mysql", Script: "deploy-mysql", Deployment: "cf-mysql"}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	expectIncomplete := func() {
+		time.Sleep(1500 * time.Millisecond)
+		Expect(filepath.Join(tmpDir, "still-running")).NotTo(BeAnExistingFile())
+
+		states, err := c.Workspace.ServiceStates()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states).To(HaveLen(1))
+		Expect(states[0].Name).To(Equal("Mysql"))
+		Expect(states[0].State).To(Equal(workspace.ServiceIncomplete))
+	}
+
+	It("kills the script and records the service as incomplete when cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(200*time.Millisecond, cancel)
+
+		err := c.DeployServices(ctx, fakeUI{}, []workspace.Service{service}, nil)
+		Expect(err).To(MatchError(ContainSubstring("the deployment was cancelled")))
+		Expect(errors.SafeError(err)).To(Equal("Failed to deploy Mysql"))
+
+		expectIncomplete()
+	})
+
+	It("applies the timeout of the service", func() {
+		service.Timeout = 200 * time.Millisecond
+
+		err := c.DeployServices(context.Background(), fakeUI{}, []workspace.Service{service}, nil)
+		Expect(err).To(MatchError(ContainSubstring("the deployment timed out after 200ms")))
+
+		expectIncomplete()
+	})
+})
@@ -1,29 +1,47 @@
 package provision
 
 import (
+	"code.cloudfoundry.org/cfdev/bosh"
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/driver"
+	"code.cloudfoundry.org/cfdev/runner"
 	"code.cloudfoundry.org/cfdev/workspace"
 	"context"
+	"fmt"
 	"github.com/aemengo/bosh-runc-cpi/client"
 	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
 	"time"
 )
 
+// CancelGracePeriod is the time the deployment or the BOSH Director
+// step in progress is given to be cancelled on exit, before the
+// commands stop the VM or exit.
+const CancelGracePeriod = 30 * time.Second
+
 type UI interface {
 	Say(message string, args ...interface{})
 	Writer() io.Writer
 }
 
+//go:generate mockgen -package mocks -destination mocks/vm.go code.cloudfoundry.org/cfdev/provision VM
+type VM interface {
+	Address(ctx context.Context) (net.IP, error)
+}
+
 type Controller struct {
 	Config    config.Config
 	Workspace *workspace.Workspace
+	VM        VM
 }
 
-func NewController(config config.Config) *Controller {
+func NewController(config config.Config, vm VM) *Controller {
 	return &Controller{
 		Config:    config,
 		Workspace: workspace.New(config),
+		VM:        vm,
 	}
 }
 
@@ -37,18 +55,55 @@ func (c *Controller) Ping(duration time.Duration) error {
 	for {
 		select {
 		case <-ticker.C:
-			var ip string
-			ip, err = driver.IP(c.Config)
-			if err != nil {
-				continue
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+			var ip net.IP
+			ip, err = c.VM.Address(ctx)
+			if err == nil {
+				err = client.Ping(ctx, net.JoinHostPort(ip.String(), "9999"))
 			}
 
-			err = client.Ping(context.Background(), ip+":9999")
+			cancel()
 			if err == nil {
 				return nil
 			}
 		case <-timeout:
+			if err == nil {
+				err = fmt.Errorf("no response after %s", duration)
+			}
+
+			if tail := driver.ConsoleTail(c.Config, 20); tail != "" {
+				err = fmt.Errorf("%s\n\nLast lines of the VM console (%s):\n%s", err, driver.ConsoleLog(c.Config), tail)
+			}
+
 			return err
 		}
 	}
 }
+
+// connect opens an SSH session to the VM.
+func (c *Controller) connect(ctx context.Context, stdout io.Writer, stderr io.Writer) (*SSH, error) {
+	ip, err := c.VM.Address(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ioutil.ReadFile(filepath.Join(c.Config.StateDir, "id_rsa"))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSSH(ctx, ip.String(), "9992", key, c.Workspace, stdout, stderr)
+}
+
+// newBosh reports progress from the Director API when it can be reached,
+// and from the BOSH CLI otherwise.
+func (c *Controller) newBosh() *Bosh {
+	b := NewBosh(runner.NewBosh(c.Config))
+
+	if director, err := bosh.New(c.Workspace.EnvsMapping()); err == nil {
+		b.Director = director
+	}
+
+	return b
+}
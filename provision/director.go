@@ -1,23 +1,19 @@
 package provision
 
 import (
-	"bytes"
 	"code.cloudfoundry.org/cfdev/driver"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/manifest"
 	"code.cloudfoundry.org/cfdev/runner"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"github.com/cppforlife/go-patch/patch"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"runtime"
 	"time"
 )
 
-const (
-	vpnkitNameserverIP = "192.168.65.1"
-	vpnkitHostIP       = "192.168.65.2"
-	vpnkitInternalIP   = "192.168.65.3"
-	kvmNameserverIP    = "192.168.122.1"
-)
-
 func (c *Controller) DeployBosh() error {
 	var (
 		credsPath           = filepath.Join(c.Config.StateBosh, "creds.yml")
@@ -35,25 +31,19 @@ func (c *Controller) DeployBosh() error {
 			_, err := os.Stat(credsPath)
 			return os.IsNotExist(err)
 		}
+		start = time.Now()
 	)
 
-	ip, err := driver.IP(c.Config)
-	if err != nil {
-		return err
-	}
-
 	logFile, err := os.Create(filepath.Join(c.Config.LogDir, "deploy-bosh.log"))
 	if err != nil {
 		return err
 	}
 	defer logFile.Close()
 
-	key, err := ioutil.ReadFile(filepath.Join(c.Config.StateDir, "id_rsa"))
-	if err != nil {
-		return err
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
 
-	s, err := NewSSH(ip, "9992", key, 20*time.Second, logFile, logFile)
+	s, err := c.connect(ctx, logFile, logFile)
 	if err != nil {
 		return err
 	}
@@ -64,118 +54,144 @@ func (c *Controller) DeployBosh() error {
 		return err
 	}
 
-	if runtime.GOOS == "linux" {
-		directorContents = bytes.Replace(directorContents, []byte(vpnkitInternalIP+":9999"), []byte(ip+":9999"), -1)
-
-		directorContents = bytes.Replace(directorContents, []byte(vpnkitNameserverIP), []byte(kvmNameserverIP), -1)
+	directorContents, err = c.patchManifest(manifest.Director, directorContents)
+	if err != nil {
+		return err
 	}
 
-	s.SendData(directorContents, "director.yml")
+	transferCtx, cancelTransfer := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelTransfer()
+
+	s.SendData(transferCtx, directorContents, "director.yml")
 
-	s.SendFile(stateJSONPath, "state.json")
+	s.SendFile(transferCtx, stateJSONPath, "state.json")
 
 	command := "/usr/local/bin/bosh --tty create-env director.yml --state state.json"
 
 	if !credhubIsDeployed() {
-		s.SendFile(credsPath, "creds.yml")
+		s.SendFile(transferCtx, credsPath, "creds.yml")
 
 		command = command + " --vars-store creds.yml"
 	}
 
-	// Added the time because we were seeing some delay
-	// between the time the container was started
-	// and the time it could access the internet
-	// Find a better solution
-	time.Sleep(7 * time.Second)
+	if s.Error != nil {
+		return s.Error
+	}
+
+	// The container cannot always access the internet right after it started
+	probeCtx, cancelProbe := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancelProbe()
+
+	if err := NewReadinessProbe(c.Config.BuildProxyConfig(), c.Config.ProbeHost).Wait(probeCtx, s); err != nil {
+		return err
+	}
+
+	createEnvCtx, cancelCreateEnv := context.WithTimeout(context.Background(), 20*time.Minute)
+	defer cancelCreateEnv()
 
-	s.Run(command)
+	s.Run(createEnvCtx, command)
 
-	s.RetrieveFile(stateJSONPath, "state.json")
+	retrieveCtx, cancelRetrieve := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelRetrieve()
+
+	s.RetrieveFile(retrieveCtx, stateJSONPath, "state.json")
 	if s.Error != nil {
 		return s.Error
 	}
 
-	if runtime.GOOS == "linux" {
-		err = c.updateCloudConfig(boshRunner, cloudConfigPath)
-		if err != nil {
-			return err
-		}
+	err = c.updateConfig(boshRunner, manifest.CloudConfig, cloudConfigPath, "update-cloud-config")
+	if err != nil {
+		return err
+	}
 
-		err = c.updateDNSRuntime(boshRunner, dnsConfigPath)
-		if err != nil {
-			return err
-		}
+	err = c.updateConfig(boshRunner, manifest.DNSRuntime, dnsConfigPath, "update-runtime-config")
+	if err != nil {
+		return err
+	}
 
-		err = c.updateOpsManDNSRuntime(boshRunner, opsManDnsConfigPath)
-		if err != nil {
-			return err
-		}
+	err = c.updateConfig(boshRunner, manifest.OpsManDNSRuntime, opsManDnsConfigPath, "update-config", "--name", "ops_manager_dns_runtime", "--type", "runtime")
+	if err != nil {
+		return err
 	}
 
+	c.Workspace.RecordDuration(workspace.DurationBosh, time.Now().Sub(start))
 	return nil
 }
 
-func (c *Controller) updateCloudConfig(boshRunner *runner.Bosh, path string) error {
-	cloudConfigContents, err := ioutil.ReadFile(path)
+// updateConfig patches a config of the Director and updates it when
+// there are ops for it. The patched config goes to a temporary file,
+// so that the extracted one is patched afresh on the next start. The DNS
+// runtime configs are not part of every version of the assets, missing
+// configs are skipped.
+func (c *Controller) updateConfig(boshRunner *runner.Bosh, name string, path string, command ...string) error {
+	ops, err := c.manifestOps(name)
 	if err != nil {
 		return err
 	}
 
-	cloudConfigContents = bytes.Replace(cloudConfigContents, []byte(vpnkitNameserverIP), []byte(kvmNameserverIP), -1)
+	if len(ops) == 0 {
+		return nil
+	}
 
-	err = ioutil.WriteFile(path, cloudConfigContents, 0600)
-	if err != nil {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && name != manifest.CloudConfig {
+		return nil
+	} else if err != nil {
 		return err
 	}
 
-	_, err = boshRunner.Output("-n", "update-cloud-config", path)
-	return err
-}
-
-func (c *Controller) updateDNSRuntime(boshRunner *runner.Bosh, path string) error {
-	_, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		// dns.yml does not exist
-		// skipping in favor of next DNS runtime method updater
-		return nil
+	contents, err = manifest.Apply(contents, ops)
+	if err != nil {
+		return e.SafeWrap(err, "Failed to patch "+filepath.Base(path))
 	}
 
-	dnsConfigContents, err := ioutil.ReadFile(path)
+	tmpDir, err := ioutil.TempDir("", "cfdev-config-")
 	if err != nil {
 		return err
 	}
+	defer os.RemoveAll(tmpDir)
 
-	dnsConfigContents = bytes.Replace(dnsConfigContents, []byte(vpnkitHostIP), []byte(kvmNameserverIP), -1)
-
-	err = ioutil.WriteFile(path, dnsConfigContents, 0600)
+	patchedPath := filepath.Join(tmpDir, filepath.Base(path))
+	err = ioutil.WriteFile(patchedPath, contents, 0600)
 	if err != nil {
 		return err
 	}
 
-	_, err = boshRunner.Output("-n", "update-runtime-config", path)
+	_, err = boshRunner.Output(append(append([]string{"-n"}, command...), patchedPath)...)
 	return err
 }
 
-func (c *Controller) updateOpsManDNSRuntime(boshRunner *runner.Bosh, path string) error {
-	_, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		// dns.yml does not exist
-		// skipping in favor of next DNS runtime method updater
-		return nil
+func (c *Controller) patchManifest(name string, contents []byte) ([]byte, error) {
+	ops, err := c.manifestOps(name)
+	if err != nil {
+		return nil, err
 	}
 
-	dnsConfigContents, err := ioutil.ReadFile(path)
+	contents, err = manifest.Apply(contents, ops)
 	if err != nil {
-		return err
+		return nil, e.SafeWrap(err, "Failed to patch the "+name+" manifest")
 	}
 
-	dnsConfigContents = bytes.Replace(dnsConfigContents, []byte(vpnkitHostIP), []byte(kvmNameserverIP), -1)
+	return contents, nil
+}
 
-	err = ioutil.WriteFile(path, dnsConfigContents, 0600)
+// manifestOps returns the network ops of the driver
+// followed by the ops files of the user.
+func (c *Controller) manifestOps(name string) (patch.Ops, error) {
+	ops, err := driver.NetworkOps(c.Config, name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = boshRunner.Output("-n", "update-config", "--name", "ops_manager_dns_runtime", "--type", "runtime", path)
-	return err
+	paths, err := c.Workspace.OpsFiles(name)
+	if err != nil {
+		return nil, err
+	}
+
+	userOps, err := manifest.ReadOpsFiles(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ops, userOps...), nil
 }
@@ -0,0 +1,99 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileSystem is a file system of the VM, on one of its disks.
+type FileSystem struct {
+	Device     string
+	MountPoint string
+	Size       uint64
+	Used       uint64
+}
+
+// GuestDiskUsage returns the usage of the file systems
+// on the disks of the VM, as reported by the VM.
+func (c *Controller) GuestDiskUsage() ([]FileSystem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	s, err := c.connect(ctx, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	return DiskFree(ctx, s)
+}
+
+// TrimDisk discards the blocks the file systems of the VM no longer
+// use, so that its disk can be compacted. It returns what was trimmed.
+func (c *Controller) TrimDisk() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	s, err := c.connect(ctx, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+
+	return Trim(ctx, s)
+}
+
+// DiskFree lists the file systems on the disks of the VM, once each.
+func DiskFree(ctx context.Context, runner CommandRunner) ([]FileSystem, error) {
+	output, err := runner.Output(ctx, "df -kP")
+	if err != nil {
+		return nil, fmt.Errorf("getting the disk usage of the VM: %s", describe(output, err))
+	}
+
+	var (
+		fileSystems []FileSystem
+		seen        = map[string]bool{}
+	)
+
+	for _, line := range strings.Split(string(output), "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || !strings.HasPrefix(fields[0], "/dev/") || strings.HasPrefix(fields[0], "/dev/loop") || seen[fields[0]] {
+			continue
+		}
+
+		size, sizeErr := strconv.ParseUint(fields[1], 10, 64)
+		used, usedErr := strconv.ParseUint(fields[2], 10, 64)
+		if sizeErr != nil || usedErr != nil {
+			continue
+		}
+
+		seen[fields[0]] = true
+		fileSystems = append(fileSystems, FileSystem{
+			Device:     fields[0],
+			MountPoint: strings.Join(fields[5:], " "),
+			Size:       size * 1024,
+			Used:       used * 1024,
+		})
+	}
+
+	if len(fileSystems) == 0 {
+		return nil, errors.New("the VM reported no file system on its disks")
+	}
+
+	return fileSystems, nil
+}
+
+// Trim runs fstrim on every mounted file system of the VM that supports it.
+func Trim(ctx context.Context, runner CommandRunner) (string, error) {
+	output, err := runner.Output(ctx, "fstrim -av")
+	if err != nil {
+		return "", fmt.Errorf("trimming the disk of the VM: %s", describe(output, err))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,66 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/provision/mocks"
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Disk", func() {
+	var (
+		mockController *gomock.Controller
+		mockRunner     *mocks.MockCommandRunner
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockRunner = mocks.NewMockCommandRunner(mockController)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("lists the file systems on the disks of the VM once each", func() {
+		mockRunner.EXPECT().Output(gomock.Any(), "df -kP").Return([]byte(`Filesystem     1024-blocks     Used Available Capacity Mounted on
+overlay            4010044   123456   3886588       4% /
+tmpfs              2048000        0   2048000       0% /dev/shm
+/dev/vda1        123329088 41109696  75941292      36% /var/lib
+/dev/vda1        123329088 41109696  75941292      36% /var/vcap/data
+/dev/loop0          102400   102400         0     100% /var/lib/garden/images
+`), nil)
+
+		fileSystems, err := provision.DiskFree(context.Background(), mockRunner)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fileSystems).To(Equal([]provision.FileSystem{
+			{Device: "/dev/vda1", MountPoint: "/var/lib", Size: 123329088 * 1024, Used: 41109696 * 1024},
+		}))
+	})
+
+	It("fails when the VM reports no disk", func() {
+		mockRunner.EXPECT().Output(gomock.Any(), "df -kP").Return([]byte("Filesystem 1024-blocks Used Available Capacity Mounted on\n"), nil)
+
+		_, err := provision.DiskFree(context.Background(), mockRunner)
+		Expect(err).To(MatchError("the VM reported no file system on its disks"))
+	})
+
+	It("trims the file systems of the VM", func() {
+		mockRunner.EXPECT().Output(gomock.Any(), "fstrim -av").Return([]byte("/var/lib: 12 GiB (12884901888 bytes) trimmed\n"), nil)
+
+		output, err := provision.Trim(context.Background(), mockRunner)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(Equal("/var/lib: 12 GiB (12884901888 bytes) trimmed"))
+	})
+
+	It("reports why trimming failed", func() {
+		mockRunner.EXPECT().Output(gomock.Any(), "fstrim -av").Return([]byte("fstrim: not found\n"), errors.New("exit status 127"))
+
+		_, err := provision.Trim(context.Background(), mockRunner)
+		Expect(err).To(MatchError("trimming the disk of the VM: fstrim: not found"))
+	})
+})
@@ -0,0 +1,98 @@
+package provision
+
+import (
+	"bufio"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const logTailLines = 20
+
+// DeploymentError carries the details needed to understand why a service
+// failed to deploy. Only the wrapped summary is meant to leave the
+// machine, through errors.SafeError.
+type DeploymentError struct {
+	Err        error
+	TaskID     int
+	TaskErrors []string
+	TaskLog    string
+	Log        string
+	LogTail    []string
+}
+
+func (e *DeploymentError) Error() string {
+	message := e.Err.Error()
+
+	if e.TaskID != 0 {
+		message += fmt.Sprintf("\n\nBOSH task %d failed:", e.TaskID)
+		for _, taskError := range e.TaskErrors {
+			message += "\n  " + taskError
+		}
+
+		if e.TaskLog != "" {
+			message += fmt.Sprintf("\nThe debug output of the task was saved to %s", e.TaskLog)
+		}
+	}
+
+	if len(e.LogTail) > 0 {
+		message += fmt.Sprintf("\n\nLast %d lines of %s:\n  %s", len(e.LogTail), e.Log, strings.Join(e.LogTail, "\n  "))
+	}
+
+	return message
+}
+
+func (c *Controller) deploymentError(b *Bosh, service workspace.Service, err error) error {
+	deploymentErr := &DeploymentError{
+		Err: err,
+		Log: c.deployLogPath(service.Name),
+	}
+
+	deploymentErr.LogTail, _ = tail(deploymentErr.Log, logTailLines)
+
+	var failed bool
+	deploymentErr.TaskID, deploymentErr.TaskErrors, failed = b.FailedTask(service.Deployment)
+	if !failed {
+		return deploymentErr
+	}
+
+	if output, err := b.TaskDebugOutput(deploymentErr.TaskID); err == nil {
+		taskLog := filepath.Join(c.Config.LogDir, fmt.Sprintf("deploy-%s-task-%d.log", strings.ToLower(service.Name), deploymentErr.TaskID))
+
+		if ioutil.WriteFile(taskLog, output, 0600) == nil {
+			deploymentErr.TaskLog = taskLog
+		}
+	}
+
+	return deploymentErr
+}
+
+func (c *Controller) deployLogPath(name string) string {
+	return filepath.Join(c.Config.LogDir, "deploy-"+strings.ToLower(name)+".log")
+}
+
+func tail(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		lines   []string
+		scanner = bufio.NewScanner(f)
+	)
+
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+
+	return lines, scanner.Err()
+}
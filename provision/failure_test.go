@@ -0,0 +1,88 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeUI struct{}
+
+func (fakeUI) Say(message string, args ...interface{}) {}
+func (fakeUI) Writer() io.Writer                       { return ioutil.Discard }
+
+var _ = Describe("Deployment failures", func() {
+	var (
+		tmpDir string
+		c      *provision.Controller
+	)
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("the service scripts are powershell scripts on windows")
+		}
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-failure-")
+		Expect(err).NotTo(HaveOccurred())
+
+		c = provision.NewController(config.Config{
+			LogDir:      tmpDir,
+			StateDir:    tmpDir,
+			ServicesDir: tmpDir,
+			StateBosh:   filepath.Join(tmpDir, "bosh"),
+		}, nil)
+
+		var script []string
+		for i := 1; i <= 30; i++ {
+			script = append(script, fmt.Sprintf("echo line-%d", i))
+		}
+
+		contents := "#!/bin/sh\n" + strings.Join(script, "\n") + "\nexit 1\n"
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "deploy-mysql"), []byte(contents), 0755)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("attaches the end of the deploy log but keeps it out of the safe error", func() {
+		err := c.DeployServices(context.Background(), fakeUI{}, []workspace.Service{{Name: "Mysql", Script: "deploy-mysql", Deployment: "cf-mysql"}}, nil)
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("exit status 1"))
+		Expect(err.Error()).To(ContainSubstring("Last 20 lines of " + filepath.Join(tmpDir, "deploy-mysql.log")))
+		Expect(err.Error()).To(ContainSubstring("line-11\n  line-12"))
+		Expect(err.Error()).To(HaveSuffix("line-30"))
+		Expect(err.Error()).NotTo(ContainSubstring("line-10\n"))
+		Expect(errors.SafeError(err)).To(Equal("Failed to deploy Mysql"))
+	})
+})
+
+var _ = Describe("DeploymentError", func() {
+	It("lists the errors of the failed BOSH task", func() {
+		err := &provision.DeploymentError{
+			Err:        fmt.Errorf("exit status 1"),
+			TaskID:     42,
+			TaskErrors: []string{"Updating instance: api/1 (0): some-failure"},
+			TaskLog:    "/some/deploy-cf-task-42.log",
+		}
+
+		Expect(err.Error()).To(Equal("exit status 1\n\n" +
+			"BOSH task 42 failed:\n" +
+			"  Updating instance: api/1 (0): some-failure\n" +
+			"The debug output of the task was saved to /some/deploy-cf-task-42.log"))
+	})
+})
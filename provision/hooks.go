@@ -0,0 +1,86 @@
+package provision
+
+import (
+	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+var unsafeLogChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RunHooks runs the user hooks of a hook point, in the same environment
+// as the service scripts. A failing hook only produces a warning,
+// unless it is declared as fatal.
+func (c *Controller) RunHooks(ui UI, point string) error {
+	return c.runHooks(ui, point)
+}
+
+func (c *Controller) runHooks(ui UI, points ...string) error {
+	hooks, err := c.Workspace.Hooks(points...)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		ui.Say("Running %s hook %s...", hook.Point, hook.Name)
+
+		logPath := c.hookLogPath(hook)
+		if err := c.runHook(hook, logPath); err != nil {
+			if hook.Fatal {
+				return fmt.Errorf("the %s hook %s failed: %s. See %s", hook.Point, hook.Name, err, logPath)
+			}
+
+			ui.Say("WARNING: the %s hook %s failed: %s. See %s", hook.Point, hook.Name, err, logPath)
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) runHook(hook workspace.Hook, logPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hook.Timeout)
+	defer cancel()
+
+	cmd := hookCommand(ctx, hook)
+	cmd.Env = append(c.scriptEnvs(), "CFDEV_HOOK="+hook.Point)
+
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", hook.Timeout)
+	}
+
+	return err
+}
+
+func (c *Controller) hookLogPath(hook workspace.Hook) string {
+	name := unsafeLogChars.ReplaceAllString(strings.ToLower(hook.Point+"-"+hook.Name), "-")
+	return filepath.Join(c.Config.LogDir, "hook-"+name+".log")
+}
+
+func hookCommand(ctx context.Context, hook workspace.Hook) *exec.Cmd {
+	switch {
+	case hook.Script != "" && runtime.GOOS == "windows" && strings.EqualFold(filepath.Ext(hook.Script), ".ps1"):
+		return exec.CommandContext(ctx, "powershell.exe", "-ExecutionPolicy", "Bypass", "-File", hook.Script)
+	case hook.Script != "":
+		return exec.CommandContext(ctx, hook.Script)
+	case runtime.GOOS == "windows":
+		return exec.CommandContext(ctx, "powershell.exe", "-ExecutionPolicy", "Bypass", "-Command", hook.Command)
+	default:
+		return exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	}
+}
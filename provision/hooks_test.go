@@ -0,0 +1,124 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type recordingUI struct {
+	messages []string
+}
+
+func (r *recordingUI) Say(message string, args ...interface{}) {
+	r.messages = append(r.messages, fmt.Sprintf(message, args...))
+}
+
+func (r *recordingUI) Writer() io.Writer { return ioutil.Discard }
+
+var _ = Describe("Hooks", func() {
+	var (
+		tmpDir string
+		ui     *recordingUI
+		c      *provision.Controller
+	)
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("the hooks are powershell scripts on windows")
+		}
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-hooks-")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(tmpDir, "log"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(tmpDir, "state", "bosh"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "state", "bosh", "env.yml"), []byte("BOSH_ENVIRONMENT: 10.144.0.2\n"), 0600)).To(Succeed())
+
+		ui = &recordingUI{}
+		c = provision.NewController(config.Config{
+			CFDevHome: tmpDir,
+			LogDir:    filepath.Join(tmpDir, "log"),
+			StateBosh: filepath.Join(tmpDir, "state", "bosh"),
+			CFDomain:  "dev.cfdev.sh",
+		}, nil)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	writeHooks := func(contents string) {
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(contents), 0600)).To(Succeed())
+	}
+
+	It("runs the hooks with the environment of the service scripts", func() {
+		Expect(os.MkdirAll(filepath.Join(tmpDir, "hooks", "post-director"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "hooks", "post-director", "print-env"), []byte("#!/bin/sh\necho $CFDEV_HOOK $BOSH_ENVIRONMENT $CF_DOMAIN\n"), 0755)).To(Succeed())
+
+		Expect(c.RunHooks(ui, workspace.HookPostDirector)).To(Succeed())
+
+		Expect(ui.messages).To(Equal([]string{"Running post-director hook print-env..."}))
+		output, err := ioutil.ReadFile(filepath.Join(tmpDir, "log", "hook-post-director-print-env.log"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(output)).To(Equal("post-director 10.144.0.2 dev.cfdev.sh\n"))
+	})
+
+	It("warns about failing hooks and runs the next ones", func() {
+		writeHooks(`---
+hooks:
+- point: pre-stop
+  name: broken
+  command: exit 3
+- point: pre-stop
+  name: marker
+  command: touch ` + filepath.Join(tmpDir, "marker") + `
+`)
+
+		Expect(c.RunHooks(ui, workspace.HookPreStop)).To(Succeed())
+
+		Expect(ui.messages).To(ContainElement(fmt.Sprintf("WARNING: the pre-stop hook broken failed: exit status 3. See %s", filepath.Join(tmpDir, "log", "hook-pre-stop-broken.log"))))
+		Expect(filepath.Join(tmpDir, "marker")).To(BeAnExistingFile())
+	})
+
+	It("stops at a failing fatal hook", func() {
+		writeHooks(`---
+hooks:
+- point: post-service:cf
+  name: seed
+  command: exit 1
+  fatal: true
+- point: post-service:cf
+  name: marker
+  command: touch ` + filepath.Join(tmpDir, "marker") + `
+`)
+
+		err := c.RunHooks(ui, workspace.ServiceHookPoint("cf"))
+		Expect(err).To(MatchError(ContainSubstring("the post-service:cf hook seed failed: exit status 1")))
+		Expect(filepath.Join(tmpDir, "marker")).NotTo(BeAnExistingFile())
+	})
+
+	It("stops hooks that run past their timeout", func() {
+		writeHooks(`---
+hooks:
+- point: post-vm
+  name: slow
+  command: sleep 10
+  timeout: 100ms
+  fatal: true
+`)
+
+		err := c.RunHooks(ui, workspace.HookPostVM)
+		Expect(err).To(MatchError(ContainSubstring("the post-vm hook slow failed: timed out after 100ms")))
+	})
+})
@@ -0,0 +1,47 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/provision (interfaces: CommandRunner)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockCommandRunner is a mock of CommandRunner interface
+type MockCommandRunner struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommandRunnerMockRecorder
+}
+
+// MockCommandRunnerMockRecorder is the mock recorder for MockCommandRunner
+type MockCommandRunnerMockRecorder struct {
+	mock *MockCommandRunner
+}
+
+// NewMockCommandRunner creates a new mock instance
+func NewMockCommandRunner(ctrl *gomock.Controller) *MockCommandRunner {
+	mock := &MockCommandRunner{ctrl: ctrl}
+	mock.recorder = &MockCommandRunnerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCommandRunner) EXPECT() *MockCommandRunnerMockRecorder {
+	return m.recorder
+}
+
+// Output mocks base method
+func (m *MockCommandRunner) Output(arg0 context.Context, arg1 string) ([]byte, error) {
+	ret := m.ctrl.Call(m, "Output", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Output indicates an expected call of Output
+func (mr *MockCommandRunnerMockRecorder) Output(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Output", reflect.TypeOf((*MockCommandRunner)(nil).Output), arg0, arg1)
+}
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/provision (interfaces: Director)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	bosh "code.cloudfoundry.org/cfdev/bosh"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockDirector is a mock of Director interface
+type MockDirector struct {
+	ctrl     *gomock.Controller
+	recorder *MockDirectorMockRecorder
+}
+
+// MockDirectorMockRecorder is the mock recorder for MockDirector
+type MockDirectorMockRecorder struct {
+	mock *MockDirector
+}
+
+// NewMockDirector creates a new mock instance
+func NewMockDirector(ctrl *gomock.Controller) *MockDirector {
+	mock := &MockDirector{ctrl: ctrl}
+	mock.recorder = &MockDirectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDirector) EXPECT() *MockDirectorMockRecorder {
+	return m.recorder
+}
+
+// CancelTask mocks base method
+func (m *MockDirector) CancelTask(arg0 int) error {
+	ret := m.ctrl.Call(m, "CancelTask", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelTask indicates an expected call of CancelTask
+func (mr *MockDirectorMockRecorder) CancelTask(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTask", reflect.TypeOf((*MockDirector)(nil).CancelTask), arg0)
+}
+
+// TaskEvents mocks base method
+func (m *MockDirector) TaskEvents(arg0 int) ([]bosh.Event, error) {
+	ret := m.ctrl.Call(m, "TaskEvents", arg0)
+	ret0, _ := ret[0].([]bosh.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskEvents indicates an expected call of TaskEvents
+func (mr *MockDirectorMockRecorder) TaskEvents(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskEvents", reflect.TypeOf((*MockDirector)(nil).TaskEvents), arg0)
+}
+
+// TaskOutput mocks base method
+func (m *MockDirector) TaskOutput(arg0 int, arg1 string) ([]byte, error) {
+	ret := m.ctrl.Call(m, "TaskOutput", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskOutput indicates an expected call of TaskOutput
+func (mr *MockDirectorMockRecorder) TaskOutput(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskOutput", reflect.TypeOf((*MockDirector)(nil).TaskOutput), arg0, arg1)
+}
+
+// Tasks mocks base method
+func (m *MockDirector) Tasks(arg0 string) ([]bosh.Task, error) {
+	ret := m.ctrl.Call(m, "Tasks", arg0)
+	ret0, _ := ret[0].([]bosh.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tasks indicates an expected call of Tasks
+func (mr *MockDirectorMockRecorder) Tasks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tasks", reflect.TypeOf((*MockDirector)(nil).Tasks), arg0)
+}
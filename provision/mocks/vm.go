@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: code.cloudfoundry.org/cfdev/provision (interfaces: VM)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	net "net"
+	reflect "reflect"
+)
+
+// MockVM is a mock of VM interface
+type MockVM struct {
+	ctrl     *gomock.Controller
+	recorder *MockVMMockRecorder
+}
+
+// MockVMMockRecorder is the mock recorder for MockVM
+type MockVMMockRecorder struct {
+	mock *MockVM
+}
+
+// NewMockVM creates a new mock instance
+func NewMockVM(ctrl *gomock.Controller) *MockVM {
+	mock := &MockVM{ctrl: ctrl}
+	mock.recorder = &MockVMMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockVM) EXPECT() *MockVMMockRecorder {
+	return m.recorder
+}
+
+// Address mocks base method
+func (m *MockVM) Address(arg0 context.Context) (net.IP, error) {
+	ret := m.ctrl.Call(m, "Address", arg0)
+	ret0, _ := ret[0].(net.IP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Address indicates an expected call of Address
+func (mr *MockVMMockRecorder) Address(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Address", reflect.TypeOf((*MockVM)(nil).Address), arg0)
+}
@@ -0,0 +1,68 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/provision/mocks"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ping", func() {
+	var (
+		mockController *gomock.Controller
+		mockVM         *mocks.MockVM
+		tmpDir         string
+		c              *provision.Controller
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockVM = mocks.NewMockVM(mockController)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-ping-")
+		Expect(err).NotTo(HaveOccurred())
+
+		c = provision.NewController(config.Config{
+			LogDir:   tmpDir,
+			StateDir: tmpDir,
+		}, mockVM)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("asks the driver for the address of the VM and reports why it is unreachable", func() {
+		mockVM.EXPECT().Address(gomock.Any()).Return(nil, errors.New("no lease yet")).MinTimes(1)
+
+		err := c.Ping(1500 * time.Millisecond)
+		Expect(err).To(MatchError("no lease yet"))
+	})
+
+	It("includes the last lines of the VM console in the error", func() {
+		var lines []string
+		for i := 1; i <= 30; i++ {
+			lines = append(lines, "boot line "+strings.Repeat("x", i%3))
+		}
+		lines[29] = "kernel panic"
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "console.log"), []byte(strings.Join(lines, "\r\n")+"\r\n"), 0644)).To(Succeed())
+		mockVM.EXPECT().Address(gomock.Any()).Return(nil, errors.New("no lease yet")).MinTimes(1)
+
+		err := c.Ping(1500 * time.Millisecond)
+		Expect(err).To(MatchError(ContainSubstring("no lease yet\n\nLast lines of the VM console (" + filepath.Join(tmpDir, "console.log") + "):\n")))
+		Expect(err.Error()).To(HaveSuffix("kernel panic"))
+		Expect(strings.Count(err.Error(), "boot line")).To(Equal(19))
+		Expect(err.Error()).NotTo(ContainSubstring("\r"))
+	})
+})
@@ -0,0 +1,18 @@
+// +build !windows
+
+package provision
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// startProcessGroup makes the command the leader of a new process group,
+// so that the processes it spawns can be killed along with it.
+func startProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,13 @@
+package provision
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+func startProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the command together with the processes it spawned.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
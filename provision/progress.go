@@ -15,7 +15,8 @@ func (c *Controller) report(start time.Time, ui UI, b *Bosh, service workspace.S
 		select {
 		case err := <-errChan:
 			if err != nil {
-				return errors.SafeWrap(err, fmt.Sprintf("Failed to deploy %s", service.Name))
+				ui.Writer().Write([]byte("\n"))
+				return errors.SafeWrap(c.deploymentError(b, service, err), fmt.Sprintf("Failed to deploy %s", service.Name))
 			}
 
 			ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Done (%s)\n", time.Now().Sub(start).Round(time.Second))))
@@ -27,7 +28,15 @@ func (c *Controller) report(start time.Time, ui UI, b *Bosh, service workspace.S
 			case Preparing:
 				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Preparing deployment (%s)", p.Duration.Round(time.Second))))
 			case Deploying:
-				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Progress: %d of %d (%s)", p.Done, p.Total, p.Duration.Round(time.Second))))
+				if p.Stage != "" {
+					ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  %s: %d of %d (%s)", p.Stage, p.Done, p.Total, p.Duration.Round(time.Second))))
+				} else {
+					ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Progress: %d of %d (%s)", p.Done, p.Total, p.Duration.Round(time.Second))))
+				}
+			case Failed:
+				ui.Writer().Write([]byte("\n"))
+				err := c.deploymentError(b, service, fmt.Errorf("BOSH task %d failed: %s", p.TaskID, p.Error))
+				return errors.SafeWrap(err, fmt.Sprintf("Failed to deploy %s", service.Name))
 			case RunningErrand:
 				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Running errand (%s)", p.Duration.Round(time.Second))))
 			}
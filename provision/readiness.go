@@ -0,0 +1,161 @@
+package provision
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//go:generate mockgen -package mocks -destination mocks/command_runner.go code.cloudfoundry.org/cfdev/provision CommandRunner
+type CommandRunner interface {
+	Output(ctx context.Context, command string) ([]byte, error)
+}
+
+type ReadinessCheck struct {
+	Name    string
+	Command string
+}
+
+// ReadinessProbe waits until the network of the VM is usable,
+// as 'bosh create-env' fails obscurely when it is not.
+type ReadinessProbe struct {
+	Checks   []ReadinessCheck
+	Retries  int
+	Interval time.Duration
+}
+
+// NewReadinessProbe checks the default route and the resolver of the VM,
+// and the name resolution and reachability of the proxy when one is
+// configured, or of the probe host otherwise, if there is one. The checks
+// only rely on the tools they find in the VM.
+func NewReadinessProbe(proxy config.ProxyConfig, probeHost string) *ReadinessProbe {
+	target := probeHost
+	if proxyHost := proxyAddress(proxy); proxyHost != "" {
+		target = proxyHost
+	}
+
+	probe := &ReadinessProbe{
+		Checks: []ReadinessCheck{
+			{Name: "default route", Command: `awk '$2 == "00000000" { found = 1 } END { exit !found }' /proc/net/route`},
+			{Name: "resolver", Command: "grep -q '^nameserver' /etc/resolv.conf"},
+		},
+		Retries:  15,
+		Interval: 2 * time.Second,
+	}
+
+	if target == "" {
+		return probe
+	}
+
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		host, port = target, "443"
+	}
+
+	if net.ParseIP(host) == nil {
+		probe.Checks = append(probe.Checks, ReadinessCheck{
+			Name: "DNS resolution of " + host,
+			Command: firstInstalled(
+				"getent hosts "+host,
+				"nslookup "+host,
+				"host "+host,
+			),
+		})
+	}
+
+	probe.Checks = append(probe.Checks, ReadinessCheck{
+		Name: "connection to " + net.JoinHostPort(host, port),
+		Command: firstInstalled(
+			fmt.Sprintf("nc -z -w 5 %s %s", host, port),
+			fmt.Sprintf("timeout 5 bash -c '</dev/tcp/%s/%s'", host, port),
+		),
+	})
+
+	return probe
+}
+
+// firstInstalled runs the first of the commands whose tool is installed
+// in the VM. When none is, the check is skipped rather than failed, as
+// the next checks and 'bosh create-env' report an unusable network too.
+func firstInstalled(commands ...string) string {
+	var (
+		script string
+		tools  []string
+	)
+
+	for i, command := range commands {
+		tool := strings.Fields(command)[0]
+		tools = append(tools, tool)
+
+		keyword := "elif"
+		if i == 0 {
+			keyword = "if"
+		}
+
+		script += fmt.Sprintf("%s command -v %s >/dev/null 2>&1; then %s; ", keyword, tool, command)
+	}
+
+	return script + fmt.Sprintf("else echo 'none of %s is installed, skipping' >&2; fi", strings.Join(tools, ", "))
+}
+
+// Wait runs the checks in order, retrying each one a bounded number of times.
+func (p *ReadinessProbe) Wait(ctx context.Context, runner CommandRunner) error {
+	for _, check := range p.Checks {
+		var (
+			output []byte
+			err    error
+		)
+
+		for attempt := 1; attempt <= p.Retries; attempt++ {
+			output, err = runner.Output(ctx, check.Command)
+			if err == nil {
+				break
+			}
+
+			if attempt == p.Retries {
+				return fmt.Errorf("the VM network is not ready: %s failed after %d attempts: %s", check.Name, p.Retries, describe(output, err))
+			}
+
+			select {
+			case <-time.After(p.Interval):
+			case <-ctx.Done():
+				return fmt.Errorf("the VM network is not ready: %s failed: %s", check.Name, describe(output, err))
+			}
+		}
+	}
+
+	return nil
+}
+
+func proxyAddress(proxy config.ProxyConfig) string {
+	for _, p := range []string{proxy.Https, proxy.Http} {
+		if p == "" {
+			continue
+		}
+
+		if !strings.Contains(p, "://") {
+			p = "http://" + p
+		}
+
+		if u, err := url.Parse(p); err == nil && u.Host != "" {
+			if u.Port() == "" {
+				return net.JoinHostPort(u.Hostname(), "80")
+			}
+			return u.Host
+		}
+	}
+
+	return ""
+}
+
+func describe(output []byte, err error) string {
+	if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+		return trimmed
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,103 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/provision/mocks"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadinessProbe", func() {
+	var (
+		mockController *gomock.Controller
+		mockRunner     *mocks.MockCommandRunner
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockRunner = mocks.NewMockCommandRunner(mockController)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	checkNames := func(probe *provision.ReadinessProbe) []string {
+		var names []string
+		for _, check := range probe.Checks {
+			names = append(names, check.Name)
+		}
+		return names
+	}
+
+	It("checks the probe host when there is no proxy", func() {
+		probe := provision.NewReadinessProbe(config.ProxyConfig{}, "bosh.io:443")
+
+		Expect(checkNames(probe)).To(Equal([]string{
+			"default route",
+			"resolver",
+			"DNS resolution of bosh.io",
+			"connection to bosh.io:443",
+		}))
+	})
+
+	It("only checks the default route without a probe host, so that an offline host can start", func() {
+		probe := provision.NewReadinessProbe(config.ProxyConfig{}, "")
+
+		Expect(checkNames(probe)).To(Equal([]string{"default route", "resolver"}))
+	})
+
+	It("checks the proxy when one is configured", func() {
+		probe := provision.NewReadinessProbe(config.ProxyConfig{Https: "http://10.0.0.5:3128"}, "bosh.io:443")
+
+		Expect(checkNames(probe)).To(Equal([]string{
+			"default route",
+			"resolver",
+			"connection to 10.0.0.5:3128",
+		}))
+	})
+
+	It("falls back to the tools installed in the VM", func() {
+		probe := provision.NewReadinessProbe(config.ProxyConfig{}, "bosh.io:443")
+
+		Expect(probe.Checks[2].Command).To(Equal("if command -v getent >/dev/null 2>&1; then getent hosts bosh.io; " +
+			"elif command -v nslookup >/dev/null 2>&1; then nslookup bosh.io; " +
+			"elif command -v host >/dev/null 2>&1; then host bosh.io; " +
+			"else echo 'none of getent, nslookup, host is installed, skipping' >&2; fi"))
+	})
+
+	It("retries the checks until they pass", func() {
+		probe := &provision.ReadinessProbe{
+			Checks:   []provision.ReadinessCheck{{Name: "one", Command: "check-one"}, {Name: "two", Command: "check-two"}},
+			Retries:  3,
+			Interval: time.Millisecond,
+		}
+
+		gomock.InOrder(
+			mockRunner.EXPECT().Output(gomock.Any(), "check-one").Return(nil, errors.New("exit status 1")),
+			mockRunner.EXPECT().Output(gomock.Any(), "check-one").Return(nil, nil),
+			mockRunner.EXPECT().Output(gomock.Any(), "check-two").Return(nil, nil),
+		)
+
+		Expect(probe.Wait(context.Background(), mockRunner)).To(Succeed())
+	})
+
+	It("reports the check that did not pass", func() {
+		probe := &provision.ReadinessProbe{
+			Checks:   []provision.ReadinessCheck{{Name: "DNS resolution of bosh.io", Command: "check-dns"}, {Name: "two", Command: "check-two"}},
+			Retries:  2,
+			Interval: time.Millisecond,
+		}
+
+		mockRunner.EXPECT().Output(gomock.Any(), "check-dns").Return([]byte("nslookup: can't resolve 'bosh.io'\n"), errors.New("exit status 1")).Times(2)
+
+		err := probe.Wait(context.Background(), mockRunner)
+		Expect(err).To(MatchError("the VM network is not ready: DNS resolution of bosh.io failed after 2 attempts: nslookup: can't resolve 'bosh.io'"))
+	})
+})
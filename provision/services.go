@@ -1,11 +1,16 @@
 package provision
 
 import (
+	"code.cloudfoundry.org/bytefmt"
 	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
 	"code.cloudfoundry.org/cfdev/runner"
 	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -58,9 +63,13 @@ func contains(services []workspace.Service, name string) bool {
 	return false
 }
 
-func (c *Controller) DeployServices(ui UI, services []workspace.Service, dockerRegistries []string) error {
+// DeployServices deploys the services one after the other. A service
+// whose deployment is cancelled through the context, or runs past the
+// timeout of its metadata, gets its BOSH task cancelled and is
+// recorded as incomplete.
+func (c *Controller) DeployServices(ctx context.Context, ui UI, services []workspace.Service, dockerRegistries []string) error {
 	var (
-		b       = NewBosh(runner.NewBosh(c.Config))
+		b       = c.newBosh()
 		errChan = make(chan error, 1)
 	)
 
@@ -68,12 +77,39 @@ func (c *Controller) DeployServices(ui UI, services []workspace.Service, dockerR
 		start := time.Now()
 
 		ui.Say("Deploying %s...", service.Name)
+		b.TrackTasks(service.Deployment)
+
+		deployCtx, cancel := deployContext(ctx, service)
 
 		go func(s workspace.Service) {
-			errChan <- c.DeployService(s, dockerRegistries)
+			errChan <- c.DeployService(deployCtx, s, dockerRegistries)
 		}(service)
 
 		err := c.report(start, ui, b, service, errChan)
+		interrupted := err != nil && deployCtx.Err() != nil
+		if interrupted {
+			ui.Say("Cancelling the deployment of %s...", service.Name)
+			err = e.SafeWrap(c.interrupted(deployCtx, b, service), fmt.Sprintf("Failed to deploy %s", service.Name))
+		}
+
+		// Stops the script when the BOSH task failed before it did
+		cancel()
+
+		switch {
+		case interrupted:
+			c.recordServiceState(service, workspace.ServiceIncomplete)
+		case err != nil:
+			c.recordServiceState(service, workspace.ServiceFailed)
+		default:
+			c.recordServiceState(service, workspace.ServiceDeployed)
+			c.Workspace.RecordDuration(service.Name, time.Now().Sub(start))
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = c.runHooks(ui, workspace.ServiceHookPoint(service.Name), workspace.ServiceHookPoint(service.Deployment))
 		if err != nil {
 			return err
 		}
@@ -82,42 +118,353 @@ func (c *Controller) DeployServices(ui UI, services []workspace.Service, dockerR
 	return nil
 }
 
-func (c *Controller) DeployService(service workspace.Service, dockerRegistries []string) error {
+// DeployService runs the deploy script of the service. When the context is
+// done, the script is killed together with the processes it spawned.
+func (c *Controller) DeployService(ctx context.Context, service workspace.Service, dockerRegistries []string) error {
+	cmd := c.scriptCommand(service.Script)
+
+	if strings.HasPrefix(service.Deployment, "cf") {
+		cmd.Env = append(cmd.Env, dockerRegistriesAsEnvVar(dockerRegistries))
+	}
+
+	logFile, err := os.Create(c.deployLogPath(service.Name))
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	startProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		<-done
+		return ctx.Err()
+	}
+}
+
+// UndeployService removes the service with its undeploy script, which also
+// deregisters its service broker, or deletes its deployment when
+// the service does not have one.
+func (c *Controller) UndeployService(service workspace.Service) error {
+	if service.UndeployScript == "" {
+		if err := c.DeleteDeployment(service.Deployment); err != nil {
+			return err
+		}
+
+		c.recordServiceState(service, workspace.ServiceNotDeployed)
+		return nil
+	}
+
+	cmd := c.scriptCommand(service.UndeployScript)
+
+	logFile, err := os.Create(filepath.Join(c.Config.LogDir, "undeploy-"+strings.ToLower(service.Name)+".log"))
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %s. See %s", service.UndeployScript, err, logFile.Name())
+	}
+
+	c.recordServiceState(service, workspace.ServiceNotDeployed)
+	return nil
+}
+
+// scriptCommand runs one of the scripts of the services directory.
+func (c *Controller) scriptCommand(script string) *exec.Cmd {
 	var cmd *exec.Cmd
 
 	if runtime.GOOS == "windows" {
-		cmd = exec.Command("powershell.exe", "-ExecutionPolicy", "Bypass", "-File", filepath.Join(c.Config.ServicesDir, service.Script+".ps1"))
+		cmd = exec.Command("powershell.exe", "-ExecutionPolicy", "Bypass", "-File", filepath.Join(c.Config.ServicesDir, script+".ps1"))
 	} else {
-		cmd = exec.Command(filepath.Join(c.Config.ServicesDir, service.Script))
+		cmd = exec.Command(filepath.Join(c.Config.ServicesDir, script))
 	}
 
-	cmd.Env = os.Environ()
-	cmd.Env = append(cmd.Env, configEnvs(c.Config)...)
-	cmd.Env = append(cmd.Env, c.Workspace.Envs()...)
+	cmd.Env = c.scriptEnvs()
+	return cmd
+}
 
-	if strings.HasPrefix(service.Deployment, "cf") {
-		cmd.Env = append(cmd.Env, dockerRegistriesAsEnvVar(dockerRegistries))
+// interrupted cancels the BOSH tasks of a deployment
+// that was cancelled or that timed out.
+func (c *Controller) interrupted(ctx context.Context, b *Bosh, service workspace.Service) error {
+	err := fmt.Errorf("the deployment was cancelled")
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("the deployment timed out after %s", service.Timeout)
+	}
+
+	if cancelErr := b.CancelTasks(service.Deployment); cancelErr != nil {
+		return fmt.Errorf("%s and its BOSH task could not be cancelled: %s", err, cancelErr)
 	}
 
-	logFile, err := os.Create(filepath.Join(c.Config.LogDir, "deploy-"+strings.ToLower(service.Name)+".log"))
+	return err
+}
+
+// DeployedServices returns the services whose last deployment succeeded
+// and whose instances all still run, so that an environment booted again
+// does not deploy them twice. Errands only need to have succeeded.
+func (c *Controller) DeployedServices(services []workspace.Service) ([]workspace.Service, error) {
+	states, err := c.Workspace.ServiceStates()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		b        = c.newBosh()
+		deployed []workspace.Service
+	)
+
+	for _, service := range services {
+		for _, state := range states {
+			if state.Name == service.Name && state.State == workspace.ServiceDeployed &&
+				(service.IsErrand || b.Healthy(service.Deployment)) {
+				deployed = append(deployed, service)
+				break
+			}
+		}
+	}
+
+	return deployed, nil
+}
+
+func (c *Controller) recordServiceState(service workspace.Service, state string) {
+	c.Workspace.RecordServiceState(workspace.ServiceState{
+		Name:       service.Name,
+		Deployment: service.Deployment,
+		State:      state,
+		Updated:    time.Now(),
+	})
+}
+
+func deployContext(ctx context.Context, service workspace.Service) (context.Context, context.CancelFunc) {
+	if service.Timeout > 0 {
+		return context.WithTimeout(ctx, service.Timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// ManifestDeploymentName resolves the name of the deployment
+// described by the manifest once the ops files are applied.
+func (c *Controller) ManifestDeploymentName(deployment workspace.CustomDeployment) (string, error) {
+	args := append([]string{"int", deployment.Manifest}, interpolateArgs(deployment)...)
+
+	output, err := runner.NewBosh(c.Config).Output(append(args, "--path", "/name")...)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// DeployManifest uploads the releases and deploys an arbitrary manifest
+// to the BOSH Director, reporting progress the same way as the services.
+// When the context is done, the BOSH task of the deployment is cancelled.
+func (c *Controller) DeployManifest(ctx context.Context, ui UI, deployment workspace.CustomDeployment) error {
+	var (
+		boshRunner = runner.NewBosh(c.Config)
+		b          = c.newBosh()
+		errChan    = make(chan error, 1)
+		start      = time.Now()
+		service    = workspace.Service{Name: deployment.Name, Deployment: deployment.Name}
+	)
+
+	logFile, err := os.Create(c.deployLogPath(deployment.Name))
 	if err != nil {
 		return err
 	}
 	defer logFile.Close()
 
-	cmd.Stdout = logFile
-	cmd.Stderr = logFile
+	for _, release := range deployment.Releases {
+		ui.Say("Uploading %s...", filepath.Base(release))
+
+		output, err := boshRunner.CombinedOutputContext(ctx, "-n", "upload-release", release)
+		logFile.Write(output)
+		if ctx.Err() != nil {
+			return fmt.Errorf("the upload of release %s was cancelled", filepath.Base(release))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to upload release %s: %s", filepath.Base(release), err)
+		}
+	}
+
+	ui.Say("Deploying %s...", deployment.Name)
+	b.TrackTasks(deployment.Name)
 
-	return cmd.Run()
+	go func() {
+		args := append([]string{"-n", "-d", deployment.Name, "deploy", deployment.Manifest}, interpolateArgs(deployment)...)
+
+		output, err := boshRunner.CombinedOutputContext(ctx, args...)
+		logFile.Write(output)
+		errChan <- err
+	}()
+
+	err = c.report(start, ui, b, service, errChan)
+	if err != nil && ctx.Err() != nil {
+		ui.Say("Cancelling the deployment of %s...", deployment.Name)
+		return e.SafeWrap(c.interrupted(ctx, b, service), fmt.Sprintf("Failed to deploy %s", deployment.Name))
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.runHooks(ui, workspace.ServiceHookPoint(deployment.Name))
+}
+
+// Deployments returns the names of the deployments known to the BOSH Director.
+func (c *Controller) Deployments() ([]string, error) {
+	output, err := runner.NewBosh(c.Config).Output("--json", "deployments")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDeployments(output)
+}
+
+// DeploymentMemory returns the memory used by the VMs
+// of the deployment, as reported by their vitals.
+func (c *Controller) DeploymentMemory(deployment string) (uint64, error) {
+	output, err := runner.NewBosh(c.Config).Output("--json", "-d", deployment, "vms", "--vitals")
+	if err != nil {
+		return 0, err
+	}
+
+	return parseMemoryUsage(output)
+}
+
+func (c *Controller) DeleteDeployment(deployment string) error {
+	_, err := runner.NewBosh(c.Config).Output("-n", "-d", deployment, "delete-deployment")
+	return err
+}
+
+// RecoverDeployments recreates the missing VMs of every deployment,
+// as their containers do not survive a restart of the CF Dev VM.
+func (c *Controller) RecoverDeployments(ui UI) error {
+	deployments, err := c.Deployments()
+	if err != nil {
+		return err
+	}
+
+	boshRunner := runner.NewBosh(c.Config)
+	for _, deployment := range deployments {
+		ui.Say("Recovering %s...", deployment)
+
+		output, err := boshRunner.Output("-n", "-d", deployment, "cloud-check", "--auto")
+		ioutil.WriteFile(filepath.Join(c.Config.LogDir, "recover-"+deployment+".log"), output, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to recover %s: %s", deployment, err)
+		}
+	}
+
+	return nil
+}
+
+func parseDeployments(output []byte) ([]string, error) {
+	var result struct {
+		Tables []struct {
+			Rows []struct {
+				Name string `json:"name"`
+			} `json:"Rows"`
+		} `json:"Tables"`
+	}
+
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, err
+	}
+
+	var deployments []string
+	for _, table := range result.Tables {
+		for _, row := range table.Rows {
+			deployments = append(deployments, row.Name)
+		}
+	}
+
+	return deployments, nil
+}
+
+// parseMemoryUsage sums the memory usage of the VMs, reported
+// by the BOSH CLI as a percentage followed by a size, e.g. "12% (1.9 GB)".
+// VMs whose vitals are not known yet are left out.
+func parseMemoryUsage(output []byte) (uint64, error) {
+	var result struct {
+		Tables []struct {
+			Rows []struct {
+				MemoryUsage string `json:"memory_usage"`
+			} `json:"Rows"`
+		} `json:"Tables"`
+	}
+
+	if err := json.Unmarshal(output, &result); err != nil {
+		return 0, err
+	}
+
+	var total uint64
+	for _, table := range result.Tables {
+		for _, row := range table.Rows {
+			start, end := strings.Index(row.MemoryUsage, "("), strings.Index(row.MemoryUsage, ")")
+			if start == -1 || end < start {
+				continue
+			}
+
+			size, err := bytefmt.ToBytes(strings.Replace(row.MemoryUsage[start+1:end], " ", "", -1))
+			if err != nil {
+				continue
+			}
+
+			total += size
+		}
+	}
+
+	return total, nil
+}
+
+func interpolateArgs(deployment workspace.CustomDeployment) []string {
+	var args []string
+	for _, opsFile := range deployment.OpsFiles {
+		args = append(args, "-o", opsFile)
+	}
+
+	for _, varsFile := range deployment.VarsFiles {
+		args = append(args, "-l", varsFile)
+	}
+
+	return args
+}
+
+// scriptEnvs is the environment of the service scripts and the hooks.
+func (c *Controller) scriptEnvs() []string {
+	envs := os.Environ()
+	envs = append(envs, configEnvs(c.Config)...)
+	return append(envs, c.Workspace.Envs()...)
 }
 
 func configEnvs(cfg config.Config) []string {
-	return []string{
+	envs := []string{
 		"BINARY_DIR=" + cfg.BinaryDir,
 		"BOSH_STATE=" + cfg.StateBosh,
 		"CF_DOMAIN=" + cfg.CFDomain,
 		"SERVICES_DIR=" + cfg.ServicesDir,
 	}
+
+	// Without root privileges, the cf CLI only reaches CF through the proxy
+	return append(envs, config.ProxyEnvs(cfg.CFProxy())...)
 }
 
 func dockerRegistriesAsEnvVar(registries []string) string {
@@ -15,7 +15,7 @@ var _ = Describe("When progress whitelist is called with", func() {
 	)
 
 	BeforeEach(func() {
-		c = provision.NewController(config.Config{})
+		c = provision.NewController(config.Config{}, nil)
 
 		services = []workspace.Service{
 			{
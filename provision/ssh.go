@@ -1,7 +1,11 @@
 package provision
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"golang.org/x/crypto/ssh"
 	"io"
@@ -9,33 +13,44 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// HostKeyStore keeps the host key of the VM,
+// so that a different host cannot impersonate it.
+type HostKeyStore interface {
+	HostKey() ([]byte, error)
+	SaveHostKey(key []byte) error
+}
+
 type SSH struct {
-	client  *ssh.Client
-	stdout  io.Writer
-	stderr  io.Writer
-	Error   error
+	client *ssh.Client
+	stdout io.Writer
+	stderr io.Writer
+	Error  error
 }
 
 func NewSSH(
+	ctx context.Context,
 	ip string,
 	port string,
 	key []byte,
-	timeout time.Duration,
+	hostKeys HostKeyStore,
 	stdout io.Writer,
 	stderr io.Writer,
 ) (*SSH, error) {
-	client, err := waitForSSH(ip, port, key, timeout)
+	client, err := waitForSSH(ctx, ip, port, key, hostKeys)
 	if err != nil {
 		return nil, err
 	}
 
 	return &SSH{
-		client:  client,
-		stdout:  stdout,
-		stderr:  stderr,
+		client: client,
+		stdout: stdout,
+		stderr: stderr,
 	}, nil
 }
 
@@ -43,25 +58,27 @@ func (s *SSH) Close() {
 	s.client.Close()
 }
 
-func (s *SSH) Run(command string) {
+func (s *SSH) Run(ctx context.Context, command string) {
 	if s.Error != nil {
 		return
 	}
 
-	session, err := s.client.NewSession()
-	if err != nil {
-		s.Error = err
-		return
-	}
-	defer session.Close()
+	s.Error = s.run(ctx, command, nil, s.stdout, s.stderr)
+}
 
-	session.Stdout = s.stdout
-	session.Stderr = s.stderr
+// Output runs the command and returns its combined output.
+// Unlike Run, a failure does not stop the following operations.
+func (s *SSH) Output(ctx context.Context, command string) ([]byte, error) {
+	var (
+		output bytes.Buffer
+		writer = &lockedWriter{w: &output}
+	)
 
-	s.Error = session.Run(command)
+	err := s.run(ctx, command, nil, writer, writer)
+	return output.Bytes(), err
 }
 
-func (s *SSH) SendFile(filePath string, remoteFilePath string) {
+func (s *SSH) SendFile(ctx context.Context, filePath string, remoteFilePath string) {
 	if s.Error != nil {
 		return
 	}
@@ -72,113 +89,307 @@ func (s *SSH) SendFile(filePath string, remoteFilePath string) {
 		return
 	}
 
-	s.SendData(data, remoteFilePath)
+	s.SendData(ctx, data, remoteFilePath)
 }
 
-
-func (s *SSH) SendData(srcData []byte, remoteFilePath string) {
+// SendData copies the data to the VM with the scp protocol, checking
+// every acknowledgement, and verifies the size and checksum of the copy.
+func (s *SSH) SendData(ctx context.Context, srcData []byte, remoteFilePath string) {
 	if s.Error != nil {
 		return
 	}
 
-	bytesReader := bytes.NewReader(srcData)
-	session, err := s.client.NewSession()
+	var (
+		stdinReader, stdinWriter   = io.Pipe()
+		stdoutReader, stdoutWriter = io.Pipe()
+		copyErr                    = make(chan error, 1)
+	)
+
+	go func() {
+		err := scpSend(stdinWriter, bufio.NewReader(stdoutReader), srcData, filepath.Base(remoteFilePath))
+		stdinWriter.Close()
+		copyErr <- err
+
+		// scp acknowledges the end of the transfer as well
+		io.Copy(ioutil.Discard, stdoutReader)
+	}()
+
+	err := s.run(ctx, fmt.Sprintf("/usr/bin/scp -qt %s", filepath.Dir(remoteFilePath)), stdinReader, stdoutWriter, s.stderr)
+	stdoutWriter.Close()
+
+	if sendErr := <-copyErr; sendErr != nil {
+		s.Error = fmt.Errorf("failed to copy %s: %s", remoteFilePath, sendErr)
+		return
+	}
+
 	if err != nil {
-		s.Error = err
+		s.Error = fmt.Errorf("failed to copy %s: %s", remoteFilePath, err)
 		return
 	}
-	defer session.Close()
 
-	session.Stdout = s.stdout
-	session.Stderr = s.stderr
+	s.Error = s.verify(ctx, remoteFilePath, srcData)
+}
 
-	go func() {
-		w, _ := session.StdinPipe()
-		defer w.Close()
+// RetrieveFile copies the remote file with the scp protocol, like SendData,
+// next to its destination first, and only replaces the destination
+// once the size and checksum of the copy are verified.
+func (s *SSH) RetrieveFile(ctx context.Context, filePath string, remoteFilePath string) {
+	if s.Error != nil {
+		return
+	}
 
-		fmt.Fprintln(w, "C0755", int64(len(srcData)), filepath.Base(remoteFilePath))
-		_, err := io.Copy(w, bytesReader)
-		if err != nil {
-			fmt.Print(err)
-		}
+	var (
+		buffer                     bytes.Buffer
+		stdinReader, stdinWriter   = io.Pipe()
+		stdoutReader, stdoutWriter = io.Pipe()
+		copyErr                    = make(chan error, 1)
+	)
 
-		fmt.Fprintln(w, "\x00")
-	}()
+	go func() {
+		err := scpReceive(stdinWriter, bufio.NewReader(stdoutReader), &buffer)
+		stdinWriter.Close()
+		copyErr <- err
 
-	command := fmt.Sprintf("/usr/bin/scp -qt %s", filepath.Dir(remoteFilePath))
+		io.Copy(ioutil.Discard, stdoutReader)
+	}()
 
-	// we are ignoring the error here because it happens even during success
-	session.Run(command)
-}
+	err := s.run(ctx, fmt.Sprintf("/usr/bin/scp -qf %s", remoteFilePath), stdinReader, stdoutWriter, s.stderr)
+	stdoutWriter.Close()
 
-func (s *SSH) RetrieveFile(filePath string, remoteFilePath string) {
-	if s.Error != nil {
+	if receiveErr := <-copyErr; receiveErr != nil {
+		s.Error = fmt.Errorf("failed to retrieve %s: %s", remoteFilePath, receiveErr)
 		return
 	}
 
-	f, err := os.Create(filePath)
 	if err != nil {
+		s.Error = fmt.Errorf("failed to retrieve %s: %s", remoteFilePath, err)
+		return
+	}
+
+	if err := s.verify(ctx, remoteFilePath, buffer.Bytes()); err != nil {
 		s.Error = err
 		return
 	}
-	defer f.Close()
 
-	session, err := s.client.NewSession()
-	if err != nil {
+	tmpPath := filePath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, buffer.Bytes(), 0600); err != nil {
 		s.Error = err
 		return
 	}
+
+	s.Error = os.Rename(tmpPath, filePath)
+}
+
+func (s *SSH) verify(ctx context.Context, remoteFilePath string, data []byte) error {
+	var output bytes.Buffer
+	if err := s.run(ctx, fmt.Sprintf("stat -c %%s %[1]s && sha256sum %[1]s", remoteFilePath), nil, &output, s.stderr); err != nil {
+		return fmt.Errorf("failed to verify %s: %s", remoteFilePath, err)
+	}
+
+	fields := strings.Fields(output.String())
+	if len(fields) < 2 {
+		return fmt.Errorf("failed to verify %s: unexpected output %q", remoteFilePath, output.String())
+	}
+
+	if size, err := strconv.Atoi(fields[0]); err != nil || size != len(data) {
+		return fmt.Errorf("size mismatch for %s: %d bytes locally, %s remotely", remoteFilePath, len(data), fields[0])
+	}
+
+	if sum := fmt.Sprintf("%x", sha256.Sum256(data)); sum != fields[1] {
+		return fmt.Errorf("checksum mismatch for %s", remoteFilePath)
+	}
+
+	return nil
+}
+
+// run executes the command and stops it when the context is done.
+func (s *SSH) run(ctx context.Context, command string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return err
+	}
 	defer session.Close()
 
-	session.Stdout = f
-	session.Stderr = s.stderr
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- session.Run(command)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		session.Signal(ssh.SIGKILL)
+		session.Close()
+		return fmt.Errorf("'%s' was interrupted: %s", strings.Fields(command)[0], ctx.Err())
+	}
+}
+
+func scpSend(w io.Writer, r *bufio.Reader, data []byte, name string) error {
+	if _, err := fmt.Fprintln(w, "C0755", len(data), name); err != nil {
+		return err
+	}
+
+	if err := scpAck(r); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	if _, err := w.Write([]byte{0}); err != nil {
+		return err
+	}
+
+	return scpAck(r)
+}
+
+// scpReceive reads a single file sent by 'scp -f',
+// acknowledging the header, the data and the final status.
+func scpReceive(w io.Writer, r *bufio.Reader, data io.Writer) error {
+	if _, err := w.Write([]byte{0}); err != nil {
+		return err
+	}
+
+	code, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	if code != 'C' {
+		return errors.New("scp: " + strings.TrimSpace(header))
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return fmt.Errorf("unexpected scp header %q", header)
+	}
+
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("unexpected scp header %q", header)
+	}
+
+	if _, err := w.Write([]byte{0}); err != nil {
+		return err
+	}
+
+	if _, err := io.CopyN(data, r, size); err != nil {
+		return err
+	}
+
+	if err := scpAck(r); err != nil {
+		return err
+	}
+
+	_, err = w.Write([]byte{0})
+	return err
+}
+
+func scpAck(r *bufio.Reader) error {
+	code, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	if code == 0 {
+		return nil
+	}
 
-	s.Error = session.Run("cat " + remoteFilePath)
+	message, _ := r.ReadString('\n')
+	return errors.New("scp: " + strings.TrimSpace(message))
 }
 
-func waitForSSH(ip string, port string, privateKey []byte, timeout time.Duration) (*ssh.Client, error) {
+func waitForSSH(ctx context.Context, ip string, port string, privateKey []byte, hostKeys HostKeyStore) (*ssh.Client, error) {
 	signer, err := ssh.ParsePrivateKey(privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse private key: %s", err)
 	}
 
 	var (
-		clientChan = make(chan *ssh.Client, 1)
-		errorChan  = make(chan error, 1)
+		ticker     = time.NewTicker(time.Second)
+		hostKeyErr error
 		config     = &ssh.ClientConfig{
 			User:    "root",
 			Auth:    []ssh.AuthMethod{ssh.PublicKeys(signer)},
 			Timeout: 10 * time.Second,
 			HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-				return nil
+				hostKeyErr = pinHostKey(hostKeys, key)
+				return hostKeyErr
 			},
 		}
 	)
+	defer ticker.Stop()
 
-	go func() {
-		var (
-			ticker  = time.NewTicker(time.Second)
-			timeout = time.After(timeout)
-			err     error
-		)
-
-		for {
-			select {
-			case <-ticker.C:
-				var client *ssh.Client
-				client, err = ssh.Dial("tcp", ip+":"+port, config)
-				if err == nil {
-					clientChan <- client
-					errorChan <- nil
-					return
-				}
-			case <-timeout:
-				clientChan <- nil
-				errorChan <- fmt.Errorf("ssh connection timed out: %s", err)
-				return
+	for {
+		select {
+		case <-ticker.C:
+			var client *ssh.Client
+			client, err = ssh.Dial("tcp", net.JoinHostPort(ip, port), config)
+			if err == nil {
+				return client, nil
+			}
+
+			// Retrying does not help against a different host key
+			if _, ok := hostKeyErr.(*HostKeyError); ok {
+				return nil, hostKeyErr
 			}
+		case <-ctx.Done():
+			return nil, fmt.Errorf("ssh connection timed out: %s", err)
 		}
-	}()
+	}
+}
+
+// lockedWriter lets stdout and stderr share a buffer,
+// as the session copies them concurrently.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+type HostKeyError struct {
+	Fingerprint string
+}
+
+func (e *HostKeyError) Error() string {
+	return fmt.Sprintf("the host key of the CF Dev VM has changed (%s). Please execute 'cf dev stop --destroy' and start again", e.Fingerprint)
+}
+
+// pinHostKey trusts the first host key it sees and rejects any other key afterwards.
+func pinHostKey(hostKeys HostKeyStore, key ssh.PublicKey) error {
+	pinned, err := hostKeys.HostKey()
+	if err != nil {
+		return err
+	}
+
+	if pinned == nil {
+		return hostKeys.SaveHostKey(ssh.MarshalAuthorizedKey(key))
+	}
+
+	pinnedKey, _, _, _, err := ssh.ParseAuthorizedKey(pinned)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(pinnedKey.Marshal(), key.Marshal()) {
+		return &HostKeyError{Fingerprint: ssh.FingerprintSHA256(key)}
+	}
 
-	return <-clientChan, <-errorChan
+	return nil
 }
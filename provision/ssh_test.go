@@ -0,0 +1,220 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/provision"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+)
+
+type memoryHostKeys struct {
+	key []byte
+}
+
+func (m *memoryHostKeys) HostKey() ([]byte, error) { return m.key, nil }
+func (m *memoryHostKeys) SaveHostKey(key []byte) error {
+	m.key = key
+	return nil
+}
+
+var _ = Describe("SSH", func() {
+	var (
+		remoteDir string
+		localDir  string
+		listener  net.Listener
+		port      string
+		clientKey []byte
+		hostKeys  *memoryHostKeys
+	)
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("the fake VM runs commands with sh")
+		}
+
+		var err error
+		remoteDir, err = ioutil.TempDir("", "cfdev-ssh-remote-")
+		Expect(err).NotTo(HaveOccurred())
+		localDir, err = ioutil.TempDir("", "cfdev-ssh-local-")
+		Expect(err).NotTo(HaveOccurred())
+
+		listener, port = startFakeVM(remoteDir, generateKey())
+		clientKey = generateKey()
+		hostKeys = &memoryHostKeys{}
+	})
+
+	AfterEach(func() {
+		listener.Close()
+		os.RemoveAll(remoteDir)
+		os.RemoveAll(localDir)
+	})
+
+	connect := func() (*provision.SSH, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		return provision.NewSSH(ctx, "127.0.0.1", port, clientKey, hostKeys, GinkgoWriter, GinkgoWriter)
+	}
+
+	It("pins the host key on first connect and rejects a different one", func() {
+		s, err := connect()
+		Expect(err).NotTo(HaveOccurred())
+		s.Close()
+		Expect(hostKeys.key).NotTo(BeEmpty())
+
+		listener.Close()
+		listener, port = startFakeVM(remoteDir, generateKey())
+
+		_, err = connect()
+		Expect(err).To(BeAssignableToTypeOf(&provision.HostKeyError{}))
+	})
+
+	It("sends and retrieves files", func() {
+		s, err := connect()
+		Expect(err).NotTo(HaveOccurred())
+		defer s.Close()
+
+		ctx := context.Background()
+		s.SendData(ctx, []byte("some-state"), "state.json")
+		Expect(s.Error).NotTo(HaveOccurred())
+		Expect(ioutil.ReadFile(filepath.Join(remoteDir, "state.json"))).To(Equal([]byte("some-state")))
+
+		Expect(ioutil.WriteFile(filepath.Join(remoteDir, "state.json"), []byte("new-state"), 0600)).To(Succeed())
+		s.RetrieveFile(ctx, filepath.Join(localDir, "state.json"), "state.json")
+		Expect(s.Error).NotTo(HaveOccurred())
+		Expect(ioutil.ReadFile(filepath.Join(localDir, "state.json"))).To(Equal([]byte("new-state")))
+	})
+
+	It("does not accept a copy that cannot be verified", func() {
+		s, err := connect()
+		Expect(err).NotTo(HaveOccurred())
+		defer s.Close()
+
+		s.SendData(context.Background(), []byte("some-state"), "missing-dir/state.json")
+		Expect(s.Error).To(MatchError(ContainSubstring("missing-dir/state.json")))
+	})
+
+	It("does not replace the destination when the remote file cannot be retrieved", func() {
+		s, err := connect()
+		Expect(err).NotTo(HaveOccurred())
+		defer s.Close()
+
+		Expect(ioutil.WriteFile(filepath.Join(localDir, "state.json"), []byte("old-state"), 0600)).To(Succeed())
+		s.RetrieveFile(context.Background(), filepath.Join(localDir, "state.json"), "missing.json")
+		Expect(s.Error).To(MatchError(ContainSubstring("failed to retrieve missing.json")))
+		Expect(ioutil.ReadFile(filepath.Join(localDir, "state.json"))).To(Equal([]byte("old-state")))
+	})
+
+	It("interrupts commands when the context is done", func() {
+		s, err := connect()
+		Expect(err).NotTo(HaveOccurred())
+		defer s.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		s.Run(ctx, "sleep 10")
+		Expect(s.Error).To(MatchError(ContainSubstring("'sleep' was interrupted")))
+	})
+})
+
+func generateKey() []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// startFakeVM serves SSH sessions that run their commands with sh in dir.
+func startFakeVM(dir string, hostKey []byte) (net.Listener, string) {
+	signer, err := ssh.ParsePrivateKey(hostKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(ssh.ConnMetadata, ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSSH(conn, config, dir)
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return listener, port
+}
+
+func serveSSH(conn net.Conn, config *ssh.ServerConfig, dir string) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			defer channel.Close()
+
+			for req := range channelRequests {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				req.Reply(true, nil)
+
+				command := string(req.Payload[4:])
+				cmd := exec.Command("sh", "-c", command)
+				cmd.Dir = dir
+				cmd.Stdout = channel
+				cmd.Stderr = channel.Stderr()
+
+				stdin, _ := cmd.StdinPipe()
+				go func() {
+					io.Copy(stdin, channel)
+					stdin.Close()
+				}()
+
+				var status uint32
+				if err := cmd.Run(); err != nil {
+					status = 1
+				}
+
+				payload := make([]byte, 4)
+				binary.BigEndian.PutUint32(payload, status)
+				channel.SendRequest("exit-status", false, payload)
+				return
+			}
+		}()
+	}
+}
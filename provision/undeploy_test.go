@@ -0,0 +1,65 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UndeployService", func() {
+	var (
+		tmpDir string
+		c      *provision.Controller
+	)
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("the service scripts are powershell scripts on windows")
+		}
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-undeploy-")
+		Expect(err).NotTo(HaveOccurred())
+
+		c = provision.NewController(config.Config{
+			LogDir:      tmpDir,
+			StateDir:    tmpDir,
+			ServicesDir: tmpDir,
+			StateBosh:   filepath.Join(tmpDir, "bosh"),
+			CFDomain:    "dev.cfdev.sh",
+		}, nil)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("runs the undeploy script and records the service as not deployed", func() {
+		script := "#!/bin/sh\necho removing broker from $CF_DOMAIN\n"
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "undeploy-mysql"), []byte(script), 0755)).To(Succeed())
+
+		err := c.UndeployService(workspace.Service{Name: "Mysql", Deployment: "cf-mysql", UndeployScript: "undeploy-mysql"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ioutil.ReadFile(filepath.Join(tmpDir, "undeploy-mysql.log"))).To(Equal([]byte("removing broker from dev.cfdev.sh\n")))
+
+		states, err := c.Workspace.ServiceStates()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states).To(HaveLen(1))
+		Expect(states[0].State).To(Equal(workspace.ServiceNotDeployed))
+	})
+
+	It("reports a failing undeploy script", func() {
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "undeploy-mysql"), []byte("#!/bin/sh\nexit 2\n"), 0755)).To(Succeed())
+
+		err := c.UndeployService(workspace.Service{Name: "Mysql", Deployment: "cf-mysql", UndeployScript: "undeploy-mysql"})
+		Expect(err).To(MatchError(ContainSubstring("undeploy-mysql failed: exit status 2")))
+	})
+})
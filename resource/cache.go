@@ -42,6 +42,24 @@ func (c *Cache) Sync(clog Catalog) error {
 	return nil
 }
 
+// Pending returns the items of the catalog that Sync still has to download.
+func (c *Cache) Pending(clog Catalog) ([]Item, error) {
+	var pending []Item
+	for _, item := range clog.Items {
+		if !item.InUse {
+			continue
+		}
+
+		if match, err := c.checksumMatches(filepath.Join(c.Dir, item.Name), item.MD5); err != nil {
+			return nil, err
+		} else if !match {
+			pending = append(pending, item)
+		}
+	}
+
+	return pending, nil
+}
+
 func (c *Cache) total(clog Catalog) uint64 {
 	var total uint64 = 0
 	for _, item := range clog.Items {
@@ -126,7 +144,7 @@ func (c *Cache) downloadHTTP(url, tmpPath string) error {
 	} else if resp.StatusCode == 416 {
 		// Possibly full file already downloaded
 	} else {
-		return errors.SafeWrap(fmt.Errorf(resp.Status), "http status")
+		return errors.SafeWrap(fmt.Errorf("%s", resp.Status), "http status")
 	}
 	return nil
 }
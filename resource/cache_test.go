@@ -333,6 +333,19 @@ var _ = Describe("Cache Sync", func() {
 		})
 	})
 
+	It("reports the items that still have to be downloaded without downloading them", func() {
+		pending, err := cache.Pending(catalog)
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		for _, item := range pending {
+			names = append(names, item.Name)
+		}
+
+		Expect(names).To(Equal([]string{"first-resource", "second-resource", "fourth-resource"}))
+		Expect(downloads).To(BeEmpty())
+	})
+
 	Context("when asset InUse", func() {
 		It("true", func() {
 			Expect(cache.Sync(catalog)).To(Succeed())
@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"context"
+
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/workspace"
 	"os"
@@ -22,13 +24,23 @@ func NewBosh(config config.Config) *Bosh {
 }
 
 func (b *Bosh) Output(args ...string) ([]byte, error) {
+	return b.command(context.Background(), args...).Output()
+}
+
+// CombinedOutputContext runs the BOSH CLI, returning both its output streams
+// for the logs, and kills it when the context is done.
+func (b *Bosh) CombinedOutputContext(ctx context.Context, args ...string) ([]byte, error) {
+	return b.command(ctx, args...).CombinedOutput()
+}
+
+func (b *Bosh) command(ctx context.Context, args ...string) *exec.Cmd {
 	executable := filepath.Join(b.config.BinaryDir, "bosh")
 
 	if runtime.GOOS == "windows" {
 		executable += ".exe"
 	}
 
-	command := exec.Command(executable, args...)
+	command := exec.CommandContext(ctx, executable, args...)
 	command.Env = append(os.Environ(), b.workspace.Envs()...)
-	return command.Output()
+	return command
 }
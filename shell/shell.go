@@ -0,0 +1,191 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	Bash       = "bash"
+	Zsh        = "zsh"
+	Fish       = "fish"
+	Powershell = "powershell"
+	Cmd        = "cmd"
+	Dotenv     = "dotenv"
+	JSON       = "json"
+)
+
+var Supported = []string{Bash, Zsh, Fish, Powershell, Cmd, Dotenv, JSON}
+
+// EnvArgs are the options of the env commands printing an environment.
+type EnvArgs struct {
+	Shell string
+	Envrc string
+}
+
+// AddFlags declares the options of an env command on it.
+func (a *EnvArgs) AddFlags(cmd *cobra.Command) {
+	pf := cmd.PersistentFlags()
+	pf.StringVar(&a.Shell, "shell", "", "output format: "+strings.Join(Supported, "|")+" (detected from $SHELL by default)")
+	pf.StringVar(&a.Envrc, "envrc", "", "write the environment to a direnv compatible file instead")
+}
+
+// Detect determines the shell from the SHELL environment variable.
+// Windows does not set SHELL for its native shells, so powershell is
+// assumed there to stay compatible with 'Invoke-Expression' usage.
+func Detect() string {
+	name := strings.ToLower(filepath.Base(os.Getenv("SHELL")))
+
+	switch {
+	case strings.Contains(name, "fish"):
+		return Fish
+	case strings.Contains(name, "zsh"):
+		return Zsh
+	case strings.Contains(name, "bash"), strings.HasSuffix(name, "sh"):
+		return Bash
+	case runtime.GOOS == "windows":
+		return Powershell
+	default:
+		return Bash
+	}
+}
+
+func Validate(shell string) error {
+	for _, s := range Supported {
+		if s == shell {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported shell '%s', please use one of: %s", shell, strings.Join(Supported, ", "))
+}
+
+// Usage returns the instructions for loading the output of the
+// given command into the current session of the shell.
+func Usage(shell string, command string) string {
+	switch shell {
+	case Fish:
+		return fmt.Sprintf("Usage: %s | source", command)
+	case Powershell:
+		return fmt.Sprintf("Usage: %s | Invoke-Expression", command)
+	case Cmd:
+		return fmt.Sprintf(`Usage: FOR /F "tokens=*" %%i IN ('%s --shell cmd') DO %%i`, command)
+	default:
+		return fmt.Sprintf(`Usage: eval "$(%s)"`, command)
+	}
+}
+
+// Format renders the environment variables in the syntax of the given shell.
+// The unset variables are ignored by formats that cannot express them, as
+// are the multi-line values by cmd.
+func Format(shell string, envs map[string]string, unset []string) (string, error) {
+	if err := Validate(shell); err != nil {
+		return "", err
+	}
+
+	if shell == JSON {
+		data, err := json.MarshalIndent(envs, "", "  ")
+		return string(data), err
+	}
+
+	var (
+		output []string
+		keys   = sortedKeys(envs)
+	)
+
+	sort.Strings(unset)
+
+	for _, key := range unset {
+		switch shell {
+		case Bash, Zsh:
+			output = append(output, fmt.Sprintf("unset %s;", key))
+		case Fish:
+			output = append(output, fmt.Sprintf("set -e %s;", key))
+		case Powershell:
+			output = append(output, fmt.Sprintf("Remove-Item Env:%s;", key))
+		case Cmd:
+			output = append(output, fmt.Sprintf("SET %s=", key))
+		}
+	}
+
+	for _, key := range keys {
+		value := envs[key]
+
+		switch shell {
+		case Bash, Zsh:
+			output = append(output, fmt.Sprintf("export %s=%s;", key, quotePosix(value)))
+		case Fish:
+			output = append(output, fmt.Sprintf("set -gx %s %s;", key, quoteFish(value)))
+		case Powershell:
+			output = append(output, fmt.Sprintf("$env:%s=%s;", key, quotePowershell(value)))
+		case Cmd:
+			if strings.ContainsAny(value, "\r\n") {
+				output = append(output, fmt.Sprintf("REM %s is left out, cmd does not support multi-line values", key))
+				continue
+			}
+			output = append(output, fmt.Sprintf(`SET "%s=%s"`, key, value))
+		case Dotenv:
+			output = append(output, fmt.Sprintf("%s=%s", key, quoteDotenv(value)))
+		}
+	}
+
+	return strings.Join(output, "\n"), nil
+}
+
+// WriteEnvrc writes the environment variables as a direnv compatible '.envrc' file.
+func WriteEnvrc(path string, envs map[string]string) error {
+	var lines []string
+	for _, key := range sortedKeys(envs) {
+		lines = append(lines, fmt.Sprintf("export %s=%s", key, quotePosix(envs[key])))
+	}
+
+	return ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+}
+
+// quotePosix single quotes the value, in which POSIX shells expand
+// nothing. A single quote ends the quoting, is escaped, and resumes it.
+func quotePosix(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// quoteFish single quotes the value, in which fish only
+// interprets escaped backslashes and single quotes.
+func quoteFish(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
+
+// quotePowershell single quotes the value, in which PowerShell expands
+// nothing but doubled single quotes. Every line is quoted on its own and
+// joined with a newline, as 'Invoke-Expression' runs the output line by line.
+func quotePowershell(value string) string {
+	lines := strings.Split(strings.Replace(value, "\r\n", "\n", -1), "\n")
+	for i, line := range lines {
+		lines[i] = "'" + strings.Replace(line, "'", "''", -1) + "'"
+	}
+
+	return strings.Join(lines, " + \"`n\" + ")
+}
+
+// quoteDotenv double quotes the value, escaping the
+// characters that dotenv parsers unescape in them.
+func quoteDotenv(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`).Replace(value) + `"`
+}
+
+func sortedKeys(envs map[string]string) []string {
+	var keys []string
+	for k := range envs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+package shell_test
+
+import (
+	"code.cloudfoundry.org/cfdev/shell"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Shell", func() {
+	var envs = map[string]string{
+		"SOME_KEY":       "some-value",
+		"SOME_OTHER_KEY": "some-other-value",
+	}
+
+	Describe("Format", func() {
+		DescribeTable("renders each supported shell",
+			func(sh string, expected string) {
+				output, err := shell.Format(sh, envs, []string{"STALE_KEY"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(output).To(Equal(expected))
+			},
+			Entry("bash", shell.Bash, "unset STALE_KEY;\nexport SOME_KEY='some-value';\nexport SOME_OTHER_KEY='some-other-value';"),
+			Entry("zsh", shell.Zsh, "unset STALE_KEY;\nexport SOME_KEY='some-value';\nexport SOME_OTHER_KEY='some-other-value';"),
+			Entry("fish", shell.Fish, "set -e STALE_KEY;\nset -gx SOME_KEY 'some-value';\nset -gx SOME_OTHER_KEY 'some-other-value';"),
+			Entry("powershell", shell.Powershell, "Remove-Item Env:STALE_KEY;\n$env:SOME_KEY='some-value';\n$env:SOME_OTHER_KEY='some-other-value';"),
+			Entry("cmd", shell.Cmd, "SET STALE_KEY=\nSET \"SOME_KEY=some-value\"\nSET \"SOME_OTHER_KEY=some-other-value\""),
+			Entry("dotenv", shell.Dotenv, "SOME_KEY=\"some-value\"\nSOME_OTHER_KEY=\"some-other-value\""),
+			Entry("json", shell.JSON, "{\n  \"SOME_KEY\": \"some-value\",\n  \"SOME_OTHER_KEY\": \"some-other-value\"\n}"),
+		)
+
+		DescribeTable("quotes the values so that each shell keeps them as is",
+			func(sh string, expected string) {
+				output, err := shell.Format(sh, map[string]string{"KEY": `it's $HOME \u00e9 \x41`}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(output).To(Equal(expected))
+			},
+			Entry("bash", shell.Bash, `export KEY='it'\''s $HOME \u00e9 \x41';`),
+			Entry("fish", shell.Fish, `set -gx KEY 'it\'s $HOME \\u00e9 \\x41';`),
+			Entry("powershell", shell.Powershell, `$env:KEY='it''s $HOME \u00e9 \x41';`),
+			Entry("dotenv", shell.Dotenv, `KEY="it's $HOME \\u00e9 \\x41"`),
+		)
+
+		DescribeTable("keeps multi-line values on a single line of output",
+			func(sh string, expected string) {
+				output, err := shell.Format(sh, map[string]string{"CERT": "line one\nline two"}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(output).To(Equal(expected))
+			},
+			Entry("powershell", shell.Powershell, "$env:CERT='line one' + \"`n\" + 'line two';"),
+			Entry("cmd", shell.Cmd, "REM CERT is left out, cmd does not support multi-line values"),
+			Entry("dotenv", shell.Dotenv, `CERT="line one\nline two"`),
+		)
+
+		It("returns an error for an unsupported shell", func() {
+			_, err := shell.Format("tcsh", envs, nil)
+			Expect(err).To(MatchError(ContainSubstring("unsupported shell 'tcsh'")))
+		})
+	})
+
+	Describe("Detect", func() {
+		var originalShell string
+
+		BeforeEach(func() {
+			originalShell = os.Getenv("SHELL")
+		})
+
+		AfterEach(func() {
+			os.Setenv("SHELL", originalShell)
+		})
+
+		DescribeTable("uses the SHELL environment variable",
+			func(value string, expected string) {
+				os.Setenv("SHELL", value)
+				Expect(shell.Detect()).To(Equal(expected))
+			},
+			Entry("bash", "/bin/bash", shell.Bash),
+			Entry("zsh", "/usr/local/bin/zsh", shell.Zsh),
+			Entry("fish", "/usr/bin/fish", shell.Fish),
+			Entry("sh", "/bin/sh", shell.Bash),
+		)
+	})
+})
@@ -0,0 +1,114 @@
+package smoketest
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	smokeTestOrg   = "cfdev-smoke-test"
+	smokeTestSpace = "cfdev-smoke-test"
+	appMarker      = "cfdev smoke test"
+)
+
+// A tiny static app, so that only the staticfile buildpack is needed
+var appFiles = map[string]string{
+	"index.html": "<html><body>" + appMarker + "</body></html>\n",
+	"Staticfile": "",
+}
+
+// AppCheck pushes, curls and deletes a tiny app with the cf CLI. The CLI runs
+// with its own CF_HOME so that the target of the user is left untouched.
+func AppCheck(cfg Config, cfBinary string) Check {
+	return Check{
+		Name:    "App push, curl and delete",
+		Timeout: 5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			return cfg.checkApp(ctx, cfBinary)
+		},
+	}
+}
+
+func (cfg Config) checkApp(ctx context.Context, cfBinary string) error {
+	tmpDir, err := ioutil.TempDir("", "cfdev-smoke-test-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	appDir := filepath.Join(tmpDir, "app")
+	if err := os.MkdirAll(appDir, 0755); err != nil {
+		return err
+	}
+
+	for name, contents := range appFiles {
+		if err := ioutil.WriteFile(filepath.Join(appDir, name), []byte(contents), 0644); err != nil {
+			return err
+		}
+	}
+
+	var (
+		appName = fmt.Sprintf("smoke-test-%d", time.Now().Unix())
+		cfWith  = func(ctx context.Context, args ...string) error {
+			cmd := exec.CommandContext(ctx, cfBinary, args...)
+			cmd.Env = append(append(os.Environ(), config.ProxyEnvs(cfg.Proxy)...), "CF_HOME="+tmpDir)
+
+			if output, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("'cf %s' failed: %s", args[0], lastLine(output))
+			}
+			return nil
+		}
+		cf = func(args ...string) error {
+			return cfWith(ctx, args...)
+		}
+	)
+
+	// Clean up even when the check timed out
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		cfWith(ctx, "delete-org", smokeTestOrg, "-f")
+	}()
+
+	steps := [][]string{
+		{"api", cfg.APIURL, "--skip-ssl-validation"},
+		{"auth", cfg.Credentials.Username, cfg.Credentials.Password},
+		{"create-org", smokeTestOrg},
+		{"target", "-o", smokeTestOrg},
+		{"create-space", smokeTestSpace},
+		{"target", "-o", smokeTestOrg, "-s", smokeTestSpace},
+	}
+
+	for _, step := range steps {
+		if err := cf(step...); err != nil {
+			return err
+		}
+	}
+
+	if err := cf("push", appName, "-p", appDir, "-b", "staticfile_buildpack", "-m", "64M", "-k", "64M"); err != nil {
+		return err
+	}
+
+	body, err := cfg.get(ctx, "http://"+cfg.RouterAddr+"/", appName+"."+cfg.Domain)
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(string(body), appMarker) {
+		return fmt.Errorf("unexpected response from %s.%s", appName, cfg.Domain)
+	}
+
+	return cf("delete", appName, "-f", "-r")
+}
+
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
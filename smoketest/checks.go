@@ -0,0 +1,161 @@
+package smoketest
+
+import (
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	APIURL      string
+	UAAURL      string
+	RouterAddr  string
+	Domain      string
+	Credentials workspace.Credentials
+	BoshEnvs    map[string]string
+	Proxy       string
+}
+
+// Checks returns the checks of the CF Dev components, in dependency order.
+func Checks(cfg Config) []Check {
+	return []Check{
+		{Name: "CF API", Timeout: 30 * time.Second, Run: cfg.checkAPI},
+		{Name: "UAA token endpoint", Timeout: 30 * time.Second, Run: cfg.checkUAA},
+		{Name: "Router", Timeout: 30 * time.Second, Run: cfg.checkRouter},
+		{Name: "BOSH Director", Timeout: 30 * time.Second, Run: cfg.checkDirector},
+	}
+}
+
+func (cfg Config) checkAPI(ctx context.Context) error {
+	resp, err := cfg.get(ctx, cfg.APIURL+"/v2/info", "")
+	if err != nil {
+		return err
+	}
+
+	var info struct {
+		APIVersion string `json:"api_version"`
+	}
+
+	if err := json.Unmarshal(resp, &info); err != nil || info.APIVersion == "" {
+		return fmt.Errorf("unexpected response from %s/v2/info", cfg.APIURL)
+	}
+
+	return nil
+}
+
+func (cfg Config) checkUAA(ctx context.Context) error {
+	form := url.Values{
+		"grant_type": {"password"},
+		"username":   {cfg.Credentials.Username},
+		"password":   {cfg.Credentials.Password},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, cfg.UAAURL+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth("cf", "")
+
+	resp, err := cfg.do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+
+	if err := json.Unmarshal(resp, &token); err != nil || token.AccessToken == "" {
+		return fmt.Errorf("no access token was issued for %s", cfg.Credentials.Username)
+	}
+
+	return nil
+}
+
+// checkRouter only requires the router to answer: a request for
+// an unknown route is expected to fail with its own error header.
+func (cfg Config) checkRouter(ctx context.Context) error {
+	req, err := http.NewRequest(http.MethodGet, "http://"+cfg.RouterAddr+"/", nil)
+	if err != nil {
+		return err
+	}
+	req.Host = "cfdev-smoke-test-unknown-route." + cfg.Domain
+
+	resp, err := cfg.client().Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.Header.Get("X-Cf-Routererror") == "" {
+		return fmt.Errorf("%s did not respond like a gorouter (status %d)", cfg.RouterAddr, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (cfg Config) checkDirector(ctx context.Context) error {
+	client, err := bosh.New(cfg.BoshEnvs)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Deployments()
+	return err
+}
+
+func (cfg Config) get(ctx context.Context, url string, host string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if host != "" {
+		req.Host = host
+	}
+
+	return cfg.do(ctx, req)
+}
+
+func (cfg Config) do(ctx context.Context, req *http.Request) ([]byte, error) {
+	resp, err := cfg.client().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with %d", req.URL, resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// client skips certificate validation, as CF Dev uses self-signed
+// certificates, and goes through the proxy to CF Dev if there is one.
+func (cfg Config) client() *http.Client {
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+
+	if proxy, err := url.Parse(cfg.Proxy); err == nil && cfg.Proxy != "" {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,71 @@
+package smoketest
+
+import (
+	"context"
+	"time"
+)
+
+// Check is a single health check of the environment.
+type Check struct {
+	Name    string
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type Result struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Run executes the checks one after the other, each bounded by its own
+// timeout, and reports every result as soon as it is known.
+func Run(ctx context.Context, checks []Check, report func(Result)) []Result {
+	var results []Result
+
+	for _, check := range checks {
+		result := run(ctx, check)
+		report(result)
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func run(parent context.Context, check Check) Result {
+	var (
+		start       = time.Now()
+		ctx, cancel = context.WithTimeout(parent, check.Timeout)
+		errChan     = make(chan error, 1)
+	)
+	defer cancel()
+
+	go func() {
+		errChan <- check.Run(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err == context.DeadlineExceeded {
+		err = &timeoutError{timeout: check.Timeout}
+	}
+
+	return Result{Name: check.Name, Duration: time.Now().Sub(start), Err: err}
+}
+
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return "timed out after " + e.timeout.String()
+}
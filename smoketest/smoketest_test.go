@@ -0,0 +1,120 @@
+package smoketest_test
+
+import (
+	"code.cloudfoundry.org/cfdev/smoketest"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Run", func() {
+	It("reports every check and times out the slow ones", func() {
+		var reported []string
+
+		results := smoketest.Run(context.Background(), []smoketest.Check{
+			{Name: "passing", Timeout: time.Second, Run: func(context.Context) error { return nil }},
+			{Name: "failing", Timeout: time.Second, Run: func(context.Context) error { return errors.New("some-error") }},
+			{Name: "slow", Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			}},
+		}, func(r smoketest.Result) {
+			reported = append(reported, r.Name)
+		})
+
+		Expect(reported).To(Equal([]string{"passing", "failing", "slow"}))
+		Expect(results[0].Passed()).To(BeTrue())
+		Expect(results[1].Err).To(MatchError("some-error"))
+		Expect(results[2].Err).To(MatchError("timed out after 10ms"))
+	})
+})
+
+var _ = Describe("Checks", func() {
+	var (
+		server    *httptest.Server
+		cfg       smoketest.Config
+		apiStatus int
+	)
+
+	BeforeEach(func() {
+		apiStatus = http.StatusOK
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/v2/info":
+				w.WriteHeader(apiStatus)
+				fmt.Fprint(w, `{"api_version": "2.120.0"}`)
+			case r.URL.Path == "/oauth/token":
+				r.ParseForm()
+				if r.Form.Get("username") != "admin" || r.Form.Get("password") != "some-password" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprint(w, `{"access_token": "some-token"}`)
+			}
+		}))
+
+		cfg = smoketest.Config{
+			APIURL:      server.URL,
+			UAAURL:      server.URL,
+			Domain:      "dev.cfdev.sh",
+			Credentials: workspace.Credentials{Username: "admin", Password: "some-password"},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	run := func(name string) error {
+		for _, check := range smoketest.Checks(cfg) {
+			if check.Name == name {
+				return check.Run(context.Background())
+			}
+		}
+
+		Fail("no such check: " + name)
+		return nil
+	}
+
+	It("checks the CF API", func() {
+		Expect(run("CF API")).To(Succeed())
+
+		apiStatus = http.StatusBadGateway
+		Expect(run("CF API")).To(MatchError(ContainSubstring("responded with 502")))
+	})
+
+	It("checks that UAA issues tokens", func() {
+		Expect(run("UAA token endpoint")).To(Succeed())
+
+		cfg.Credentials.Password = "wrong-password"
+		Expect(run("UAA token endpoint")).To(MatchError(ContainSubstring("responded with 401")))
+	})
+
+	It("checks that the router answers for unknown routes", func() {
+		router := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Host).To(HaveSuffix(".dev.cfdev.sh"))
+			w.Header().Set("X-Cf-Routererror", "unknown_route")
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer router.Close()
+
+		cfg.RouterAddr = strings.TrimPrefix(router.URL, "http://")
+		Expect(run("Router")).To(Succeed())
+
+		cfg.RouterAddr = strings.TrimPrefix(server.URL, "https://")
+		Expect(run("Router")).To(HaveOccurred())
+	})
+
+	It("fails the BOSH Director check without credentials", func() {
+		Expect(run("BOSH Director")).To(MatchError(ContainSubstring("BOSH_ENVIRONMENT is not set")))
+	})
+})
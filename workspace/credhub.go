@@ -0,0 +1,147 @@
+package workspace
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// cfAdminPasswordName is the CredHub variable that
+// cf-deployment generates for the CF admin user.
+const cfAdminPasswordName = "/cf/cf_admin_password"
+
+// credhubCFAdminPassword reads the password of the CF admin user from the
+// CredHub of the BOSH Director, with the credentials of the env.yml.
+func credhubCFAdminPassword(envs map[string]string) (string, error) {
+	if envs["CREDHUB_SERVER"] == "" {
+		return "", fmt.Errorf("the env.yml does not contain the CredHub credentials")
+	}
+
+	client, err := credhubClient(envs)
+	if err != nil {
+		return "", err
+	}
+
+	server := strings.TrimSuffix(envs["CREDHUB_SERVER"], "/")
+
+	var info struct {
+		AuthServer struct {
+			URL string `json:"url"`
+		} `json:"auth-server"`
+	}
+	if err := getJSON(client, server+"/info", "", &info); err != nil {
+		return "", err
+	}
+
+	token, err := uaaToken(client, info.AuthServer.URL, envs["CREDHUB_CLIENT"], envs["CREDHUB_SECRET"])
+	if err != nil {
+		return "", err
+	}
+
+	var found struct {
+		Credentials []struct {
+			Name string `json:"name"`
+		} `json:"credentials"`
+	}
+	if err := getJSON(client, server+"/api/v1/data?name-like="+url.QueryEscape(cfAdminPasswordName), token, &found); err != nil {
+		return "", err
+	}
+
+	for _, credential := range found.Credentials {
+		if !strings.HasSuffix(credential.Name, cfAdminPasswordName) {
+			continue
+		}
+
+		var current struct {
+			Data []struct {
+				Value string `json:"value"`
+			} `json:"data"`
+		}
+		if err := getJSON(client, server+"/api/v1/data?current=true&name="+url.QueryEscape(credential.Name), token, &current); err != nil {
+			return "", err
+		}
+
+		if len(current.Data) > 0 && current.Data[0].Value != "" {
+			return current.Data[0].Value, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s was not found in CredHub", cfAdminPasswordName)
+}
+
+// credhubClient trusts the CA of the env.yml and, without root
+// privileges, reaches CredHub through the proxy of the BOSH Director.
+func credhubClient(envs map[string]string) (*http.Client, error) {
+	transport := &http.Transport{TLSClientConfig: &tls.Config{}}
+
+	if caCert := envs["CREDHUB_CA_CERT"]; caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, fmt.Errorf("the CredHub CA certificate of the env.yml is invalid")
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if proxy := envs["BOSH_ALL_PROXY"]; strings.HasPrefix(proxy, "socks5://") {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{Transport: transport, Timeout: 10 * time.Second}, nil
+}
+
+func uaaToken(client *http.Client, uaaURL string, clientID string, clientSecret string) (string, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+
+	req, err := http.NewRequest("POST", strings.TrimSuffix(uaaURL, "/")+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(clientID, clientSecret)
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(client, req, &token); err != nil {
+		return "", err
+	}
+
+	return token.AccessToken, nil
+}
+
+func getJSON(client *http.Client, url string, token string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return doJSON(client, req, v)
+}
+
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returned %s", req.Method, req.URL.Path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,105 @@
+package workspace_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("CFCredentials", func() {
+	var (
+		tmpDir string
+		server *httptest.Server
+		ws     *workspace.Workspace
+	)
+
+	writeEnvs := func(envs map[string]string) {
+		data, err := yaml.Marshal(envs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "env.yml"), data, 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-credentials-")
+		Expect(err).NotTo(HaveOccurred())
+
+		ws = workspace.New(config.Config{StateBosh: tmpDir})
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"auth-server": map[string]string{"url": server.URL}})
+		})
+		mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+			if client, secret, _ := r.BasicAuth(); client != "credhub-admin" || secret != "some-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "some-token"})
+		})
+		mux.HandleFunc("/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer some-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			if r.URL.Query().Get("name-like") != "" {
+				json.NewEncoder(w).Encode(map[string]interface{}{"credentials": []map[string]string{
+					{"name": "/cfdev/cf-mysql/cf_admin_password_mysql"},
+					{"name": "/cfdev/cf/cf_admin_password"},
+				}})
+				return
+			}
+
+			if r.URL.Query().Get("name") != "/cfdev/cf/cf_admin_password" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]string{{"value": "generated-password"}}})
+		})
+
+		server = httptest.NewTLSServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("reads the admin password generated by the deployment from CredHub", func() {
+		writeEnvs(map[string]string{
+			"CREDHUB_SERVER":  server.URL,
+			"CREDHUB_CLIENT":  "credhub-admin",
+			"CREDHUB_SECRET":  "some-secret",
+			"CREDHUB_CA_CERT": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+		})
+
+		Expect(ws.CFCredentials()).To(Equal(workspace.Credentials{Username: "admin", Password: "generated-password"}))
+	})
+
+	It("prefers the credentials declared in the env.yml", func() {
+		writeEnvs(map[string]string{
+			"CREDHUB_SERVER":    server.URL,
+			"CF_ADMIN_USERNAME": "some-admin",
+			"CF_ADMIN_PASSWORD": "some-password",
+		})
+
+		Expect(ws.CFCredentials()).To(Equal(workspace.Credentials{Username: "some-admin", Password: "some-password"}))
+	})
+
+	It("falls back to the CF Dev defaults without CredHub", func() {
+		writeEnvs(map[string]string{"BOSH_ENVIRONMENT": "10.144.0.2"})
+
+		Expect(ws.CFCredentials()).To(Equal(workspace.Credentials{Username: "admin", Password: "admin"}))
+	})
+})
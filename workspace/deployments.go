@@ -0,0 +1,84 @@
+package workspace
+
+import (
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// CustomDeployment is a BOSH manifest deployed with
+// 'cf dev deploy-service --manifest' rather than a service script.
+type CustomDeployment struct {
+	Name      string   `yaml:"name"`
+	Manifest  string   `yaml:"manifest"`
+	OpsFiles  []string `yaml:"ops_files,omitempty"`
+	VarsFiles []string `yaml:"vars_files,omitempty"`
+	Releases  []string `yaml:"releases,omitempty"`
+}
+
+func (w *Workspace) CustomDeployments() ([]CustomDeployment, error) {
+	data, err := ioutil.ReadFile(w.deploymentsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var deployments []CustomDeployment
+	err = yaml.Unmarshal(data, &deployments)
+	return deployments, err
+}
+
+// RecordDeployment stores the deployment, replacing
+// any previous record of a deployment with the same name.
+func (w *Workspace) RecordDeployment(deployment CustomDeployment) error {
+	deployments, err := w.CustomDeployments()
+	if err != nil {
+		return err
+	}
+
+	var replaced bool
+	for i, d := range deployments {
+		if d.Name == deployment.Name {
+			deployments[i] = deployment
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		deployments = append(deployments, deployment)
+	}
+
+	return w.saveDeployments(deployments)
+}
+
+// RemoveDeployment forgets the deployment with the given name.
+func (w *Workspace) RemoveDeployment(name string) error {
+	deployments, err := w.CustomDeployments()
+	if err != nil {
+		return err
+	}
+
+	var kept []CustomDeployment
+	for _, d := range deployments {
+		if d.Name != name {
+			kept = append(kept, d)
+		}
+	}
+
+	return w.saveDeployments(kept)
+}
+
+func (w *Workspace) saveDeployments(deployments []CustomDeployment) error {
+	data, err := yaml.Marshal(deployments)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(w.deploymentsPath(), data, 0600)
+}
+
+func (w *Workspace) deploymentsPath() string {
+	return filepath.Join(w.Config.StateDir, "deployments.yml")
+}
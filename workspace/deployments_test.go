@@ -0,0 +1,59 @@
+package workspace_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CustomDeployments", func() {
+	var (
+		stateDir string
+		ws       *workspace.Workspace
+	)
+
+	BeforeEach(func() {
+		var err error
+		stateDir, err = ioutil.TempDir("", "cfdev-deployments-")
+		Expect(err).NotTo(HaveOccurred())
+
+		ws = workspace.New(config.Config{StateDir: stateDir})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(stateDir)
+	})
+
+	It("returns no deployments when none were recorded", func() {
+		deployments, err := ws.CustomDeployments()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployments).To(BeEmpty())
+	})
+
+	It("records deployments and replaces redeployed ones", func() {
+		Expect(ws.RecordDeployment(workspace.CustomDeployment{Name: "one", Manifest: "/one.yml"})).To(Succeed())
+		Expect(ws.RecordDeployment(workspace.CustomDeployment{Name: "two", Manifest: "/two.yml"})).To(Succeed())
+		Expect(ws.RecordDeployment(workspace.CustomDeployment{Name: "one", Manifest: "/one.yml", OpsFiles: []string{"/ops.yml"}})).To(Succeed())
+
+		deployments, err := ws.CustomDeployments()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployments).To(Equal([]workspace.CustomDeployment{
+			{Name: "one", Manifest: "/one.yml", OpsFiles: []string{"/ops.yml"}},
+			{Name: "two", Manifest: "/two.yml"},
+		}))
+	})
+
+	It("removes deployments", func() {
+		Expect(ws.RecordDeployment(workspace.CustomDeployment{Name: "one", Manifest: "/one.yml"})).To(Succeed())
+		Expect(ws.RecordDeployment(workspace.CustomDeployment{Name: "two", Manifest: "/two.yml"})).To(Succeed())
+		Expect(ws.RemoveDeployment("one")).To(Succeed())
+
+		deployments, err := ws.CustomDeployments()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployments).To(Equal([]workspace.CustomDeployment{{Name: "two", Manifest: "/two.yml"}}))
+	})
+})
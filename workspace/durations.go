@@ -0,0 +1,80 @@
+package workspace
+
+import (
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	DurationVM   = "vm"
+	DurationBosh = "bosh"
+
+	keptDurations = 3
+)
+
+// RecordDuration remembers how long a provisioning step took. The durations
+// are kept in the CF Dev home directory, as the state directory is reset
+// every time CF Dev is started.
+func (w *Workspace) RecordDuration(step string, duration time.Duration) error {
+	durations, err := w.readDurations()
+	if err != nil {
+		return err
+	}
+
+	durations[step] = append(durations[step], duration)
+	if len(durations[step]) > keptDurations {
+		durations[step] = durations[step][len(durations[step])-keptDurations:]
+	}
+
+	data, err := yaml.Marshal(durations)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(w.durationsPath(), data, 0600)
+}
+
+// Durations returns the average duration of the last runs of every step.
+func (w *Workspace) Durations() (map[string]time.Duration, error) {
+	durations, err := w.readDurations()
+	if err != nil {
+		return nil, err
+	}
+
+	averages := map[string]time.Duration{}
+	for step, runs := range durations {
+		if len(runs) == 0 {
+			continue
+		}
+
+		var total time.Duration
+		for _, d := range runs {
+			total += d
+		}
+
+		averages[step] = total / time.Duration(len(runs))
+	}
+
+	return averages, nil
+}
+
+func (w *Workspace) readDurations() (map[string][]time.Duration, error) {
+	durations := map[string][]time.Duration{}
+
+	data, err := ioutil.ReadFile(w.durationsPath())
+	if os.IsNotExist(err) {
+		return durations, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, &durations)
+	return durations, err
+}
+
+func (w *Workspace) durationsPath() string {
+	return filepath.Join(w.Config.CFDevHome, "durations.yml")
+}
@@ -0,0 +1,51 @@
+package workspace_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"io/ioutil"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Durations", func() {
+	var (
+		homeDir string
+		ws      *workspace.Workspace
+	)
+
+	BeforeEach(func() {
+		var err error
+		homeDir, err = ioutil.TempDir("", "cfdev-durations-")
+		Expect(err).NotTo(HaveOccurred())
+
+		ws = workspace.New(config.Config{CFDevHome: homeDir})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(homeDir)
+	})
+
+	It("returns no durations when nothing was recorded", func() {
+		durations, err := ws.Durations()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(durations).To(BeEmpty())
+	})
+
+	It("averages the last three runs of every step", func() {
+		for _, d := range []time.Duration{time.Hour, 4 * time.Minute, 6 * time.Minute, 8 * time.Minute} {
+			Expect(ws.RecordDuration(workspace.DurationBosh, d)).To(Succeed())
+		}
+		Expect(ws.RecordDuration("mysql", 3*time.Minute)).To(Succeed())
+
+		durations, err := ws.Durations()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(durations).To(Equal(map[string]time.Duration{
+			workspace.DurationBosh: 6 * time.Minute,
+			"mysql":                3 * time.Minute,
+		}))
+	})
+})
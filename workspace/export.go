@@ -0,0 +1,284 @@
+package workspace
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const exportManifestName = "manifest.yml"
+
+type ExportManifest struct {
+	PluginVersion        string            `yaml:"plugin_version"`
+	ArtifactVersion      string            `yaml:"artifact_version"`
+	CompatibilityVersion string            `yaml:"compatibility_version"`
+	Platform             string            `yaml:"platform"`
+	Settings             Settings          `yaml:"settings"`
+	Checksums            map[string]string `yaml:"checksums"`
+}
+
+// Export packages the state of a stopped environment, including the VM disk
+// and the BOSH state, together with the binaries and service scripts it was
+// provisioned with. The manifest is written first so that an import can
+// be refused before anything gets extracted.
+func (w *Workspace) Export(path string, manifest ExportManifest) error {
+	files, err := w.exportFiles()
+	if err != nil {
+		return err
+	}
+
+	manifest.Checksums = map[string]string{}
+	for _, file := range files {
+		sum, err := checksum(filepath.Join(w.Config.CFDevHome, file))
+		if err != nil {
+			return err
+		}
+
+		manifest.Checksums[filepath.ToSlash(file)] = sum
+	}
+
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := w.writeExport(f, data, files); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// writeExport writes the manifest and the files as a gzipped tarball.
+// The writers are closed explicitly, as closing them writes the end
+// of the archive.
+func (w *Workspace) writeExport(out io.Writer, manifest []byte, files []string) error {
+	gzw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gzw)
+
+	err := tw.WriteHeader(&tar.Header{Name: exportManifestName, Typeflag: tar.TypeReg, Mode: 0600, Size: int64(len(manifest))})
+	if err != nil {
+		return err
+	}
+
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := addToTar(tw, w.Config.CFDevHome, file); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gzw.Close()
+}
+
+func (w *Workspace) ReadExportManifest(path string) (ExportManifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ExportManifest{}, err
+	}
+	defer f.Close()
+
+	gzr, err := gzip.NewReader(f)
+	if err != nil {
+		return ExportManifest{}, err
+	}
+	defer gzr.Close()
+
+	return readExportManifest(tar.NewReader(gzr))
+}
+
+// Import extracts an exported environment into the CF Dev home directory,
+// verifying every file against the checksums of the manifest.
+func (w *Workspace) Import(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gzr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+
+	manifest, err := readExportManifest(tr)
+	if err != nil {
+		return err
+	}
+
+	extracted := map[string]bool{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		expected, ok := manifest.Checksums[header.Name]
+		if !ok {
+			return fmt.Errorf("unexpected file in package: %s", header.Name)
+		}
+
+		target := filepath.Join(w.Config.CFDevHome, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(w.Config.CFDevHome)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid file path in package: %s", header.Name)
+		}
+
+		sum, err := extractFile(tr, target, os.FileMode(header.Mode))
+		if err != nil {
+			return err
+		}
+
+		if sum != expected {
+			return fmt.Errorf("checksum mismatch for %s", header.Name)
+		}
+
+		extracted[header.Name] = true
+	}
+
+	for name := range manifest.Checksums {
+		if !extracted[name] {
+			return fmt.Errorf("file missing from package: %s", name)
+		}
+	}
+
+	return nil
+}
+
+func (w *Workspace) exportFiles() ([]string, error) {
+	var files []string
+
+	for _, dir := range []string{w.Config.StateDir, w.Config.BinaryDir, w.Config.ServicesDir} {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// Sockets, pid files and the cached VM address
+			// only make sense for the VM that created them
+			if !info.Mode().IsRegular() ||
+				filepath.Ext(path) == ".pid" ||
+				path == filepath.Join(w.Config.StateLinuxkit, "ip") {
+				return nil
+			}
+
+			rel, err := filepath.Rel(w.Config.CFDevHome, path)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, rel)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func readExportManifest(tr *tar.Reader) (ExportManifest, error) {
+	header, err := tr.Next()
+	if err != nil {
+		return ExportManifest{}, err
+	}
+
+	if header.Name != exportManifestName {
+		return ExportManifest{}, fmt.Errorf("not a CF Dev environment package: %s is missing", exportManifestName)
+	}
+
+	var manifest ExportManifest
+	err = yaml.NewDecoder(tr).Decode(&manifest)
+	return manifest, err
+}
+
+func addToTar(tw *tar.Writer, baseDir string, file string) error {
+	f, err := os.Open(filepath.Join(baseDir, file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+
+	header.Name = filepath.ToSlash(file)
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func extractFile(r io.Reader, target string, mode os.FileMode) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,97 @@
+package workspace_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Export", func() {
+	var (
+		homeDir   string
+		importDir string
+		pkgPath   string
+		ws        *workspace.Workspace
+		importWs  *workspace.Workspace
+	)
+
+	newWorkspace := func(dir string) *workspace.Workspace {
+		return workspace.New(config.Config{
+			CFDevHome:     dir,
+			StateDir:      filepath.Join(dir, "state"),
+			StateLinuxkit: filepath.Join(dir, "state", "linuxkit"),
+			BinaryDir:     filepath.Join(dir, "bin"),
+			ServicesDir:   filepath.Join(dir, "services"),
+		})
+	}
+
+	BeforeEach(func() {
+		var err error
+		homeDir, err = ioutil.TempDir("", "cfdev-export-")
+		Expect(err).NotTo(HaveOccurred())
+		importDir, err = ioutil.TempDir("", "cfdev-import-")
+		Expect(err).NotTo(HaveOccurred())
+
+		pkgPath = filepath.Join(homeDir, "env.tgz")
+		ws = newWorkspace(homeDir)
+		importWs = newWorkspace(importDir)
+
+		writeFiles(homeDir, map[string]string{
+			"bin/some-binary":         "binary",
+			"services/deploy-cf":      "script",
+			"state/metadata.yml":      "artifact_version: some-version",
+			"state/bosh/state.json":   "director-state",
+			"state/linuxkit/disk.img": "disk",
+			"state/linuxkit/ip":       "10.0.0.1",
+			"state/linuxkit/vm.pid":   "1234",
+		})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(homeDir)
+		os.RemoveAll(importDir)
+	})
+
+	It("round trips the environment", func() {
+		Expect(ws.SaveSettings(workspace.Settings{Cpus: 4, Memory: 8192})).To(Succeed())
+
+		err := ws.Export(pkgPath, workspace.ExportManifest{
+			PluginVersion:   "0.0.16",
+			ArtifactVersion: "some-version",
+			Platform:        "linux",
+			Settings:        workspace.Settings{Cpus: 4, Memory: 8192},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		manifest, err := importWs.ReadExportManifest(pkgPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifest.ArtifactVersion).To(Equal("some-version"))
+		Expect(manifest.Settings).To(Equal(workspace.Settings{Cpus: 4, Memory: 8192}))
+		Expect(manifest.Checksums).To(HaveKey("state/bosh/state.json"))
+		Expect(manifest.Checksums).NotTo(HaveKey("state/linuxkit/ip"))
+		Expect(manifest.Checksums).NotTo(HaveKey("state/linuxkit/vm.pid"))
+
+		Expect(importWs.Import(pkgPath)).To(Succeed())
+		Expect(readFile(importDir, "bin/some-binary")).To(Equal("binary"))
+		Expect(readFile(importDir, "services/deploy-cf")).To(Equal("script"))
+		Expect(readFile(importDir, "state/bosh/state.json")).To(Equal("director-state"))
+		Expect(readFile(importDir, "state/linuxkit/disk.img")).To(Equal("disk"))
+		Expect(filepath.Join(importDir, "state", "linuxkit", "ip")).NotTo(BeAnExistingFile())
+
+		settings, err := importWs.Settings()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(settings.Cpus).To(Equal(4))
+	})
+
+	It("rejects a tarball without a manifest", func() {
+		writeTarball(pkgPath, map[string]string{"state/metadata.yml": "artifact_version: other"})
+
+		_, err := importWs.ReadExportManifest(pkgPath)
+		Expect(err).To(MatchError(ContainSubstring("manifest.yml is missing")))
+	})
+})
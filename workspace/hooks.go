@@ -0,0 +1,187 @@
+package workspace
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	HookPreStart       = "pre-start"
+	HookPostVM         = "post-vm"
+	HookPostDirector   = "post-director"
+	HookPostService    = "post-service"
+	HookPostProvision  = "post-provision"
+	HookPreStop        = "pre-stop"
+	defaultHookTimeout = 10 * time.Minute
+)
+
+// Hook is a user script run at one of the hook points of the
+// lifecycle of CF Dev. Hooks are either executables placed under
+// CFDEV_HOME/hooks/<point>/ (CFDEV_HOME/hooks/post-service/<name>/ for
+// a service) or shell commands declared in CFDEV_HOME/hooks.yml.
+type Hook struct {
+	Point   string        `yaml:"point"`
+	Name    string        `yaml:"name"`
+	Command string        `yaml:"command"`
+	Script  string        `yaml:"-"`
+	Timeout time.Duration `yaml:"timeout"`
+	Fatal   bool          `yaml:"fatal"`
+}
+
+// ServiceHookPoint is the hook point run once the given service is deployed.
+func ServiceHookPoint(name string) string {
+	return HookPostService + ":" + name
+}
+
+// Hooks returns the hooks of the given hook points: first the ones
+// found in the hooks directory, in name order, then the ones
+// declared in the hooks file. Hook points are case insensitive.
+func (w *Workspace) Hooks(points ...string) ([]Hook, error) {
+	dirHooks, err := w.dirHooks()
+	if err != nil {
+		return nil, err
+	}
+
+	fileHooks, err := w.fileHooks()
+	if err != nil {
+		return nil, err
+	}
+
+	var hooks []Hook
+	for _, hook := range append(dirHooks, fileHooks...) {
+		if hasPoint(points, hook.Point) {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	return hooks, nil
+}
+
+func (w *Workspace) dirHooks() ([]Hook, error) {
+	var hooks []Hook
+
+	for _, point := range []string{HookPreStart, HookPostVM, HookPostDirector, HookPostProvision, HookPreStop} {
+		scripts, err := hookScripts(filepath.Join(w.hooksDir(), point), point)
+		if err != nil {
+			return nil, err
+		}
+
+		hooks = append(hooks, scripts...)
+	}
+
+	services, err := ioutil.ReadDir(filepath.Join(w.hooksDir(), HookPostService))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, service := range services {
+		if !service.IsDir() {
+			continue
+		}
+
+		scripts, err := hookScripts(filepath.Join(w.hooksDir(), HookPostService, service.Name()), ServiceHookPoint(service.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		hooks = append(hooks, scripts...)
+	}
+
+	return hooks, nil
+}
+
+func (w *Workspace) fileHooks() ([]Hook, error) {
+	data, err := ioutil.ReadFile(w.hooksFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Hooks []Hook `yaml:"hooks"`
+	}
+
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid hooks file %s: %s", w.hooksFile(), err)
+	}
+
+	for i := range file.Hooks {
+		hook := &file.Hooks[i]
+
+		if !isHookPoint(hook.Point) {
+			return nil, fmt.Errorf("invalid hooks file %s: unknown hook point '%s'", w.hooksFile(), hook.Point)
+		}
+
+		if hook.Command == "" {
+			return nil, fmt.Errorf("invalid hooks file %s: the %s hook has no command", w.hooksFile(), hook.Point)
+		}
+
+		if hook.Name == "" {
+			hook.Name = fmt.Sprintf("hook-%d", i+1)
+		}
+
+		if hook.Timeout <= 0 {
+			hook.Timeout = defaultHookTimeout
+		}
+	}
+
+	return file.Hooks, nil
+}
+
+func (w *Workspace) hooksDir() string {
+	return filepath.Join(w.Config.CFDevHome, "hooks")
+}
+
+func (w *Workspace) hooksFile() string {
+	return filepath.Join(w.Config.CFDevHome, "hooks.yml")
+}
+
+func hookScripts(dir string, point string) ([]Hook, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var hooks []Hook
+	for _, file := range files {
+		if !file.Mode().IsRegular() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		hooks = append(hooks, Hook{
+			Point:   point,
+			Name:    file.Name(),
+			Script:  filepath.Join(dir, file.Name()),
+			Timeout: defaultHookTimeout,
+		})
+	}
+
+	return hooks, nil
+}
+
+func isHookPoint(point string) bool {
+	switch point {
+	case HookPreStart, HookPostVM, HookPostDirector, HookPostProvision, HookPreStop:
+		return true
+	default:
+		return strings.HasPrefix(point, HookPostService+":") && len(point) > len(HookPostService)+1
+	}
+}
+
+func hasPoint(points []string, point string) bool {
+	for _, p := range points {
+		if strings.EqualFold(p, point) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+package workspace_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hooks", func() {
+	var (
+		homeDir string
+		ws      *workspace.Workspace
+	)
+
+	BeforeEach(func() {
+		var err error
+		homeDir, err = ioutil.TempDir("", "cfdev-hooks-")
+		Expect(err).NotTo(HaveOccurred())
+
+		ws = workspace.New(config.Config{CFDevHome: homeDir})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(homeDir)
+	})
+
+	writeFile := func(path string, contents string) {
+		path = filepath.Join(homeDir, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0755)).To(Succeed())
+	}
+
+	It("returns no hooks when none are defined", func() {
+		hooks, err := ws.Hooks(workspace.HookPreStart)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hooks).To(BeEmpty())
+	})
+
+	It("loads the scripts of the hooks directory in name order", func() {
+		writeFile("hooks/post-director/20-second", "")
+		writeFile("hooks/post-director/10-first", "")
+		writeFile("hooks/post-director/.hidden", "")
+		writeFile("hooks/pre-stop/cleanup", "")
+
+		hooks, err := ws.Hooks(workspace.HookPostDirector)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hooks).To(Equal([]workspace.Hook{
+			{Point: "post-director", Name: "10-first", Script: filepath.Join(homeDir, "hooks", "post-director", "10-first"), Timeout: 10 * time.Minute},
+			{Point: "post-director", Name: "20-second", Script: filepath.Join(homeDir, "hooks", "post-director", "20-second"), Timeout: 10 * time.Minute},
+		}))
+	})
+
+	It("loads the service hooks from a directory per service", func() {
+		writeFile("hooks/post-service/cf/seed-registry", "")
+		writeFile("hooks/post-service/cf-mysql/create-broker", "")
+
+		hooks, err := ws.Hooks(workspace.ServiceHookPoint("CF"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hooks).To(HaveLen(1))
+		Expect(hooks[0].Point).To(Equal("post-service:cf"))
+		Expect(hooks[0].Name).To(Equal("seed-registry"))
+	})
+
+	It("loads the hooks of the hooks file after the ones of the hooks directory", func() {
+		writeFile("hooks/post-provision/create-orgs", "")
+		writeFile("hooks.yml", `---
+hooks:
+- point: post-provision
+  command: cf create-org team
+- point: post-provision
+  name: install-ca
+  command: ./install-ca.sh
+  timeout: 30s
+  fatal: true
+- point: pre-start
+  command: echo starting
+`)
+
+		hooks, err := ws.Hooks(workspace.HookPostProvision)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hooks).To(HaveLen(3))
+		Expect(hooks[0].Name).To(Equal("create-orgs"))
+		Expect(hooks[1]).To(Equal(workspace.Hook{Point: "post-provision", Name: "hook-1", Command: "cf create-org team", Timeout: 10 * time.Minute}))
+		Expect(hooks[2]).To(Equal(workspace.Hook{Point: "post-provision", Name: "install-ca", Command: "./install-ca.sh", Timeout: 30 * time.Second, Fatal: true}))
+	})
+
+	It("rejects unknown hook points", func() {
+		writeFile("hooks.yml", `---
+hooks:
+- point: post-service
+  command: echo
+`)
+
+		_, err := ws.Hooks(workspace.HookPreStart)
+		Expect(err).To(MatchError(ContainSubstring("unknown hook point 'post-service'")))
+	})
+
+	It("rejects hooks without a command", func() {
+		writeFile("hooks.yml", `---
+hooks:
+- point: pre-stop
+`)
+
+		_, err := ws.Hooks(workspace.HookPreStop)
+		Expect(err).To(MatchError(ContainSubstring("the pre-stop hook has no command")))
+	})
+})
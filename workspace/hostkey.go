@@ -0,0 +1,27 @@
+package workspace
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// HostKey returns the SSH host key pinned on the first connection
+// to the VM, or nil when no connection was made yet.
+func (w *Workspace) HostKey() ([]byte, error) {
+	data, err := ioutil.ReadFile(w.hostKeyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+func (w *Workspace) SaveHostKey(key []byte) error {
+	return ioutil.WriteFile(w.hostKeyPath(), key, 0600)
+}
+
+func (w *Workspace) hostKeyPath() string {
+	return filepath.Join(w.Config.StateDir, config.HostKeyFile)
+}
@@ -0,0 +1,33 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OpsFiles returns the user ops files of the given BOSH manifest,
+// found under CFDEV_HOME/ops/<manifest>/, in name order.
+func (w *Workspace) OpsFiles(manifest string) ([]string, error) {
+	dir := filepath.Join(w.Config.CFDevHome, "ops", manifest)
+
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Name()))
+		if !file.Mode().IsRegular() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+
+		paths = append(paths, filepath.Join(dir, file.Name()))
+	}
+
+	return paths, nil
+}
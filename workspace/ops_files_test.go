@@ -0,0 +1,49 @@
+package workspace_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpsFiles", func() {
+	var (
+		homeDir string
+		ws      *workspace.Workspace
+	)
+
+	BeforeEach(func() {
+		var err error
+		homeDir, err = ioutil.TempDir("", "cfdev-ops-files-")
+		Expect(err).NotTo(HaveOccurred())
+
+		ws = workspace.New(config.Config{CFDevHome: homeDir})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(homeDir)
+	})
+
+	It("returns no ops files when there is no ops directory", func() {
+		paths, err := ws.OpsFiles("director")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(BeEmpty())
+	})
+
+	It("returns the YAML files of the manifest directory in name order", func() {
+		dir := filepath.Join(homeDir, "ops", "director")
+		Expect(os.MkdirAll(filepath.Join(dir, "nested.yml"), 0755)).To(Succeed())
+		for _, name := range []string{"b.yml", "a.yaml", "README.md"} {
+			Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte("[]"), 0600)).To(Succeed())
+		}
+
+		paths, err := ws.OpsFiles("director")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(Equal([]string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yml")}))
+	})
+})
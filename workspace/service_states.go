@@ -0,0 +1,69 @@
+package workspace
+
+import (
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	ServiceDeployed    = "deployed"
+	ServiceIncomplete  = "incomplete"
+	ServiceFailed      = "failed"
+	ServiceNotDeployed = "not deployed"
+)
+
+// ServiceState is the outcome of the last deployment of a service.
+type ServiceState struct {
+	Name       string    `yaml:"name"`
+	Deployment string    `yaml:"deployment"`
+	State      string    `yaml:"state"`
+	Updated    time.Time `yaml:"updated"`
+}
+
+func (w *Workspace) ServiceStates() ([]ServiceState, error) {
+	data, err := ioutil.ReadFile(w.serviceStatesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var states []ServiceState
+	err = yaml.Unmarshal(data, &states)
+	return states, err
+}
+
+// RecordServiceState stores the state, replacing any
+// previous state of a service with the same name.
+func (w *Workspace) RecordServiceState(state ServiceState) error {
+	states, err := w.ServiceStates()
+	if err != nil {
+		return err
+	}
+
+	var replaced bool
+	for i, s := range states {
+		if s.Name == state.Name {
+			states[i] = state
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		states = append(states, state)
+	}
+
+	data, err := yaml.Marshal(states)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(w.serviceStatesPath(), data, 0600)
+}
+
+func (w *Workspace) serviceStatesPath() string {
+	return filepath.Join(w.Config.StateDir, "services.yml")
+}
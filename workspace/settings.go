@@ -0,0 +1,51 @@
+package workspace
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// Settings are the options the VM was last started with.
+type Settings struct {
+	Cpus       int    `yaml:"cpus"`
+	Memory     int    `yaml:"memory"`
+	Disk       int    `yaml:"disk,omitempty"`
+	Registries string `yaml:"registries"`
+	Services   string `yaml:"services"`
+}
+
+// DiskSize is the size of the disk of the VM in GB. Environments
+// started by previous versions of CF Dev did not record it.
+func (s Settings) DiskSize() int {
+	if s.Disk == 0 {
+		return config.DefaultDiskSize
+	}
+
+	return s.Disk
+}
+
+func (w *Workspace) SaveSettings(settings Settings) error {
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(w.settingsPath(), data, 0600)
+}
+
+func (w *Workspace) Settings() (Settings, error) {
+	data, err := ioutil.ReadFile(w.settingsPath())
+	if err != nil {
+		return Settings{}, err
+	}
+
+	var settings Settings
+	err = yaml.Unmarshal(data, &settings)
+	return settings, err
+}
+
+func (w *Workspace) settingsPath() string {
+	return filepath.Join(w.Config.StateDir, "settings.yml")
+}
@@ -0,0 +1,266 @@
+package workspace
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ActionAdd     = "add"
+	ActionUpdate  = "update"
+	ActionDropped = "dropped"
+)
+
+type VersionChange struct {
+	Name string
+	From string
+	To   string
+}
+
+type ServiceChange struct {
+	Service Service
+	Action  string
+}
+
+type UpgradePlan struct {
+	From     string
+	To       string
+	Director bool
+	Versions []VersionChange
+	Changes  []ServiceChange
+}
+
+// Services returns the services that have to be redeployed, in deployment order.
+func (p UpgradePlan) Services() []Service {
+	var services []Service
+	for _, change := range p.Changes {
+		if change.Action != ActionDropped {
+			services = append(services, change.Service)
+		}
+	}
+
+	return services
+}
+
+// PlanUpgrade compares the installed metadata with the one of a newer asset.
+// Only services whose deployment is currently deployed, or that are always
+// included, are taken into account. Release versions are attributed to the
+// optional services by name and every remaining change to the always
+// included deployments.
+func PlanUpgrade(current Metadata, next Metadata, deployed []string) UpgradePlan {
+	plan := UpgradePlan{
+		From: current.ArtifactVersion,
+		To:   next.ArtifactVersion,
+	}
+
+	currentVersions := map[string]string{}
+	for _, v := range current.Versions {
+		currentVersions[v.Name] = v.Value
+	}
+
+	for _, v := range next.Versions {
+		if currentVersions[v.Name] != v.Value {
+			plan.Versions = append(plan.Versions, VersionChange{Name: v.Name, From: currentVersions[v.Name], To: v.Value})
+		}
+	}
+
+	var (
+		updated      = map[string]bool{}
+		unattributed bool
+	)
+
+	for _, v := range plan.Versions {
+		var attributed bool
+		for _, service := range next.Services {
+			if service.Flagname != "always-include" && releaseBelongsTo(v.Name, service) {
+				updated[service.Name] = true
+				attributed = true
+			}
+		}
+
+		unattributed = unattributed || !attributed
+	}
+
+	for _, service := range next.Services {
+		var (
+			old, exists  = findService(current.Services, service.Name)
+			alwaysDeploy = service.Flagname == "always-include"
+		)
+
+		switch {
+		case !exists && alwaysDeploy:
+			plan.Changes = append(plan.Changes, ServiceChange{Service: service, Action: ActionAdd})
+		case !exists || !(alwaysDeploy || isDeployed(deployed, old.Deployment)):
+			continue
+		case old != service, updated[service.Name], unattributed && alwaysDeploy:
+			plan.Changes = append(plan.Changes, ServiceChange{Service: service, Action: ActionUpdate})
+		}
+	}
+
+	for _, service := range current.Services {
+		if _, exists := findService(next.Services, service.Name); !exists && isDeployed(deployed, service.Deployment) {
+			plan.Changes = append(plan.Changes, ServiceChange{Service: service, Action: ActionDropped})
+		}
+	}
+
+	return plan
+}
+
+// StageUpgrade extracts a deps tarball next to the installed one
+// and returns its metadata, without touching the running environment.
+func (w *Workspace) StageUpgrade(depsFile string) (Metadata, error) {
+	if err := os.RemoveAll(w.upgradeDir()); err != nil {
+		return Metadata{}, err
+	}
+
+	if err := extract(depsFile, w.upgradeDir()); err != nil {
+		return Metadata{}, err
+	}
+
+	return readMetadata(filepath.Join(w.upgradeDir(), "state", "metadata.yml"))
+}
+
+// DirectorChanged tells whether the staged asset deploys or
+// configures the BOSH Director differently from the installed one.
+func (w *Workspace) DirectorChanged() (bool, error) {
+	for _, path := range w.directorPaths() {
+		installed, err := ioutil.ReadFile(filepath.Join(w.Config.CFDevHome, path))
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+
+		staged, err := ioutil.ReadFile(filepath.Join(w.upgradeDir(), path))
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+
+		if !bytes.Equal(installed, staged) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ApplyUpgrade swaps the binaries, service scripts, metadata and Director
+// manifests of the staged asset into place. The state and the vars store
+// of the BOSH Director are left untouched, as they belong to the running
+// director. The replaced files are kept until CleanupUpgrade.
+func (w *Workspace) ApplyUpgrade() error {
+	if err := os.RemoveAll(w.upgradeBackupDir()); err != nil {
+		return err
+	}
+
+	if err := mkdirAlls(filepath.Join(w.upgradeBackupDir(), "state", "bosh")); err != nil {
+		return err
+	}
+
+	// The DNS runtime configs are not part of every version of the assets
+	for _, path := range w.upgradePaths() {
+		err := os.Rename(filepath.Join(w.Config.CFDevHome, path), filepath.Join(w.upgradeBackupDir(), path))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		err = os.Rename(filepath.Join(w.upgradeDir(), path), filepath.Join(w.Config.CFDevHome, path))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RollbackUpgrade restores the files replaced by ApplyUpgrade.
+func (w *Workspace) RollbackUpgrade() error {
+	for _, path := range w.upgradePaths() {
+		backup := filepath.Join(w.upgradeBackupDir(), path)
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(w.Config.CFDevHome, path)); err != nil {
+			return err
+		}
+
+		if err := os.Rename(backup, filepath.Join(w.Config.CFDevHome, path)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Workspace) CleanupUpgrade() error {
+	return removeDirAlls(w.upgradeDir(), w.upgradeBackupDir())
+}
+
+func (w *Workspace) upgradeDir() string {
+	return filepath.Join(w.Config.CFDevHome, "upgrade")
+}
+
+func (w *Workspace) upgradeBackupDir() string {
+	return filepath.Join(w.Config.CFDevHome, "upgrade-backup")
+}
+
+func (w *Workspace) upgradePaths() []string {
+	return append([]string{
+		"bin",
+		"services",
+		filepath.Join("state", "metadata.yml"),
+	}, w.directorPaths()...)
+}
+
+func (w *Workspace) directorPaths() []string {
+	return []string{
+		filepath.Join("state", "bosh", "director.yml"),
+		filepath.Join("state", "bosh", "cloud-config.yml"),
+		filepath.Join("state", "bosh", "dns.yml"),
+		filepath.Join("state", "bosh", "ops-manager-dns-runtime.yml"),
+	}
+}
+
+// releaseBelongsTo tells whether the release is the deployment of the
+// service, or is named after its flag name, such as cf-mysql for mysql.
+func releaseBelongsTo(release string, service Service) bool {
+	release = strings.ToLower(release)
+
+	if service.Deployment != "" && release == strings.ToLower(service.Deployment) {
+		return true
+	}
+
+	if service.Flagname == "" {
+		return false
+	}
+
+	for _, word := range strings.FieldsFunc(release, func(r rune) bool { return r == '-' || r == '_' }) {
+		if word == strings.ToLower(service.Flagname) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func findService(services []Service, name string) (Service, bool) {
+	for _, s := range services {
+		if s.Name == name {
+			return s, true
+		}
+	}
+
+	return Service{}, false
+}
+
+func isDeployed(deployments []string, deployment string) bool {
+	for _, d := range deployments {
+		if d == deployment {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,240 @@
+package workspace_test
+
+import (
+	"archive/tar"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/workspace"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Upgrade", func() {
+	var (
+		cf = workspace.Service{
+			Name:       "Cloud Foundry",
+			Flagname:   "always-include",
+			Script:     "bin/deploy-cf",
+			Deployment: "cf",
+		}
+		mysql = workspace.Service{
+			Name:       "Mysql",
+			Flagname:   "mysql",
+			Script:     "bin/deploy-mysql",
+			Deployment: "cf-mysql",
+		}
+		redis = workspace.Service{
+			Name:       "Redis",
+			Flagname:   "redis",
+			Script:     "bin/deploy-redis",
+			Deployment: "cf-redis",
+		}
+		current workspace.Metadata
+	)
+
+	BeforeEach(func() {
+		current = workspace.Metadata{
+			ArtifactVersion: "1.0",
+			Services:        []workspace.Service{cf, mysql, redis},
+			Versions: []workspace.Version{
+				{Name: "capi", Value: "1.0"},
+				{Name: "cf-mysql", Value: "1.0"},
+				{Name: "cf-redis", Value: "1.0"},
+			},
+		}
+	})
+
+	Describe("PlanUpgrade", func() {
+		It("attributes the release versions to the deployed services", func() {
+			next := current
+			next.ArtifactVersion = "2.0"
+			next.Versions = []workspace.Version{
+				{Name: "capi", Value: "1.0"},
+				{Name: "cf-mysql", Value: "2.0"},
+				{Name: "cf-redis", Value: "2.0"},
+			}
+
+			plan := workspace.PlanUpgrade(current, next, []string{"cf", "cf-mysql"})
+
+			Expect(plan.From).To(Equal("1.0"))
+			Expect(plan.To).To(Equal("2.0"))
+			Expect(plan.Versions).To(ConsistOf(
+				workspace.VersionChange{Name: "cf-mysql", From: "1.0", To: "2.0"},
+				workspace.VersionChange{Name: "cf-redis", From: "1.0", To: "2.0"},
+			))
+			Expect(plan.Changes).To(Equal([]workspace.ServiceChange{
+				{Service: mysql, Action: workspace.ActionUpdate},
+			}))
+		})
+
+		It("attributes the remaining release versions to the always included services", func() {
+			next := current
+			next.Versions = []workspace.Version{
+				{Name: "capi", Value: "2.0"},
+				{Name: "cf-mysql", Value: "1.0"},
+				{Name: "cf-redis", Value: "1.0"},
+			}
+
+			plan := workspace.PlanUpgrade(current, next, []string{"cf", "cf-mysql"})
+			Expect(plan.Services()).To(Equal([]workspace.Service{cf}))
+		})
+
+		It("redeploys services whose definition changed and reports dropped ones", func() {
+			updatedMysql := mysql
+			updatedMysql.Script = "bin/deploy-mysql-v2"
+
+			next := current
+			next.Services = []workspace.Service{cf, updatedMysql}
+
+			plan := workspace.PlanUpgrade(current, next, []string{"cf", "cf-mysql", "cf-redis"})
+			Expect(plan.Changes).To(Equal([]workspace.ServiceChange{
+				{Service: updatedMysql, Action: workspace.ActionUpdate},
+				{Service: redis, Action: workspace.ActionDropped},
+			}))
+			Expect(plan.Services()).To(Equal([]workspace.Service{updatedMysql}))
+		})
+
+		It("only attributes release versions on whole names", func() {
+			sql := workspace.Service{Name: "SQL", Flagname: "sql", Deployment: "sql"}
+			unnamed := workspace.Service{Name: "Unnamed", Script: "bin/deploy-unnamed"}
+
+			current.Services = append(current.Services, sql, unnamed)
+			next := current
+			next.Versions = []workspace.Version{
+				{Name: "capi", Value: "1.0"},
+				{Name: "cf-mysql", Value: "2.0"},
+				{Name: "cf-redis", Value: "1.0"},
+			}
+
+			plan := workspace.PlanUpgrade(current, next, []string{"cf", "cf-mysql", "sql", ""})
+			Expect(plan.Services()).To(Equal([]workspace.Service{mysql}))
+		})
+
+		It("returns an empty plan when nothing changed", func() {
+			plan := workspace.PlanUpgrade(current, current, []string{"cf", "cf-mysql"})
+			Expect(plan.Changes).To(BeEmpty())
+		})
+	})
+
+	Describe("StageUpgrade", func() {
+		var (
+			homeDir string
+			ws      *workspace.Workspace
+		)
+
+		BeforeEach(func() {
+			var err error
+			homeDir, err = ioutil.TempDir("", "cfdev-upgrade-")
+			Expect(err).NotTo(HaveOccurred())
+
+			ws = workspace.New(config.Config{
+				CFDevHome:   homeDir,
+				StateDir:    filepath.Join(homeDir, "state"),
+				BinaryDir:   filepath.Join(homeDir, "bin"),
+				ServicesDir: filepath.Join(homeDir, "services"),
+			})
+
+			writeFiles(homeDir, map[string]string{
+				"bin/some-binary":         "old-binary",
+				"services/deploy-cf":      "old-script",
+				"state/metadata.yml":      "artifact_version: old",
+				"state/bosh/director.yml": "old-director",
+				"state/bosh/state.json":   "director-state",
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(homeDir)
+		})
+
+		It("swaps the staged assets into place and restores them on rollback", func() {
+			depsFile := filepath.Join(homeDir, "deps.tgz")
+			writeTarball(depsFile, map[string]string{
+				"bin/some-binary":         "new-binary",
+				"services/deploy-cf":      "new-script",
+				"state/metadata.yml":      "artifact_version: new",
+				"state/bosh/director.yml": "new-director",
+				"state/bosh/state.json":   "initial-state",
+			})
+
+			metadata, err := ws.StageUpgrade(depsFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata.ArtifactVersion).To(Equal("new"))
+			Expect(readFile(homeDir, "bin/some-binary")).To(Equal("old-binary"))
+			Expect(ws.DirectorChanged()).To(BeTrue())
+
+			Expect(ws.ApplyUpgrade()).To(Succeed())
+			Expect(readFile(homeDir, "bin/some-binary")).To(Equal("new-binary"))
+			Expect(readFile(homeDir, "services/deploy-cf")).To(Equal("new-script"))
+			Expect(readFile(homeDir, "state/metadata.yml")).To(Equal("artifact_version: new"))
+			Expect(readFile(homeDir, "state/bosh/director.yml")).To(Equal("new-director"))
+			Expect(readFile(homeDir, "state/bosh/state.json")).To(Equal("director-state"))
+
+			Expect(ws.RollbackUpgrade()).To(Succeed())
+			Expect(readFile(homeDir, "bin/some-binary")).To(Equal("old-binary"))
+			Expect(readFile(homeDir, "services/deploy-cf")).To(Equal("old-script"))
+			Expect(readFile(homeDir, "state/metadata.yml")).To(Equal("artifact_version: old"))
+			Expect(readFile(homeDir, "state/bosh/director.yml")).To(Equal("old-director"))
+
+			Expect(ws.CleanupUpgrade()).To(Succeed())
+			Expect(filepath.Join(homeDir, "upgrade")).NotTo(BeADirectory())
+			Expect(filepath.Join(homeDir, "upgrade-backup")).NotTo(BeADirectory())
+		})
+
+		It("does not redeploy the BOSH Director when its manifests are the same", func() {
+			depsFile := filepath.Join(homeDir, "deps.tgz")
+			writeTarball(depsFile, map[string]string{
+				"state/metadata.yml":      "artifact_version: new",
+				"state/bosh/director.yml": "old-director",
+			})
+
+			_, err := ws.StageUpgrade(depsFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ws.DirectorChanged()).To(BeFalse())
+		})
+	})
+})
+
+func writeFiles(dir string, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+	}
+}
+
+func readFile(dir string, name string) string {
+	contents, err := ioutil.ReadFile(filepath.Join(dir, name))
+	Expect(err).NotTo(HaveOccurred())
+	return string(contents)
+}
+
+func writeTarball(path string, files map[string]string) {
+	f, err := os.Create(path)
+	Expect(err).NotTo(HaveOccurred())
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	defer gw.Close()
+
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	dirs := map[string]bool{}
+	for name, contents := range files {
+		for dir := filepath.Dir(name); dir != "."; dir = filepath.Dir(dir) {
+			if !dirs[dir] {
+				dirs[dir] = true
+				Expect(tw.WriteHeader(&tar.Header{Name: dir + "/", Typeflag: tar.TypeDir, Mode: 0755})).To(Succeed())
+			}
+		}
+
+		Expect(tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0600, Size: int64(len(contents))})).To(Succeed())
+		_, err := tw.Write([]byte(contents))
+		Expect(err).NotTo(HaveOccurred())
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type Version struct {
@@ -19,11 +20,13 @@ type Version struct {
 }
 
 type Service struct {
-	Name       string `yaml:"name"`
-	Flagname   string `yaml:"flag_name"`
-	Script     string `yaml:"script"`
-	Deployment string `yaml:"deployment"`
-	IsErrand   bool   `yaml:"errand"`
+	Name           string        `yaml:"name"`
+	Flagname       string        `yaml:"flag_name"`
+	Script         string        `yaml:"script"`
+	UndeployScript string        `yaml:"undeploy_script"`
+	Deployment     string        `yaml:"deployment"`
+	IsErrand       bool          `yaml:"errand"`
+	Timeout        time.Duration `yaml:"timeout"`
 }
 
 type Metadata struct {
@@ -37,6 +40,11 @@ type Metadata struct {
 	Versions         []Version           `yaml:"versions"`
 }
 
+type Credentials struct {
+	Username string
+	Password string
+}
+
 type Workspace struct {
 	Config config.Config
 }
@@ -64,7 +72,21 @@ func (w *Workspace) CreateDirs() error {
 		w.Config.LogDir)
 }
 
+// Destroy deletes the environment kept by cf dev stop, with the disk
+// of the VM and the state of the Director, so that the next start
+// provisions a new one.
+func (w *Workspace) Destroy() error {
+	return removeDirAlls(
+		w.Config.StateDir,
+		w.Config.BinaryDir,
+		w.Config.ServicesDir)
+}
+
 func (w *Workspace) SetupState(depsFile string) error {
+	return extract(depsFile, w.Config.CFDevHome)
+}
+
+func extract(depsFile string, dir string) error {
 	f, err := os.Open(depsFile)
 	if err != nil {
 		return err
@@ -91,7 +113,7 @@ func (w *Workspace) SetupState(depsFile string) error {
 			continue
 		}
 
-		target := filepath.Join(w.Config.CFDevHome, header.Name)
+		target := filepath.Join(dir, header.Name)
 
 		switch header.Typeflag {
 		case tar.TypeDir:
@@ -126,6 +148,12 @@ func (w *Workspace) EnvsMapping() map[string]string {
 	}
 
 	yaml.Unmarshal(data, &mapping)
+
+	// Without root privileges, there is no route to the BOSH Director
+	if w.Config.Rootless && mapping["BOSH_ALL_PROXY"] == "" {
+		mapping["BOSH_ALL_PROXY"] = "socks5://" + config.RootlessProxy
+	}
+
 	return mapping
 }
 
@@ -138,8 +166,68 @@ func (w *Workspace) Envs() []string {
 	return results
 }
 
+// CFCredentials returns the credentials of the CF admin user. Assets
+// can declare them in the env.yml, otherwise the password generated by
+// the deployment is read from the CredHub of the BOSH Director. Without
+// CredHub, the deployment uses the CF Dev defaults, admin/admin.
+func (w *Workspace) CFCredentials() Credentials {
+	var (
+		mapping     = w.EnvsMapping()
+		credentials = Credentials{Username: "admin", Password: "admin"}
+	)
+
+	if username := mapping["CF_ADMIN_USERNAME"]; username != "" {
+		credentials.Username = username
+	}
+
+	if password := mapping["CF_ADMIN_PASSWORD"]; password != "" {
+		credentials.Password = password
+	} else if password, err := credhubCFAdminPassword(mapping); err == nil {
+		credentials.Password = password
+	}
+
+	return credentials
+}
+
 func (w *Workspace) Metadata() (Metadata, error) {
-	buf, err := ioutil.ReadFile(filepath.Join(w.Config.StateDir, "metadata.yml"))
+	return readMetadata(filepath.Join(w.Config.StateDir, "metadata.yml"))
+}
+
+// ReadDepsMetadata reads the metadata of a deps tarball without extracting it.
+func (w *Workspace) ReadDepsMetadata(depsFile string) (Metadata, error) {
+	f, err := os.Open(depsFile)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer f.Close()
+
+	gzr, err := gzip.NewReader(f)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return Metadata{}, fmt.Errorf("%s does not contain any metadata", depsFile)
+		} else if err != nil {
+			return Metadata{}, err
+		}
+
+		if filepath.ToSlash(filepath.Clean(header.Name)) != "state/metadata.yml" {
+			continue
+		}
+
+		var metadata Metadata
+		err = yaml.NewDecoder(tr).Decode(&metadata)
+		return metadata, err
+	}
+}
+
+func readMetadata(path string) (Metadata, error) {
+	buf, err := ioutil.ReadFile(path)
 	if err != nil {
 		return Metadata{}, err
 	}